@@ -0,0 +1,5 @@
+module adapters
+
+go 1.21
+
+require github.com/spf13/viper v1.18.2
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/models"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// AuthHandler handles authentication and user management endpoints
+type AuthHandler struct {
+	authService *services.AuthService
+	logger      *utils.Logger
+}
+
+// NewAuthHandler creates a new authentication handler
+func NewAuthHandler(authService *services.AuthService, logger *utils.Logger) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+		logger:      logger,
+	}
+}
+
+// ActivateUser activates a user account, e.g. one pending admin activation
+func (h *AuthHandler) ActivateUser(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	if err := h.authService.ActivateUser(c.Request.Context(), userID, currentUser.ID); err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse(c, "User not found", "USER_NOT_FOUND"))
+			return
+		}
+
+		h.logger.Error("Failed to activate user", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to activate user", "USER_ACTIVATION_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User activated successfully",
+	})
+}
+
+// Login authenticates a user by email or username and password
+func (h *AuthHandler) Login(c *gin.Context) {
+	var req models.LoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid request body", "INVALID_REQUEST_BODY"))
+		return
+	}
+
+	resp, err := h.authService.Login(c.Request.Context(), &req, c.ClientIP(), c.GetHeader("User-Agent"))
+	if err != nil {
+		var lockedErr *services.AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			remaining := lockedErr.RemainingSeconds()
+			c.Header("Retry-After", strconv.Itoa(remaining))
+			body := middleware.ErrorResponse(c, "Account is locked", "ACCOUNT_LOCKED")
+			body["retry_after_seconds"] = remaining
+			c.JSON(http.StatusLocked, body)
+		case errors.Is(err, services.ErrLoginIdentifierTooLong), errors.Is(err, services.ErrUnknownClient):
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid login request", "INVALID_LOGIN_REQUEST"))
+		case errors.Is(err, auth.ErrTooManySessions):
+			c.JSON(http.StatusConflict, middleware.ErrorResponse(c, "Too many active sessions", "TOO_MANY_SESSIONS"))
+		default:
+			c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Invalid credentials", "INVALID_CREDENTIALS"))
+		}
+		return
+	}
+
+	// A second factor is needed; only the challenge is returned
+	if resp.MFARequired {
+		c.JSON(http.StatusOK, gin.H{
+			"mfa_required":    true,
+			"challenge_token": resp.ChallengeToken,
+			"expires_in":      resp.ExpiresIn,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// VerifyTOTP completes a login that requires a second factor with a TOTP
+// or recovery code
+func (h *AuthHandler) VerifyTOTP(c *gin.Context) {
+	var req models.VerifyTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid request body", "INVALID_REQUEST_BODY"))
+		return
+	}
+
+	resp, err := h.authService.VerifyTOTP(c.Request.Context(), req.ChallengeToken, req.Code)
+	if err != nil {
+		var lockedErr *services.AccountLockedError
+		switch {
+		case errors.As(err, &lockedErr):
+			remaining := lockedErr.RemainingSeconds()
+			c.Header("Retry-After", strconv.Itoa(remaining))
+			body := middleware.ErrorResponse(c, "Account is locked", "ACCOUNT_LOCKED")
+			body["retry_after_seconds"] = remaining
+			c.JSON(http.StatusLocked, body)
+		case errors.Is(err, services.ErrInvalidMFAChallenge):
+			c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Invalid or expired MFA challenge", "INVALID_MFA_CHALLENGE"))
+		case errors.Is(err, services.ErrInvalidTOTPCode), errors.Is(err, services.ErrTOTPCodeReused):
+			c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Invalid two-factor code", "INVALID_TOTP_CODE"))
+		case errors.Is(err, auth.ErrTooManySessions):
+			c.JSON(http.StatusConflict, middleware.ErrorResponse(c, "Too many active sessions", "TOO_MANY_SESSIONS"))
+		default:
+			h.logger.Error("Failed to verify two-factor code", "error", err)
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to verify two-factor code", "TOTP_VERIFICATION_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// SetupTOTP starts two-factor enrolment for the current user
+func (h *AuthHandler) SetupTOTP(c *gin.Context) {
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	resp, err := h.authService.SetupTOTP(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrTOTPAlreadyEnabled) {
+			c.JSON(http.StatusConflict, middleware.ErrorResponse(c, "Two-factor authentication is already enabled", "TOTP_ALREADY_ENABLED"))
+			return
+		}
+
+		h.logger.Error("Failed to set up two-factor authentication", "error", err, "user_id", currentUser.ID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to set up two-factor authentication", "TOTP_SETUP_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// EnableTOTP confirms two-factor enrolment for the current user and
+// returns their recovery codes
+func (h *AuthHandler) EnableTOTP(c *gin.Context) {
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	var req models.EnableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid request body", "INVALID_REQUEST_BODY"))
+		return
+	}
+
+	resp, err := h.authService.EnableTOTP(c.Request.Context(), currentUser.ID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrTOTPAlreadyEnabled):
+			c.JSON(http.StatusConflict, middleware.ErrorResponse(c, "Two-factor authentication is already enabled", "TOTP_ALREADY_ENABLED"))
+		case errors.Is(err, services.ErrTOTPNotSetUp):
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Two-factor authentication has not been set up", "TOTP_NOT_SET_UP"))
+		case errors.Is(err, services.ErrInvalidTOTPCode):
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid two-factor code", "INVALID_TOTP_CODE"))
+		default:
+			h.logger.Error("Failed to enable two-factor authentication", "error", err, "user_id", currentUser.ID)
+			c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to enable two-factor authentication", "TOTP_ENABLE_FAILED"))
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// RegenerateRecoveryCodes replaces the current user's recovery codes
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	resp, err := h.authService.RegenerateRecoveryCodes(c.Request.Context(), currentUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrTOTPNotEnabled) {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Two-factor authentication is not enabled", "TOTP_NOT_ENABLED"))
+			return
+		}
+
+		h.logger.Error("Failed to regenerate recovery codes", "error", err, "user_id", currentUser.ID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to regenerate recovery codes", "RECOVERY_CODES_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
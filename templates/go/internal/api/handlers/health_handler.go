@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"gorm.io/gorm"
+
+	"app/internal/utils"
+)
+
+// defaultHealthCheckTimeout bounds how long a single dependency check may
+// take unless WithHealthCheckTimeout sets another limit
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// ServiceHealth reports the state of a single dependency
+type ServiceHealth struct {
+	Status  string `json:"status"`
+	Latency string `json:"latency,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// HealthStatus is the response of the health and readiness endpoints
+type HealthStatus struct {
+	Status    string                   `json:"status"`
+	Timestamp time.Time                `json:"timestamp"`
+	Services  map[string]ServiceHealth `json:"services,omitempty"`
+}
+
+// HealthHandler serves the health check endpoints. Liveness only reports
+// that the process can serve requests; readiness and the full health check
+// probe the database and Redis.
+type HealthHandler struct {
+	db          *gorm.DB
+	redisClient *redis.Client
+	logger      *utils.Logger
+	timeout     time.Duration
+}
+
+// HealthHandlerOption configures optional HealthHandler behaviour
+type HealthHandlerOption func(*HealthHandler)
+
+// WithHealthCheckTimeout sets how long each dependency check may take before
+// the dependency is reported unhealthy. Non-positive values keep the default.
+func WithHealthCheckTimeout(timeout time.Duration) HealthHandlerOption {
+	return func(h *HealthHandler) {
+		if timeout > 0 {
+			h.timeout = timeout
+		}
+	}
+}
+
+// NewHealthHandler creates a new health handler
+func NewHealthHandler(db *gorm.DB, redisClient *redis.Client, logger *utils.Logger, opts ...HealthHandlerOption) *HealthHandler {
+	h := &HealthHandler{
+		db:          db,
+		redisClient: redisClient,
+		logger:      logger,
+		timeout:     defaultHealthCheckTimeout,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// Health reports the state of every dependency, returning 503 if any of
+// them is unhealthy
+func (h *HealthHandler) Health(c *gin.Context) {
+	services, healthy := h.checkDependencies(c.Request.Context())
+
+	status, code := "healthy", http.StatusOK
+	if !healthy {
+		status, code = "unhealthy", http.StatusServiceUnavailable
+	}
+
+	c.JSON(code, HealthStatus{
+		Status:    status,
+		Timestamp: time.Now(),
+		Services:  services,
+	})
+}
+
+// Readiness reports whether the instance can take traffic. It returns 503
+// while a dependency is down so the load balancer stops routing to it.
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	services, healthy := h.checkDependencies(c.Request.Context())
+
+	status, code := "ready", http.StatusOK
+	if !healthy {
+		status, code = "not_ready", http.StatusServiceUnavailable
+		h.logger.Warn("Readiness check failed", "services", services)
+	}
+
+	c.JSON(code, HealthStatus{
+		Status:    status,
+		Timestamp: time.Now(),
+		Services:  services,
+	})
+}
+
+// Liveness reports that the process is running. It never checks external
+// dependencies: an outage there should not get the instance restarted.
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, HealthStatus{
+		Status:    "alive",
+		Timestamp: time.Now(),
+	})
+}
+
+// checkDependencies probes the database and Redis, reporting whether both
+// are healthy. A nil Redis client means Redis is disabled on purpose, which
+// the application runs without, so it is reported as disabled and does not
+// fail the check.
+func (h *HealthHandler) checkDependencies(ctx context.Context) (map[string]ServiceHealth, bool) {
+	services := map[string]ServiceHealth{
+		"database": h.check(ctx, h.pingDatabase),
+		"redis":    {Status: "disabled"},
+	}
+	if h.redisClient != nil {
+		services["redis"] = h.check(ctx, h.pingRedis)
+	}
+
+	for _, service := range services {
+		if service.Status == "unhealthy" {
+			return services, false
+		}
+	}
+	return services, true
+}
+
+// check runs a single dependency probe with a timeout
+func (h *HealthHandler) check(ctx context.Context, ping func(context.Context) error) ServiceHealth {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := ping(ctx); err != nil {
+		return ServiceHealth{Status: "unhealthy", Error: err.Error()}
+	}
+	return ServiceHealth{Status: "healthy", Latency: time.Since(start).String()}
+}
+
+func (h *HealthHandler) pingDatabase(ctx context.Context) error {
+	if h.db == nil {
+		return errors.New("database not configured")
+	}
+
+	sqlDB, err := h.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+func (h *HealthHandler) pingRedis(ctx context.Context) error {
+	return h.redisClient.Ping(ctx).Err()
+}
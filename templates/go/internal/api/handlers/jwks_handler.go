@@ -0,0 +1,19 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"app/internal/auth"
+)
+
+// JWKSHandler serves the public signing keys as a JSON Web Key Set so other
+// services can verify access tokens without the private keys
+func JWKSHandler(keySet *auth.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// Short cache so verifiers pick up a rotated key quickly
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, keySet.JWKS())
+	}
+}
@@ -0,0 +1,11 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// PrometheusHandler serves the metrics in the default Prometheus registry
+func PrometheusHandler() gin.HandlerFunc {
+	return gin.WrapH(promhttp.Handler())
+}
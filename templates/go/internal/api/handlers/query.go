@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"app/internal/api/middleware"
+	"app/internal/utils"
+)
+
+// parsePagination reads the page and page_size query parameters, applying
+// defaultSize when page_size is absent and clamping it to maxSize. It writes
+// a 400 response and returns false when either parameter is malformed
+func parsePagination(c *gin.Context, defaultSize, maxSize int) (page, pageSize int, ok bool) {
+	page, pageSize = 1, defaultSize
+
+	if value := c.Query("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "page must be a positive integer", "INVALID_PAGINATION"))
+			return 0, 0, false
+		}
+		page = parsed
+	}
+
+	if value := c.Query("page_size"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "page_size must be a positive integer", "INVALID_PAGINATION"))
+			return 0, 0, false
+		}
+		pageSize = parsed
+	}
+
+	if pageSize > maxSize {
+		pageSize = maxSize
+	}
+
+	return page, pageSize, true
+}
+
+// queryBool reads an optional boolean query parameter, returning nil when it
+// is absent. It writes a 400 response and returns false when it is malformed.
+func queryBool(c *gin.Context, name string) (*bool, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, true
+	}
+
+	parsed, err := utils.ParseQueryBool(value)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, name+" must be a boolean (true/false, 1/0, yes/no)", "INVALID_QUERY_PARAM"))
+		return nil, false
+	}
+
+	return &parsed, true
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// RoleHandler handles role management endpoints
+type RoleHandler struct {
+	roleService *services.RoleService
+	config      *config.Config
+	logger      *utils.Logger
+}
+
+// NewRoleHandler creates a new role handler
+func NewRoleHandler(roleService *services.RoleService, cfg *config.Config, logger *utils.Logger) *RoleHandler {
+	return &RoleHandler{
+		roleService: roleService,
+		config:      cfg,
+		logger:      logger,
+	}
+}
+
+// ListRoles returns a paginated list of roles filtered by active state and name
+func (h *RoleHandler) ListRoles(c *gin.Context) {
+	page, pageSize, ok := parsePagination(c, h.config.PaginationDefaultSize, h.config.PaginationMaxSize)
+	if !ok {
+		return
+	}
+
+	filters := interfaces.RoleFilters{
+		Name:      c.Query("name"),
+		SortBy:    c.Query("sort_by"),
+		SortOrder: c.Query("sort_order"),
+	}
+
+	if filters.IsActive, ok = queryBool(c, "is_active"); !ok {
+		return
+	}
+
+	expand, ok := queryBool(c, "expand")
+	if !ok {
+		return
+	}
+
+	roles, err := h.roleService.ListRoles(c.Request.Context(), filters, page, pageSize)
+	if err != nil {
+		h.logger.Error("Failed to list roles", "error", err)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to list roles", "ROLE_LIST_FAILED"))
+		return
+	}
+
+	// Optionally resolve wildcards so clients can display concrete permissions
+	if expand != nil && *expand {
+		for i := range roles.Items {
+			roles.Items[i].Expand()
+		}
+	}
+
+	roles.SetLinks(c.Request.URL)
+	c.JSON(http.StatusOK, roles)
+}
+
+// UpdateRole updates a role and returns the permissions that were added and removed
+func (h *RoleHandler) UpdateRole(c *gin.Context) {
+	roleID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid role ID", "INVALID_ROLE_ID"))
+		return
+	}
+
+	var req models.RoleUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid request body", "INVALID_REQUEST_BODY"))
+		return
+	}
+
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	result, err := h.roleService.UpdateRole(c.Request.Context(), roleID, &req, currentUser.ID)
+	if err != nil {
+		if errors.Is(err, services.ErrRoleNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse(c, "Role not found", "ROLE_NOT_FOUND"))
+			return
+		}
+
+		h.logger.Error("Failed to update role", "error", err, "role_id", roleID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to update role", "ROLE_UPDATE_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
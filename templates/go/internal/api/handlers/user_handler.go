@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"app/internal/api/middleware"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// exportBatchSize is the number of users read from the database per chunk
+// while streaming an export
+const exportBatchSize = 500
+
+// UserHandler handles admin user management endpoints
+type UserHandler struct {
+	userService *services.UserService
+	logger      *utils.Logger
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userService *services.UserService, logger *utils.Logger) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+		logger:      logger,
+	}
+}
+
+// ExportUsers streams all users as newline-delimited JSON
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var filters interfaces.UserFilters
+	var ok bool
+	if filters.IsActive, ok = queryBool(c, "is_active"); !ok {
+		return
+	}
+	if filters.IsVerified, ok = queryBool(c, "is_verified"); !ok {
+		return
+	}
+	filters.RoleName = c.Query("role")
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", `attachment; filename="users.ndjson"`)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	count := 0
+	err := h.userService.ExportUsers(c.Request.Context(), filters, exportBatchSize, func(user models.UserResponse) error {
+		if err := encoder.Encode(user); err != nil {
+			return err
+		}
+		count++
+		if count%exportBatchSize == 0 {
+			c.Writer.Flush()
+		}
+		return nil
+	})
+	if err != nil {
+		// Headers are already sent, so the stream is simply cut short
+		h.logger.Error("User export failed", "error", err, "exported", count)
+		return
+	}
+
+	c.Writer.Flush()
+}
+
+// GetPermissionSource reports which of a user's roles grants a permission,
+// to help debug authorization decisions
+func (h *UserHandler) GetPermissionSource(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	permission := c.Query("permission")
+	if permission == "" {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Query parameter permission is required", "MISSING_PERMISSION"))
+		return
+	}
+
+	source, err := h.userService.ResolvePermissionSource(c.Request.Context(), userID, permission)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse(c, "User not found", "USER_NOT_FOUND"))
+			return
+		}
+
+		h.logger.Error("Failed to resolve permission source", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to resolve permission source", "PERMISSION_SOURCE_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, source)
+}
+
+// GetOwnSecurityInfo returns the current user's account security details
+func (h *UserHandler) GetOwnSecurityInfo(c *gin.Context) {
+	currentUser, err := middleware.GetCurrentUser(c)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, middleware.ErrorResponse(c, "Authentication required", "AUTHENTICATION_REQUIRED"))
+		return
+	}
+
+	h.writeSecurityInfo(c, currentUser.ID)
+}
+
+// GetSecurityInfo returns a user's account security details for admins
+func (h *UserHandler) GetSecurityInfo(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	h.writeSecurityInfo(c, userID)
+}
+
+// writeSecurityInfo looks up and writes a user's security details
+func (h *UserHandler) writeSecurityInfo(c *gin.Context, userID uuid.UUID) {
+	info, err := h.userService.GetSecurityInfo(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse(c, "User not found", "USER_NOT_FOUND"))
+			return
+		}
+
+		h.logger.Error("Failed to get security info", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to get security info", "SECURITY_INFO_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, info)
+}
+
+// GetRoleHistory returns the roles granted to and revoked from a user and
+// who made each change
+func (h *UserHandler) GetRoleHistory(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Invalid user ID", "INVALID_USER_ID"))
+		return
+	}
+
+	history, err := h.userService.GetRoleHistory(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			c.JSON(http.StatusNotFound, middleware.ErrorResponse(c, "User not found", "USER_NOT_FOUND"))
+			return
+		}
+
+		h.logger.Error("Failed to get role history", "error", err, "user_id", userID)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to get role history", "ROLE_HISTORY_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// SearchUsers searches users by email, username or name. Passing
+// highlight=true adds the matching field and a highlighted snippet.
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	query := c.Query("q")
+	if query == "" {
+		c.JSON(http.StatusBadRequest, middleware.ErrorResponse(c, "Query parameter q is required", "MISSING_QUERY"))
+		return
+	}
+
+	var filters interfaces.UserFilters
+	var ok bool
+	if filters.IsActive, ok = queryBool(c, "is_active"); !ok {
+		return
+	}
+	highlight, ok := queryBool(c, "highlight")
+	if !ok {
+		return
+	}
+
+	results, err := h.userService.SearchUsers(c.Request.Context(), query, filters, highlight != nil && *highlight)
+	if err != nil {
+		h.logger.Error("Failed to search users", "error", err)
+		c.JSON(http.StatusInternalServerError, middleware.ErrorResponse(c, "Failed to search users", "USER_SEARCH_FAILED"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"users": results,
+	})
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"app/internal/models"
+	"app/internal/utils"
+)
+
+// AuditLogWriter stores audit log entries
+type AuditLogWriter interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+}
+
+// ReadAuditor records audit log entries for successful reads of designated
+// routes, for deployments that must account for who viewed sensitive data
+type ReadAuditor struct {
+	writer AuditLogWriter
+	routes map[string]bool
+	logger *utils.Logger
+}
+
+// NewReadAuditor creates an auditor for the given route patterns, written as
+// registered with gin, e.g. "/api/v1/admin/users/:id". With no routes it
+// audits nothing.
+func NewReadAuditor(writer AuditLogWriter, routes []string, logger *utils.Logger) *ReadAuditor {
+	set := make(map[string]bool, len(routes))
+	for _, route := range routes {
+		set[route] = true
+	}
+	return &ReadAuditor{
+		writer: writer,
+		routes: set,
+		logger: logger,
+	}
+}
+
+// AuditReads writes an audit entry after a successful GET of a designated
+// route. Failures to write are logged and never fail the request.
+func (a *ReadAuditor) AuditReads() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method != http.MethodGet || !a.routes[c.FullPath()] {
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+
+		auditLog := &models.AuditLog{
+			Action:   "read",
+			Resource: c.FullPath(),
+			Details: map[string]interface{}{
+				"path":       c.Request.URL.Path,
+				"query":      c.Request.URL.RawQuery,
+				"status":     c.Writer.Status(),
+				"request_id": RequestIDFromContext(c),
+			},
+			IPAddress: c.ClientIP(),
+			UserAgent: c.GetHeader("User-Agent"),
+			Success:   true,
+		}
+		if userID, ok := c.Get("user_id"); ok {
+			if id, isUUID := userID.(uuid.UUID); isUUID {
+				auditLog.UserID = &id
+			}
+		}
+		if resourceID, err := uuid.Parse(c.Param("id")); err == nil {
+			auditLog.ResourceID = &resourceID
+		}
+
+		if err := a.writer.Create(c.Request.Context(), auditLog); err != nil {
+			a.logger.Error("Failed to audit read", "error", err, "route", c.FullPath())
+		}
+	}
+}
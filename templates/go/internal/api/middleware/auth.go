@@ -1,6 +1,8 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -14,16 +16,47 @@ import (
 
 // AuthMiddleware handles JWT authentication
 type AuthMiddleware struct {
-	jwtService *auth.JWTService
-	logger     *utils.Logger
+	jwtService    *auth.JWTService
+	logger        *utils.Logger
+	tokenVersions TokenVersionSource
+	blacklist     auth.TokenBlacklist
+}
+
+// TokenVersionSource looks up a user's current token version
+type TokenVersionSource interface {
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// AuthMiddlewareOption configures optional AuthMiddleware behaviour
+type AuthMiddlewareOption func(*AuthMiddleware)
+
+// WithTokenVersionSource rejects tokens issued before the user's token
+// version was last bumped, e.g. by a role change
+func WithTokenVersionSource(source TokenVersionSource) AuthMiddlewareOption {
+	return func(a *AuthMiddleware) {
+		a.tokenVersions = source
+	}
+}
+
+// WithTokenBlacklist rejects tokens whose ID has been blacklisted, e.g. on logout
+func WithTokenBlacklist(blacklist auth.TokenBlacklist) AuthMiddlewareOption {
+	return func(a *AuthMiddleware) {
+		a.blacklist = blacklist
+	}
 }
 
 // NewAuthMiddleware creates a new authentication middleware
-func NewAuthMiddleware(jwtService *auth.JWTService, logger *utils.Logger) *AuthMiddleware {
-	return &AuthMiddleware{
+func NewAuthMiddleware(jwtService *auth.JWTService, logger *utils.Logger, opts ...AuthMiddlewareOption) *AuthMiddleware {
+	a := &AuthMiddleware{
 		jwtService: jwtService,
 		logger:     logger,
 	}
+
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
 }
 
 // RequireAuth middleware that requires valid JWT authentication
@@ -32,11 +65,7 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		// Get Authorization header
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authorization header is required",
-				"code":  "MISSING_AUTH_HEADER",
-			})
-			c.Abort()
+			abortUnauthenticated(c, "Authorization header is required", "MISSING_AUTH_HEADER")
 			return
 		}
 
@@ -44,11 +73,7 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		token, err := a.jwtService.ExtractTokenFromHeader(authHeader)
 		if err != nil {
 			a.logger.Warn("Invalid authorization header format", "error", err, "ip", c.ClientIP())
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid authorization header format",
-				"code":  "INVALID_AUTH_HEADER",
-			})
-			c.Abort()
+			abortUnauthenticated(c, "Invalid authorization header format", "INVALID_AUTH_HEADER")
 			return
 		}
 
@@ -56,11 +81,27 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		claims, err := a.jwtService.ValidateToken(token)
 		if err != nil {
 			a.logger.Warn("Invalid JWT token", "error", err, "ip", c.ClientIP())
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid or expired token",
-				"code":  "INVALID_TOKEN",
-			})
-			c.Abort()
+			message, code := tokenErrorDetails(err)
+			abortUnauthenticated(c, message, code)
+			return
+		}
+
+		// Reject IP-bound tokens used from another network
+		if err := a.jwtService.CheckIPBinding(claims, c.ClientIP()); err != nil {
+			a.logger.Warn("Token used from a different network", "user_id", claims.UserID, "token_ip", claims.IP, "ip", c.ClientIP())
+			abortUnauthenticated(c, "Token cannot be used from this network", "TOKEN_IP_MISMATCH")
+			return
+		}
+
+		// Reject tokens revoked by logout
+		if a.isBlacklisted(c, claims) {
+			abortUnauthenticated(c, "Token has been revoked", "TOKEN_REVOKED")
+			return
+		}
+
+		// Reject tokens issued before the user's permissions changed
+		if a.isStale(c, claims) {
+			abortUnauthenticated(c, "Token is outdated, please sign in again", "TOKEN_REVOKED")
 			return
 		}
 
@@ -68,8 +109,8 @@ func (a *AuthMiddleware) RequireAuth() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_username", claims.Username)
-		c.Set("user_roles", claims.Roles)
-		c.Set("user_permissions", claims.Permissions)
+		c.Set("user_roles", nonNilStrings(claims.Roles))
+		c.Set("user_permissions", nonNilStrings(claims.Permissions))
 		c.Set("token_claims", claims)
 
 		a.logger.Debug("User authenticated successfully", 
@@ -97,7 +138,7 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		}
 
 		claims, err := a.jwtService.ValidateToken(token)
-		if err != nil {
+		if err != nil || a.jwtService.CheckIPBinding(claims, c.ClientIP()) != nil || a.isBlacklisted(c, claims) || a.isStale(c, claims) {
 			c.Next()
 			return
 		}
@@ -106,8 +147,8 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 		c.Set("user_id", claims.UserID)
 		c.Set("user_email", claims.Email)
 		c.Set("user_username", claims.Username)
-		c.Set("user_roles", claims.Roles)
-		c.Set("user_permissions", claims.Permissions)
+		c.Set("user_roles", nonNilStrings(claims.Roles))
+		c.Set("user_permissions", nonNilStrings(claims.Permissions))
 		c.Set("token_claims", claims)
 
 		c.Next()
@@ -117,26 +158,14 @@ func (a *AuthMiddleware) OptionalAuth() gin.HandlerFunc {
 // RequireRole middleware that requires specific role
 func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// First check if user is authenticated
-		userRoles, exists := c.Get("user_roles")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-				"code":  "AUTHENTICATION_REQUIRED",
-			})
-			c.Abort()
+		// First check if user is authenticated; an authenticated user
+		// without usable roles is unauthorized rather than unauthenticated
+		if !isAuthenticated(c) {
+			abortUnauthenticated(c, "Authentication required", "AUTHENTICATION_REQUIRED")
 			return
 		}
 
-		roles, ok := userRoles.([]string)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Invalid user roles data",
-				"code":  "INVALID_ROLES_DATA",
-			})
-			c.Abort()
-			return
-		}
+		roles := contextStrings(c, "user_roles")
 
 		// Check if user has required role
 		hasRole := false
@@ -155,10 +184,7 @@ func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 				"user_roles", roles,
 				"ip", c.ClientIP())
 			
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-				"code":  "INSUFFICIENT_ROLE",
-			})
+			c.JSON(http.StatusForbidden, ErrorResponse(c, "Insufficient permissions", "INSUFFICIENT_ROLE"))
 			c.Abort()
 			return
 		}
@@ -170,26 +196,14 @@ func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 // RequirePermission middleware that requires specific permission
 func (a *AuthMiddleware) RequirePermission(requiredPermission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// First check if user is authenticated
-		userPermissions, exists := c.Get("user_permissions")
-		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-				"code":  "AUTHENTICATION_REQUIRED",
-			})
-			c.Abort()
+		// First check if user is authenticated; an authenticated user
+		// without usable permissions is unauthorized rather than unauthenticated
+		if !isAuthenticated(c) {
+			abortUnauthenticated(c, "Authentication required", "AUTHENTICATION_REQUIRED")
 			return
 		}
 
-		permissions, ok := userPermissions.([]string)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Invalid user permissions data",
-				"code":  "INVALID_PERMISSIONS_DATA",
-			})
-			c.Abort()
-			return
-		}
+		permissions := contextStrings(c, "user_permissions")
 
 		// Check if user has required permission
 		hasPermission := false
@@ -216,10 +230,7 @@ func (a *AuthMiddleware) RequirePermission(requiredPermission string) gin.Handle
 				"user_permissions", permissions,
 				"ip", c.ClientIP())
 			
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Insufficient permissions",
-				"code":  "INSUFFICIENT_PERMISSION",
-			})
+			c.JSON(http.StatusForbidden, ErrorResponse(c, "Insufficient permissions", "INSUFFICIENT_PERMISSION"))
 			c.Abort()
 			return
 		}
@@ -233,32 +244,22 @@ func (a *AuthMiddleware) RequireOwnershipOrRole(getResourceOwnerID func(*gin.Con
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Authentication required",
-				"code":  "AUTHENTICATION_REQUIRED",
-			})
-			c.Abort()
+			abortUnauthenticated(c, "Authentication required", "AUTHENTICATION_REQUIRED")
 			return
 		}
 
 		currentUserID, ok := userID.(uuid.UUID)
 		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Invalid user ID data",
-				"code":  "INVALID_USER_ID",
-			})
+			c.JSON(http.StatusInternalServerError, ErrorResponse(c, "Invalid user ID data", "INVALID_USER_ID"))
 			c.Abort()
 			return
 		}
 
 		// Check if user has the allowed role (e.g., admin)
-		userRoles, _ := c.Get("user_roles")
-		if roles, ok := userRoles.([]string); ok {
-			for _, role := range roles {
-				if role == allowedRole {
-					c.Next()
-					return
-				}
+		for _, role := range contextStrings(c, "user_roles") {
+			if role == allowedRole {
+				c.Next()
+				return
 			}
 		}
 
@@ -266,10 +267,7 @@ func (a *AuthMiddleware) RequireOwnershipOrRole(getResourceOwnerID func(*gin.Con
 		resourceOwnerID, err := getResourceOwnerID(c)
 		if err != nil {
 			a.logger.Error("Failed to get resource owner ID", "error", err)
-			c.JSON(http.StatusInternalServerError, gin.H{
-				"error": "Failed to verify ownership",
-				"code":  "OWNERSHIP_CHECK_FAILED",
-			})
+			c.JSON(http.StatusInternalServerError, ErrorResponse(c, "Failed to verify ownership", "OWNERSHIP_CHECK_FAILED"))
 			c.Abort()
 			return
 		}
@@ -280,10 +278,7 @@ func (a *AuthMiddleware) RequireOwnershipOrRole(getResourceOwnerID func(*gin.Con
 				"resource_owner_id", resourceOwnerID,
 				"ip", c.ClientIP())
 			
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Access denied - not owner of resource",
-				"code":  "NOT_OWNER",
-			})
+			c.JSON(http.StatusForbidden, ErrorResponse(c, "Access denied - not owner of resource", "NOT_OWNER"))
 			c.Abort()
 			return
 		}
@@ -292,6 +287,89 @@ func (a *AuthMiddleware) RequireOwnershipOrRole(getResourceOwnerID func(*gin.Con
 	}
 }
 
+// isStale reports whether the token's version is older than the user's
+// current one. Lookup failures are treated as stale so that tokens are
+// never accepted unchecked.
+func (a *AuthMiddleware) isStale(c *gin.Context, claims *auth.Claims) bool {
+	if a.tokenVersions == nil {
+		return false
+	}
+
+	version, err := a.tokenVersions.GetTokenVersion(c.Request.Context(), claims.UserID)
+	if err != nil {
+		a.logger.Warn("Failed to check token version", "error", err, "user_id", claims.UserID)
+		return true
+	}
+
+	return claims.TokenVersion < version
+}
+
+// isBlacklisted reports whether the token has been revoked. Lookup failures
+// count as revoked so an outage cannot resurrect logged-out tokens.
+func (a *AuthMiddleware) isBlacklisted(c *gin.Context, claims *auth.Claims) bool {
+	if a.blacklist == nil {
+		return false
+	}
+
+	blacklisted, err := a.blacklist.IsTokenBlacklisted(claims.ID)
+	if err != nil {
+		a.logger.Warn("Failed to check token blacklist", "error", err, "user_id", claims.UserID)
+		return true
+	}
+
+	return blacklisted
+}
+
+// isAuthenticated reports whether RequireAuth or OptionalAuth identified the caller
+func isAuthenticated(c *gin.Context) bool {
+	_, exists := c.Get("user_id")
+	return exists
+}
+
+// contextStrings returns a string slice stored in the context, or an empty
+// slice if it is missing or has an unexpected type
+func contextStrings(c *gin.Context, key string) []string {
+	value, _ := c.Get(key)
+	values, _ := value.([]string)
+	return nonNilStrings(values)
+}
+
+// nonNilStrings returns values, or an empty slice in place of nil, so tokens
+// without roles or permissions behave the same as ones with empty lists
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// abortUnauthenticated rejects a request whose caller is not authenticated.
+// Callers that are authenticated but not allowed must get 403 instead.
+func abortUnauthenticated(c *gin.Context, message, code string) {
+	c.Header("WWW-Authenticate", `Bearer realm="api"`)
+	c.JSON(http.StatusUnauthorized, ErrorResponse(c, message, code))
+	c.Abort()
+}
+
+// tokenErrorDetails maps a token validation error to the message and code
+// returned to the client
+func tokenErrorDetails(err error) (message, code string) {
+	switch {
+	case errors.Is(err, auth.ErrTokenMalformed):
+		return "Token is malformed", "TOKEN_MALFORMED"
+	case errors.Is(err, auth.ErrTokenExpired):
+		return "Token has expired", "TOKEN_EXPIRED"
+	case errors.Is(err, auth.ErrTokenNotYetValid):
+		return "Token is not valid yet", "TOKEN_NOT_YET_VALID"
+	case errors.Is(err, auth.ErrTokenSignatureInvalid):
+		return "Token signature is invalid", "TOKEN_SIGNATURE_INVALID"
+	case errors.Is(err, auth.ErrTokenAudienceInvalid):
+		return "Token is not accepted by this server", "TOKEN_AUDIENCE_INVALID"
+	default:
+		return "Invalid or expired token", "INVALID_TOKEN"
+	}
+}
+
 // GetCurrentUser returns the current authenticated user from context
 func GetCurrentUser(c *gin.Context) (*CurrentUser, error) {
 	userID, exists := c.Get("user_id")
@@ -304,17 +382,12 @@ func GetCurrentUser(c *gin.Context) (*CurrentUser, error) {
 		return nil, fmt.Errorf("invalid user ID format")
 	}
 
-	email, _ := c.Get("user_email")
-	username, _ := c.Get("user_username")
-	roles, _ := c.Get("user_roles")
-	permissions, _ := c.Get("user_permissions")
-
 	return &CurrentUser{
 		ID:          id,
-		Email:       email.(string),
-		Username:    username.(string),
-		Roles:       roles.([]string),
-		Permissions: permissions.([]string),
+		Email:       c.GetString("user_email"),
+		Username:    c.GetString("user_username"),
+		Roles:       contextStrings(c, "user_roles"),
+		Permissions: contextStrings(c, "user_permissions"),
 	}, nil
 }
 
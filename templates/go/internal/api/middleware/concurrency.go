@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+
+	"app/internal/utils"
+)
+
+// ConcurrencyLimiter caps the number of simultaneous in-flight requests per
+// user. Counts are held in process, so the cap applies per instance.
+type ConcurrencyLimiter struct {
+	limit  int
+	logger *utils.Logger
+
+	mu       sync.Mutex
+	inFlight map[string]int
+}
+
+// NewConcurrencyLimiter creates a limiter allowing limit concurrent requests
+// per user; a limit of 0 or less disables it
+func NewConcurrencyLimiter(limit int, logger *utils.Logger) *ConcurrencyLimiter {
+	return &ConcurrencyLimiter{
+		limit:    limit,
+		logger:   logger,
+		inFlight: make(map[string]int),
+	}
+}
+
+// Limit rejects a request with 429 while its user already has the maximum
+// number of requests in flight. Authenticated requests are keyed by user ID,
+// anonymous ones by client IP.
+func (l *ConcurrencyLimiter) Limit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l.limit <= 0 {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID, exists := c.Get("user_id"); exists {
+			key = fmt.Sprintf("user:%v", userID)
+		}
+
+		if !l.acquire(key) {
+			l.logger.Warn("Concurrent request limit exceeded",
+				"key", key,
+				"limit", l.limit,
+				"path", c.Request.URL.Path)
+
+			c.Header("Retry-After", "1")
+			c.JSON(http.StatusTooManyRequests, ErrorResponse(c, "Too many concurrent requests", "CONCURRENCY_LIMIT_EXCEEDED"))
+			c.Abort()
+			return
+		}
+		defer l.release(key)
+
+		c.Next()
+	}
+}
+
+// acquire takes a slot for key, reporting false when none are free
+func (l *ConcurrencyLimiter) acquire(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.inFlight[key] >= l.limit {
+		return false
+	}
+	l.inFlight[key]++
+	return true
+}
+
+// release frees a slot for key
+func (l *ConcurrencyLimiter) release(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.inFlight[key]--
+	if l.inFlight[key] <= 0 {
+		delete(l.inFlight, key)
+	}
+}
@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrorResponse builds the standard JSON error envelope, including the
+// request ID so clients can quote it when reporting problems
+func ErrorResponse(c *gin.Context, message, code string) gin.H {
+	return gin.H{
+		"error":      message,
+		"code":       code,
+		"request_id": RequestIDFromContext(c),
+	}
+}
+
+// NotFound answers requests for unknown routes with the standard error
+// envelope instead of gin's plain-text default
+func NotFound() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusNotFound, ErrorResponse(c, "Route not found", "ROUTE_NOT_FOUND"))
+	}
+}
+
+// MethodNotAllowed answers requests using an unsupported method on a known
+// route with the standard error envelope instead of gin's plain-text default
+func MethodNotAllowed() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusMethodNotAllowed, ErrorResponse(c, "Method not allowed", "METHOD_NOT_ALLOWED"))
+	}
+}
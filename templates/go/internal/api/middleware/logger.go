@@ -0,0 +1,29 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"app/internal/utils"
+)
+
+// RequestLogger logs every request once it has been handled, tagged with the
+// request ID so the line can be correlated with the handler's own logs. It
+// must run after RequestID.
+func RequestLogger(logger *utils.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		logger.WithRequestID(RequestIDFromContext(c)).LogHTTPRequest(
+			c.Request.Method,
+			c.Request.URL.Path,
+			c.Request.UserAgent(),
+			c.ClientIP(),
+			c.Writer.Status(),
+			time.Since(start).Milliseconds(),
+		)
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"net/url"
+	"strings"
+)
+
+// OriginMatcher decides per request whether a CORS origin is allowed. It
+// accepts exact origins, wildcard subdomain patterns such as
+// https://*.example.com, and arbitrary predicates. A bare "*" is never
+// honoured: credentialed responses must name the requesting origin.
+type OriginMatcher struct {
+	exact      map[string]bool
+	wildcards  []wildcardOrigin
+	predicates []func(origin string) bool
+}
+
+// wildcardOrigin is a parsed https://*.example.com style pattern
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com"
+	port   string
+}
+
+// NewOriginMatcher creates a matcher for the configured origins. Patterns
+// with a leading "*." in the host match any subdomain depth but not the
+// bare domain itself.
+func NewOriginMatcher(origins []string, predicates ...func(origin string) bool) *OriginMatcher {
+	m := &OriginMatcher{
+		exact:      make(map[string]bool, len(origins)),
+		predicates: predicates,
+	}
+
+	for _, origin := range origins {
+		origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+		if origin == "" || origin == "*" {
+			continue
+		}
+		if !strings.Contains(origin, "://*.") {
+			m.exact[origin] = true
+			continue
+		}
+
+		u, err := url.Parse(strings.Replace(origin, "://*.", "://", 1))
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		m.wildcards = append(m.wildcards, wildcardOrigin{
+			scheme: u.Scheme,
+			suffix: "." + u.Hostname(),
+			port:   u.Port(),
+		})
+	}
+
+	return m
+}
+
+// Allowed reports whether origin may make cross-origin requests
+func (m *OriginMatcher) Allowed(origin string) bool {
+	normalized := strings.ToLower(origin)
+	if m.exact[normalized] {
+		return true
+	}
+
+	if len(m.wildcards) > 0 {
+		if u, err := url.Parse(normalized); err == nil && u.Path == "" && u.User == nil {
+			host := u.Hostname()
+			for _, w := range m.wildcards {
+				if u.Scheme == w.scheme && u.Port() == w.port &&
+					len(host) > len(w.suffix) && strings.HasSuffix(host, w.suffix) {
+					return true
+				}
+			}
+		}
+	}
+
+	for _, predicate := range m.predicates {
+		if predicate(origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// uuidParamKeyPrefix prefixes the gin context keys holding parsed UUID params
+const uuidParamKeyPrefix = "uuid_param:"
+
+// ValidateUUIDParams rejects requests whose named path params are not
+// canonical UUIDs with a 400, before they reach the handler or database.
+// Params absent from the matched route are skipped, so it can be mounted on
+// a whole route group. Parsed values are available through UUIDParam.
+func ValidateUUIDParams(names ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, name := range names {
+			value, ok := c.Params.Get(name)
+			if !ok {
+				continue
+			}
+
+			// uuid.Parse also accepts braced, URN and undashed forms; only
+			// the canonical 36 character form is a valid path param
+			id, err := uuid.Parse(value)
+			if err != nil || len(value) != 36 {
+				c.JSON(http.StatusBadRequest, ErrorResponse(c, "Invalid "+name+": must be a UUID", "INVALID_PATH_PARAMETER"))
+				c.Abort()
+				return
+			}
+
+			c.Set(uuidParamKeyPrefix+name, id)
+		}
+
+		c.Next()
+	}
+}
+
+// UUIDParam returns a path param validated by ValidateUUIDParams
+func UUIDParam(c *gin.Context, name string) (uuid.UUID, bool) {
+	value, ok := c.Get(uuidParamKeyPrefix + name)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, ok := value.(uuid.UUID)
+	return id, ok
+}
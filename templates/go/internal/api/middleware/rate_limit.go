@@ -3,12 +3,14 @@ package middleware
 import (
 	"context"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 
 	"app/internal/config"
 	"app/internal/utils"
@@ -19,15 +21,42 @@ type RateLimiter struct {
 	redisClient *redis.Client
 	config      *config.Config
 	logger      *utils.Logger
+	formatter   ResponseFormatter
+	metrics     *RateLimitMetrics
 }
 
-// NewRateLimiter creates a new rate limiter
-func NewRateLimiter(redisClient *redis.Client, cfg *config.Config, logger *utils.Logger) *RateLimiter {
-	return &RateLimiter{
+// RateLimiterOption configures optional RateLimiter behaviour
+type RateLimiterOption func(*RateLimiter)
+
+// WithResponseFormatter sets the formatter used for 429 response bodies on
+// every limit that does not set its own FormatResponse or OnLimitFunc
+func WithResponseFormatter(formatter ResponseFormatter) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.formatter = formatter
+	}
+}
+
+// WithRateLimitMetrics counts rejected requests in metrics
+func WithRateLimitMetrics(metrics *RateLimitMetrics) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.metrics = metrics
+	}
+}
+
+// NewRateLimiter creates a new rate limiter. A nil Redis client disables
+// rate limiting: every request is allowed, matching how Redis errors fail open.
+func NewRateLimiter(redisClient *redis.Client, cfg *config.Config, logger *utils.Logger, opts ...RateLimiterOption) *RateLimiter {
+	rl := &RateLimiter{
 		redisClient: redisClient,
 		config:      cfg,
 		logger:      logger,
 	}
+
+	for _, opt := range opts {
+		opt(rl)
+	}
+
+	return rl
 }
 
 // RateLimitConfig represents rate limiting configuration for different endpoints
@@ -37,6 +66,15 @@ type RateLimitConfig struct {
 	KeyFunc     KeyFunc       // Function to generate rate limit key
 	SkipFunc    SkipFunc      // Function to determine if rate limiting should be skipped
 	OnLimitFunc OnLimitFunc   // Function called when rate limit is exceeded
+	Category    string        // Label for metrics on rejected requests; defaults to "custom"
+
+	// Sliding counts requests in the trailing Window instead of in fixed
+	// windows, so bursts straddling a window boundary cannot exceed Requests
+	Sliding bool
+
+	// FormatResponse builds the 429 response body; it is ignored when
+	// OnLimitFunc is set. Defaults to the limiter's formatter.
+	FormatResponse ResponseFormatter
 }
 
 // KeyFunc generates a rate limiting key for the request
@@ -48,6 +86,34 @@ type SkipFunc func(*gin.Context) bool
 // OnLimitFunc is called when rate limit is exceeded
 type OnLimitFunc func(*gin.Context)
 
+// RateLimitInfo describes the limit a request exceeded
+type RateLimitInfo struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+	Window    time.Duration
+}
+
+// ResponseFormatter builds the body of a 429 response. The rate limit
+// headers are set by the limiter regardless of the body.
+type ResponseFormatter func(c *gin.Context, info RateLimitInfo) interface{}
+
+// DefaultResponseFormatter returns the standard error envelope with the
+// remaining count and reset time
+func DefaultResponseFormatter(c *gin.Context, info RateLimitInfo) interface{} {
+	response := ErrorResponse(c, "Rate limit exceeded", "RATE_LIMIT_EXCEEDED")
+	response["remaining"] = info.Remaining
+	response["reset_at"] = info.ResetAt.Unix()
+	response["retry_after_seconds"] = retryAfterSeconds(info.ResetAt)
+	return response
+}
+
+// MinimalResponseFormatter returns only the error code, for clients that
+// need the smallest possible body
+func MinimalResponseFormatter(c *gin.Context, info RateLimitInfo) interface{} {
+	return gin.H{"code": "RATE_LIMIT_EXCEEDED"}
+}
+
 // DefaultKeyFunc generates a key based on client IP
 func DefaultKeyFunc(c *gin.Context) string {
 	return "rate_limit:" + c.ClientIP()
@@ -74,8 +140,8 @@ func UserKeyFunc(prefix string) KeyFunc {
 // RateLimit applies rate limiting based on the provided configuration
 func (rl *RateLimiter) RateLimit(config RateLimitConfig) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Skip rate limiting if skip function returns true
-		if config.SkipFunc != nil && config.SkipFunc(c) {
+		// Skip rate limiting if Redis is disabled or skip function returns true
+		if rl.redisClient == nil || (config.SkipFunc != nil && config.SkipFunc(c)) {
 			c.Next()
 			return
 		}
@@ -87,7 +153,11 @@ func (rl *RateLimiter) RateLimit(config RateLimitConfig) gin.HandlerFunc {
 		}
 
 		// Check rate limit
-		allowed, remaining, resetTime, err := rl.checkRateLimit(key, config.Requests, config.Window)
+		check := rl.checkRateLimit
+		if config.Sliding {
+			check = rl.SlidingWindowRateLimit
+		}
+		allowed, remaining, resetTime, err := check(key, config.Requests, config.Window)
 		if err != nil {
 			rl.logger.Error("Rate limiting error", "error", err, "key", key)
 			// On error, allow the request but log the issue
@@ -107,15 +177,27 @@ func (rl *RateLimiter) RateLimit(config RateLimitConfig) gin.HandlerFunc {
 				"ip", c.ClientIP(),
 				"user_agent", c.GetHeader("User-Agent"))
 
+			c.Header("Retry-After", retryAfter(resetTime))
+
+			category := config.Category
+			if category == "" {
+				category = "custom"
+			}
+			rl.metrics.recordBlocked(category, config.Window.String())
+
 			if config.OnLimitFunc != nil {
 				config.OnLimitFunc(c)
 			} else {
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error":     "Rate limit exceeded",
-					"code":      "RATE_LIMIT_EXCEEDED",
-					"remaining": remaining,
-					"reset_at":  resetTime.Unix(),
-				})
+				formatter := config.FormatResponse
+				if formatter == nil {
+					formatter = rl.responseFormatter()
+				}
+				c.JSON(http.StatusTooManyRequests, formatter(c, RateLimitInfo{
+					Limit:     config.Requests,
+					Remaining: remaining,
+					ResetAt:   resetTime,
+					Window:    config.Window,
+				}))
 			}
 			c.Abort()
 			return
@@ -125,6 +207,40 @@ func (rl *RateLimiter) RateLimit(config RateLimitConfig) gin.HandlerFunc {
 	}
 }
 
+// responseFormatter returns the limiter-wide formatter, falling back to the default
+func (rl *RateLimiter) responseFormatter() ResponseFormatter {
+	if rl.formatter != nil {
+		return rl.formatter
+	}
+	return DefaultResponseFormatter
+}
+
+// retryAfter returns the Retry-After header value in whole seconds until resetTime
+func retryAfter(resetTime time.Time) string {
+	return strconv.Itoa(retryAfterSeconds(resetTime))
+}
+
+// retryAfterSeconds returns the whole seconds until resetTime, rounded up and
+// at least 1 so that clients never retry early
+func retryAfterSeconds(resetTime time.Time) int {
+	seconds := int(math.Ceil(time.Until(resetTime).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// fixedWindowScript increments a window counter and returns the new count,
+// setting the expiry only when the window's first request creates the key so
+// later requests cannot extend the window
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call("INCR", KEYS[1])
+if count == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return count
+`)
+
 // checkRateLimit checks if a request is allowed under the rate limit
 func (rl *RateLimiter) checkRateLimit(key string, requests int, window time.Duration) (allowed bool, remaining int, resetTime time.Time, err error) {
 	ctx := context.Background()
@@ -132,30 +248,15 @@ func (rl *RateLimiter) checkRateLimit(key string, requests int, window time.Dura
 	windowStart := now.Truncate(window)
 	resetTime = windowStart.Add(window)
 
-	// Use Redis pipeline for atomic operations
-	pipe := rl.redisClient.Pipeline()
-	
 	// Current count key
-	countKey := fmt.Sprintf("%s:%d", key, windowStart.Unix())
-	
-	// Get current count
-	getCmd := pipe.Get(ctx, countKey)
-	
-	// Increment count
-	incrCmd := pipe.Incr(ctx, countKey)
-	
-	// Set expiration (only if this is the first increment)
-	pipe.Expire(ctx, countKey, window)
-	
-	// Execute pipeline
-	_, err = pipe.Exec(ctx)
-	if err != nil && err != redis.Nil {
-		return false, 0, resetTime, fmt.Errorf("failed to execute rate limit pipeline: %w", err)
+	countKey := fmt.Sprintf("%s%s:%d", rl.config.RedisKeyPrefix, key, windowStart.Unix())
+
+	// Increment and expire atomically, so concurrent requests see distinct counts
+	currentCount, err := fixedWindowScript.Run(ctx, rl.redisClient, []string{countKey}, window.Milliseconds()).Int()
+	if err != nil {
+		return false, 0, resetTime, fmt.Errorf("failed to run rate limit script: %w", err)
 	}
 
-	// Get the incremented count
-	currentCount := int(incrCmd.Val())
-	
 	// Check if limit is exceeded
 	if currentCount > requests {
 		return false, 0, resetTime, nil
@@ -165,12 +266,79 @@ func (rl *RateLimiter) checkRateLimit(key string, requests int, window time.Dura
 	return true, remaining, resetTime, nil
 }
 
+// slidingWindowScript drops the requests that have left the window, then
+// records this one only if the window is still under the limit, so rejected
+// requests never take a slot. It returns whether the request is allowed, the
+// requests now in the window and the score of the oldest of them.
+var slidingWindowScript = redis.NewScript(`
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", ARGV[1])
+local count = redis.call("ZCARD", KEYS[1])
+
+local allowed = 0
+if count < tonumber(ARGV[3]) then
+	redis.call("ZADD", KEYS[1], ARGV[2], ARGV[4])
+	redis.call("PEXPIRE", KEYS[1], ARGV[5])
+	count = count + 1
+	allowed = 1
+end
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {allowed, count, oldest[2] or ARGV[2]}
+`)
+
+// SlidingWindowRateLimit checks if a request is allowed under the rate limit
+// counting the requests made in the trailing window. Each allowed request is
+// kept as a timestamped member of a sorted set; rejected requests are not
+// recorded, so they do not extend the block.
+func (rl *RateLimiter) SlidingWindowRateLimit(key string, requests int, window time.Duration) (allowed bool, remaining int, resetTime time.Time, err error) {
+	ctx := context.Background()
+	now := time.Now()
+	if rl.redisClient == nil {
+		return true, requests, now.Add(window), nil
+	}
+
+	setKey := fmt.Sprintf("%s%s:sliding", rl.config.RedisKeyPrefix, key)
+	member := fmt.Sprintf("%d-%s", now.UnixNano(), uuid.NewString())
+
+	// Prune, count and record in one script, so concurrent requests cannot
+	// all be recorded before any of them is counted
+	result, err := slidingWindowScript.Run(ctx, rl.redisClient, []string{setKey},
+		strconv.FormatInt(now.Add(-window).UnixNano(), 10),
+		strconv.FormatInt(now.UnixNano(), 10),
+		requests,
+		member,
+		window.Milliseconds(),
+	).Slice()
+	if err != nil {
+		return false, 0, now.Add(window), fmt.Errorf("failed to run sliding rate limit script: %w", err)
+	}
+	if len(result) != 3 {
+		return false, 0, now.Add(window), fmt.Errorf("unexpected sliding rate limit script result: %v", result)
+	}
+
+	allowedFlag, _ := result[0].(int64)
+	currentCount, _ := result[1].(int64)
+	oldestScore, _ := result[2].(string)
+
+	// The window frees up a slot once its oldest request ages out
+	resetTime = now.Add(window)
+	if oldest, parseErr := strconv.ParseFloat(oldestScore, 64); parseErr == nil {
+		resetTime = time.Unix(0, int64(oldest)).Add(window)
+	}
+
+	if allowedFlag != 1 {
+		return false, 0, resetTime, nil
+	}
+	return true, requests - int(currentCount), resetTime, nil
+}
+
 // GlobalRateLimit applies global rate limiting by IP
 func (rl *RateLimiter) GlobalRateLimit() gin.HandlerFunc {
 	return rl.RateLimit(RateLimitConfig{
 		Requests: rl.config.RateLimitRPS,
 		Window:   time.Minute,
 		KeyFunc:  IPKeyFunc("global"),
+		Category: "global",
 	})
 }
 
@@ -180,12 +348,15 @@ func (rl *RateLimiter) AuthRateLimit() gin.HandlerFunc {
 		Requests: 5, // 5 attempts per minute
 		Window:   time.Minute,
 		KeyFunc:  IPKeyFunc("auth"),
+		Category: "auth",
 		OnLimitFunc: func(c *gin.Context) {
-			c.JSON(http.StatusTooManyRequests, gin.H{
-				"error":   "Too many authentication attempts",
-				"code":    "AUTH_RATE_LIMIT_EXCEEDED",
-				"message": "Please try again later",
-			})
+			response := ErrorResponse(c, "Too many authentication attempts", "AUTH_RATE_LIMIT_EXCEEDED")
+			response["message"] = "Please try again later"
+			// RateLimit sets Retry-After before calling OnLimitFunc
+			if seconds, err := strconv.Atoi(c.Writer.Header().Get("Retry-After")); err == nil {
+				response["retry_after_seconds"] = seconds
+			}
+			c.JSON(http.StatusTooManyRequests, response)
 		},
 	})
 }
@@ -196,6 +367,7 @@ func (rl *RateLimiter) APIRateLimit() gin.HandlerFunc {
 		Requests: 100, // 100 requests per minute
 		Window:   time.Minute,
 		KeyFunc:  UserKeyFunc("api"),
+		Category: "api",
 		SkipFunc: func(c *gin.Context) bool {
 			// Skip rate limiting for admin users
 			userRoles, exists := c.Get("user_roles")
@@ -222,6 +394,7 @@ func (rl *RateLimiter) StrictRateLimit() gin.HandlerFunc {
 		Requests: 10, // 10 requests per hour
 		Window:   time.Hour,
 		KeyFunc:  IPKeyFunc("strict"),
+		Category: "strict",
 	})
 }
 
@@ -231,12 +404,103 @@ func (rl *RateLimiter) BurstRateLimit(requests int, window time.Duration) gin.Ha
 		Requests: requests,
 		Window:   window,
 		KeyFunc:  UserKeyFunc("burst"),
+		Category: "burst",
 	})
 }
 
+// tokenBucketScript refills a bucket by the time elapsed since its last
+// refill, then takes one token if available. It returns whether the request
+// is allowed, the whole tokens left and the milliseconds until the next token.
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local bucket = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(bucket[1])
+local ts = tonumber(bucket[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", tostring(now))
+redis.call("PEXPIRE", KEYS[1], math.ceil(burst / rate * 1000) + 1000)
+
+local wait = 0
+if tokens < 1 then
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+return {allowed, math.floor(tokens), wait}
+`)
+
+// TokenBucketRateLimit limits each user to a steady rate of requests per
+// second while allowing bursts of up to burst requests after idle periods.
+// The bucket is kept in Redis, so the limit is shared by all instances.
+func (rl *RateLimiter) TokenBucketRateLimit(rate float64, burst int) gin.HandlerFunc {
+	keyFunc := UserKeyFunc("token_bucket")
+
+	return func(c *gin.Context) {
+		if rl.redisClient == nil {
+			c.Next()
+			return
+		}
+
+		key := rl.config.RedisKeyPrefix + keyFunc(c)
+		now := time.Now()
+		result, err := tokenBucketScript.Run(c.Request.Context(), rl.redisClient, []string{key}, rate, burst, now.UnixMilli()).Int64Slice()
+		if err != nil || len(result) != 3 {
+			rl.logger.Error("Token bucket rate limiting error", "error", err, "key", key)
+			// On error, allow the request but log the issue
+			c.Next()
+			return
+		}
+
+		allowed, remaining := result[0] == 1, int(result[1])
+		resetTime := now.Add(time.Duration(result[2]) * time.Millisecond)
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(burst))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
+
+		if !allowed {
+			rl.logger.Warn("Token bucket rate limit exceeded",
+				"key", key,
+				"ip", c.ClientIP())
+
+			c.Header("Retry-After", retryAfter(resetTime))
+			rl.metrics.recordBlocked("token_bucket", strconv.FormatFloat(rate, 'f', -1, 64)+"/s")
+
+			c.JSON(http.StatusTooManyRequests, rl.responseFormatter()(c, RateLimitInfo{
+				Limit:     burst,
+				Remaining: remaining,
+				ResetAt:   resetTime,
+				Window:    time.Duration(float64(time.Second) / rate),
+			}))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
 // ProgressiveRateLimit applies progressive rate limiting with increasing restrictions
 func (rl *RateLimiter) ProgressiveRateLimit() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if rl.redisClient == nil {
+			c.Next()
+			return
+		}
+
 		ip := c.ClientIP()
 		
 		// Check different time windows with different limits
@@ -270,14 +534,27 @@ func (rl *RateLimiter) ProgressiveRateLimit() gin.HandlerFunc {
 				c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
 				c.Header("X-RateLimit-Reset", strconv.FormatInt(resetTime.Unix(), 10))
 				c.Header("X-RateLimit-Window", w.name)
+				c.Header("Retry-After", retryAfter(resetTime))
+
+				rl.metrics.recordBlocked("progressive", w.name)
+
+				if rl.formatter != nil {
+					c.JSON(http.StatusTooManyRequests, rl.formatter(c, RateLimitInfo{
+						Limit:     w.requests,
+						Remaining: remaining,
+						ResetAt:   resetTime,
+						Window:    w.window,
+					}))
+					c.Abort()
+					return
+				}
 
-				c.JSON(http.StatusTooManyRequests, gin.H{
-					"error":     "Rate limit exceeded",
-					"code":      "PROGRESSIVE_RATE_LIMIT_EXCEEDED",
-					"window":    w.name,
-					"limit":     w.requests,
-					"reset_at":  resetTime.Unix(),
-				})
+				response := ErrorResponse(c, "Rate limit exceeded", "PROGRESSIVE_RATE_LIMIT_EXCEEDED")
+				response["window"] = w.name
+				response["limit"] = w.requests
+				response["reset_at"] = resetTime.Unix()
+				response["retry_after_seconds"] = retryAfterSeconds(resetTime)
+				c.JSON(http.StatusTooManyRequests, response)
 				c.Abort()
 				return
 			}
@@ -301,7 +578,11 @@ func (rl *RateLimiter) GetRateLimitStatus(key string, requests int, window time.
 	windowStart := now.Truncate(window)
 	resetTime = windowStart.Add(window)
 
-	countKey := fmt.Sprintf("%s:%d", key, windowStart.Unix())
+	if rl.redisClient == nil {
+		return requests, resetTime, nil
+	}
+
+	countKey := fmt.Sprintf("%s%s:%d", rl.config.RedisKeyPrefix, key, windowStart.Unix())
 	
 	currentCountStr, err := rl.redisClient.Get(ctx, countKey).Result()
 	if err != nil {
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RateLimitMetrics counts requests rejected by the rate limiter so operators
+// can see which limits are being hit
+type RateLimitMetrics struct {
+	blocked *prometheus.CounterVec
+}
+
+// NewRateLimitMetrics creates the rate limit counters and registers them
+// with registerer
+func NewRateLimitMetrics(registerer prometheus.Registerer) *RateLimitMetrics {
+	m := &RateLimitMetrics{
+		blocked: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "rate_limit_blocked_requests_total",
+			Help: "Requests rejected by a rate limit, by limit category and window.",
+		}, []string{"category", "window"}),
+	}
+	registerer.MustRegister(m.blocked)
+	return m
+}
+
+// Blocked returns the counter for requests rejected by the given limit
+func (m *RateLimitMetrics) Blocked(category, window string) prometheus.Counter {
+	return m.blocked.WithLabelValues(category, window)
+}
+
+// recordBlocked counts a rejected request; it is a no-op without metrics
+func (m *RateLimitMetrics) recordBlocked(category, window string) {
+	if m == nil {
+		return
+	}
+	m.Blocked(category, window).Inc()
+}
@@ -3,28 +3,61 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 
 	"app/internal/config"
 	"app/internal/utils"
 )
 
+const (
+	// RequestIDHeader is the header carrying the request ID in both directions
+	RequestIDHeader = "X-Request-ID"
+
+	// RequestIDKey is the gin context key holding the request ID
+	RequestIDKey = "request_id"
+
+	// maxRequestIDLength bounds client-supplied request IDs
+	maxRequestIDLength = 128
+)
+
 // SecurityMiddleware provides various security middleware functions
 type SecurityMiddleware struct {
-	config *config.Config
-	logger *utils.Logger
+	config           *config.Config
+	logger           *utils.Logger
+	originPredicates []func(origin string) bool
+}
+
+// SecurityMiddlewareOption configures optional SecurityMiddleware behaviour
+type SecurityMiddlewareOption func(*SecurityMiddleware)
+
+// WithOriginPredicate allows CORS requests from any origin for which
+// allowed returns true, in addition to the configured origins
+func WithOriginPredicate(allowed func(origin string) bool) SecurityMiddlewareOption {
+	return func(s *SecurityMiddleware) {
+		s.originPredicates = append(s.originPredicates, allowed)
+	}
 }
 
 // NewSecurityMiddleware creates a new security middleware
-func NewSecurityMiddleware(cfg *config.Config, logger *utils.Logger) *SecurityMiddleware {
-	return &SecurityMiddleware{
+func NewSecurityMiddleware(cfg *config.Config, logger *utils.Logger, opts ...SecurityMiddlewareOption) *SecurityMiddleware {
+	s := &SecurityMiddleware{
 		config: cfg,
 		logger: logger,
 	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
 }
 
 // SecurityHeaders adds security headers to responses
@@ -80,32 +113,109 @@ func (s *SecurityMiddleware) SecurityHeaders() gin.HandlerFunc {
 	}
 }
 
-// CORS configures Cross-Origin Resource Sharing
+// CORS configures Cross-Origin Resource Sharing. Origins are checked per
+// request against the configured origins, which may use wildcard
+// subdomains, and any origin predicates; an allowed request gets its own
+// origin echoed back, never "*", since credentials are allowed.
 func (s *SecurityMiddleware) CORS() gin.HandlerFunc {
-	config := cors.Config{
-		AllowOrigins:     s.config.CORSAllowedOrigins,
+	predicates := append([]func(origin string) bool{}, s.originPredicates...)
+
+	// In development, also allow local dev servers on any port in the
+	// configured range; other environments only allow explicit origins
+	if s.config.IsDevelopment() && s.config.CORSDevLocalhostPortMin > 0 {
+		minPort, maxPort := s.config.CORSDevLocalhostPortMin, s.config.CORSDevLocalhostPortMax
+		predicates = append(predicates, func(origin string) bool {
+			return isLocalhostOrigin(origin, minPort, maxPort)
+		})
+		s.logger.Warn("CORS allows localhost origins in development; this must not be enabled in production",
+			"port_min", minPort,
+			"port_max", maxPort,
+		)
+	}
+
+	for _, origin := range s.config.CORSAllowedOrigins {
+		if strings.TrimSpace(origin) == "*" {
+			s.logger.Warn("CORS origin \"*\" is ignored because credentials are allowed; list origins explicitly")
+		}
+	}
+
+	matcher := NewOriginMatcher(s.config.CORSAllowedOrigins, predicates...)
+	return cors.New(cors.Config{
+		AllowOriginFunc:  matcher.Allowed,
 		AllowMethods:     s.config.CORSAllowedMethods,
 		AllowHeaders:     s.config.CORSAllowedHeaders,
-		ExposeHeaders:    []string{"Content-Length", "X-Request-ID"},
+		ExposeHeaders:    s.config.CORSExposedHeaders,
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
+	})
+}
+
+// isLocalhostOrigin reports whether origin is an http(s) loopback origin
+// with an explicit port in [minPort, maxPort]
+func isLocalhostOrigin(origin string, minPort, maxPort int) bool {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
 	}
 
-	// In development, allow all origins
-	if s.config.IsDevelopment() {
-		config.AllowAllOrigins = false // Keep explicit origins for security
+	switch u.Hostname() {
+	case "localhost", "127.0.0.1", "::1":
+	default:
+		return false
 	}
 
-	return cors.New(config)
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return false
+	}
+	return port >= minPort && port <= maxPort
 }
 
-// RequestID adds a unique request ID to each request
+// RequestID adds a unique request ID to each request. A well-formed incoming
+// X-Request-ID header is reused so IDs can be traced across services.
 func (s *SecurityMiddleware) RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !isValidRequestID(requestID) {
+			requestID = uuid.New().String()
+		}
+
+		c.Set(RequestIDKey, requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// RequestIDFromContext returns the request ID set by RequestID, or "" when
+// the middleware did not run.
+func RequestIDFromContext(c *gin.Context) string {
+	return c.GetString(RequestIDKey)
+}
+
+// Recovery recovers from panics, logging the stack trace with the request ID.
+// Outside development the response carries only a generic error and the
+// request ID for correlation; in development the panic value and stack are
+// included to ease debugging.
+func (s *SecurityMiddleware) Recovery() gin.HandlerFunc {
 	return gin.CustomRecovery(func(c *gin.Context, recovered interface{}) {
-		if err, ok := recovered.(string); ok {
-			c.String(http.StatusInternalServerError, fmt.Sprintf("error: %s", err))
+		requestID := RequestIDFromContext(c)
+		stack := string(debug.Stack())
+
+		s.logger.WithRequestID(requestID).Error("Panic recovered",
+			"error", fmt.Sprint(recovered),
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"stack", stack,
+		)
+
+		response := ErrorResponse(c, "Internal server error", "INTERNAL_ERROR")
+		if s.config.IsDevelopment() {
+			response["details"] = fmt.Sprint(recovered)
+			response["stack"] = stack
 		}
-		c.AbortWithStatus(http.StatusInternalServerError)
+
+		c.AbortWithStatusJSON(http.StatusInternalServerError, response)
 	})
 }
 
@@ -121,10 +231,7 @@ func (s *SecurityMiddleware) IPWhitelist(allowedIPs []string) gin.HandlerFunc {
 		
 		if !allowedIPMap[clientIP] {
 			s.logger.Warn("Access denied - IP not whitelisted", "ip", clientIP)
-			c.JSON(http.StatusForbidden, gin.H{
-				"error": "Access denied",
-				"code":  "IP_NOT_ALLOWED",
-			})
+			c.JSON(http.StatusForbidden, ErrorResponse(c, "Access denied", "IP_NOT_ALLOWED"))
 			c.Abort()
 			return
 		}
@@ -160,10 +267,7 @@ func (s *SecurityMiddleware) ContentTypeValidation() gin.HandlerFunc {
 					"method", c.Request.Method,
 					"ip", c.ClientIP())
 				
-				c.JSON(http.StatusUnsupportedMediaType, gin.H{
-					"error": "Unsupported content type",
-					"code":  "INVALID_CONTENT_TYPE",
-				})
+				c.JSON(http.StatusUnsupportedMediaType, ErrorResponse(c, "Unsupported content type", "INVALID_CONTENT_TYPE"))
 				c.Abort()
 				return
 			}
@@ -182,10 +286,7 @@ func (s *SecurityMiddleware) RequestSizeLimit(maxSize int64) gin.HandlerFunc {
 				"max_size", maxSize,
 				"ip", c.ClientIP())
 			
-			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
-				"error": "Request body too large",
-				"code":  "REQUEST_TOO_LARGE",
-			})
+			c.JSON(http.StatusRequestEntityTooLarge, ErrorResponse(c, "Request body too large", "REQUEST_TOO_LARGE"))
 			c.Abort()
 			return
 		}
@@ -208,10 +309,7 @@ func (s *SecurityMiddleware) APIKeyAuth(validAPIKeys []string) gin.HandlerFunc {
 		}
 
 		if apiKey == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "API key is required",
-				"code":  "MISSING_API_KEY",
-			})
+			c.JSON(http.StatusUnauthorized, ErrorResponse(c, "API key is required", "MISSING_API_KEY"))
 			c.Abort()
 			return
 		}
@@ -221,10 +319,7 @@ func (s *SecurityMiddleware) APIKeyAuth(validAPIKeys []string) gin.HandlerFunc {
 				"api_key", apiKey[:8]+"...", // Log partial key for security
 				"ip", c.ClientIP())
 			
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Invalid API key",
-				"code":  "INVALID_API_KEY",
-			})
+			c.JSON(http.StatusUnauthorized, ErrorResponse(c, "Invalid API key", "INVALID_API_KEY"))
 			c.Abort()
 			return
 		}
@@ -267,30 +362,6 @@ func (s *SecurityMiddleware) SecureJSON() gin.HandlerFunc {
 	}
 }
 
-// CSRFProtection provides basic CSRF protection
-func (s *SecurityMiddleware) CSRFProtection() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Skip CSRF protection for GET, HEAD, OPTIONS
-		if c.Request.Method == "GET" || c.Request.Method == "HEAD" || c.Request.Method == "OPTIONS" {
-			c.Next()
-			return
-		}
-
-		// Check for CSRF token in header or form
-		csrfToken := c.GetHeader("X-CSRF-Token")
-		if csrfToken == "" {
-			csrfToken = c.PostForm("csrf_token")
-		}
-
-		// For now, just log CSRF checks - implement full CSRF protection as needed
-		if csrfToken == "" {
-			s.logger.Warn("Missing CSRF token", "method", c.Request.Method, "ip", c.ClientIP())
-		}
-
-		c.Next()
-	}
-}
-
 // XSSProtection adds XSS protection headers and basic filtering
 func (s *SecurityMiddleware) XSSProtection() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -300,4 +371,18 @@ func (s *SecurityMiddleware) XSSProtection() gin.HandlerFunc {
 		
 		c.Next()
 	}
-}
\ No newline at end of file
+}
+
+// isValidRequestID reports whether a client-supplied request ID is safe to
+// reuse in logs and response headers
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
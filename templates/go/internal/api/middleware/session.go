@@ -0,0 +1,73 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"app/internal/auth"
+	"app/internal/utils"
+)
+
+const (
+	// SessionHeader carries the server-side session ID issued at login
+	SessionHeader = "X-Session-ID"
+
+	// SessionIDKey is the gin context key holding a verified session ID
+	SessionIDKey = "session_id"
+)
+
+// SessionMiddleware keeps server-side sessions alive while they are in use
+type SessionMiddleware struct {
+	sessionService  *auth.SessionService
+	refreshInterval time.Duration
+	logger          *utils.Logger
+}
+
+// NewSessionMiddleware creates a new session middleware. A session is
+// refreshed at most once per refreshInterval to limit Redis writes.
+func NewSessionMiddleware(sessionService *auth.SessionService, refreshInterval time.Duration, logger *utils.Logger) *SessionMiddleware {
+	return &SessionMiddleware{
+		sessionService:  sessionService,
+		refreshInterval: refreshInterval,
+		logger:          logger,
+	}
+}
+
+// SlidingExpiration extends the timeout of the session named in the
+// X-Session-ID header on each authenticated request, so sessions only expire
+// once their user goes idle. It must be mounted after RequireAuth. Missing,
+// expired or foreign sessions are ignored and never fail the request.
+func (s *SessionMiddleware) SlidingExpiration() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		sessionID := c.GetHeader(SessionHeader)
+		if sessionID == "" || !isAuthenticated(c) {
+			c.Next()
+			return
+		}
+
+		session, err := s.sessionService.GetSession(c.Request.Context(), sessionID)
+		if err != nil {
+			c.Next()
+			return
+		}
+
+		// Only the session's owner may keep it alive
+		userID, ok := c.Get("user_id")
+		if id, isUUID := userID.(uuid.UUID); !ok || !isUUID || id != session.UserID {
+			c.Next()
+			return
+		}
+
+		c.Set(SessionIDKey, sessionID)
+
+		if time.Since(session.LastActivity) >= s.refreshInterval {
+			if err := s.sessionService.RefreshSession(c.Request.Context(), sessionID); err != nil {
+				s.logger.Warn("Failed to refresh session", "error", err, "session_id", sessionID)
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -1,14 +1,20 @@
 package routes
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
 	"gorm.io/gorm"
 
 	"app/internal/api/handlers"
 	"app/internal/api/middleware"
 	"app/internal/auth"
 	"app/internal/config"
+	"app/internal/repository/cache"
 	"app/internal/repository/postgres"
 	"app/internal/services"
 	"app/internal/utils"
@@ -20,32 +26,116 @@ type Dependencies struct {
 	RedisClient *redis.Client
 	Config      *config.Config
 	Logger      *utils.Logger
+
+	// KeySet, when set, signs access tokens with rotating RSA keys and
+	// publishes their public halves at /.well-known/jwks.json
+	KeySet *auth.KeySet
 }
 
-// Setup configures all routes and middleware
-func Setup(router *gin.Engine, deps *Dependencies) {
+// Setup configures all routes and middleware. It fails when a security
+// feature that is configured cannot be set up, rather than serving without it.
+func Setup(router *gin.Engine, deps *Dependencies) error {
 	// Initialize services
-	userRepo := postgres.NewUserRepository(deps.DB)
-	jwtService := auth.NewJWTService(deps.Config.JWTSecret, "go-api", deps.Config.JWTExpirationHours)
-	passwordService := auth.NewPasswordService(deps.Config.BCryptCost)
-	sessionService := auth.NewSessionService(deps.RedisClient, deps.Config.SessionTimeout)
-	authService := services.NewAuthService(userRepo, jwtService, passwordService, sessionService, deps.RedisClient, deps.Config, deps.Logger, deps.DB)
+	userRepo := cache.NewCachingUserRepository(
+		postgres.NewUserRepository(deps.DB,
+			postgres.WithMaxRolesPerUser(deps.Config.MaxRolesPerUser),
+			postgres.WithBulkBatchSize(deps.Config.BulkBatchSize),
+		),
+		deps.RedisClient,
+		time.Duration(deps.Config.UserCacheTTLSeconds)*time.Second,
+		cache.WithKeyPrefix(deps.Config.RedisKeyPrefix),
+	)
+	roleRepo := postgres.NewRoleRepository(deps.DB)
+	jwtOptions := []auth.JWTOption{
+		auth.WithNotBeforeSkew(time.Duration(deps.Config.JWTNotBeforeSkewSeconds) * time.Second),
+		auth.WithAudiences(deps.Config.JWTAcceptedAudiences...),
+	}
+	if deps.Config.JWTBindIP {
+		jwtOptions = append(jwtOptions, auth.WithIPBinding(deps.Config.JWTBindIPv4Prefix, deps.Config.JWTBindIPv6Prefix))
+	}
+	jwtService := auth.NewJWTService(deps.Config.JWTSecret, "go-api", deps.Config.JWTExpirationHours, jwtOptions...)
+	if deps.KeySet != nil {
+		jwtService = auth.NewJWTServiceWithKeySet(deps.KeySet, "go-api", deps.Config.JWTExpirationHours, jwtOptions...)
+	}
+	jwtService.SetLeeway(time.Duration(deps.Config.JWTLeewaySeconds) * time.Second)
+	passwordService := auth.NewPasswordService(deps.Config.BCryptCost,
+		auth.WithPasswordPolicy(auth.PasswordPolicy{
+			MinLength:           deps.Config.PasswordMinLength,
+			MaxLength:           deps.Config.PasswordMaxLength,
+			RequireUppercase:    deps.Config.PasswordRequireUppercase,
+			RequireLowercase:    deps.Config.PasswordRequireLowercase,
+			RequireNumbers:      deps.Config.PasswordRequireNumbers,
+			RequireSpecialChars: deps.Config.PasswordRequireSpecialChars,
+			ForbidSequential:    deps.Config.PasswordForbidSequential,
+			ForbidRepeated:      deps.Config.PasswordForbidRepeated,
+		}),
+		auth.WithMinEntropyBits(float64(deps.Config.PasswordMinEntropyBits)),
+	)
+	sessionOptions := []auth.SessionOption{
+		auth.WithKeyPrefix(deps.Config.RedisKeyPrefix),
+		auth.WithSessionLimitPolicy(auth.SessionLimitPolicy{
+			Default:     deps.Config.MaxConcurrentSessions,
+			ByRole:      deps.Config.SessionLimitsByRole,
+			EvictOldest: deps.Config.SessionEvictOldest,
+		}),
+		auth.WithMaxSessionSize(deps.Config.SessionMaxBytes),
+		auth.WithTimeoutsByMethod(sessionTimeoutsByMethod(deps.Config.SessionTimeoutsByMethod)),
+		auth.WithMaxLifetime(time.Duration(deps.Config.SessionMaxLifetimeSeconds) * time.Second),
+	}
+	sessionEncryption, err := sessionEncryptionOptions(deps.Config)
+	if err != nil {
+		return fmt.Errorf("failed to set up session encryption: %w", err)
+	}
+	sessionOptions = append(sessionOptions, sessionEncryption...)
+	sessionService := auth.NewSessionService(
+		deps.RedisClient,
+		time.Duration(deps.Config.SessionTimeout)*time.Second,
+		sessionOptions...,
+	)
+	tokenBlacklist := auth.NewRedisTokenBlacklist(deps.RedisClient, deps.Config.RedisKeyPrefix)
+	authService := services.NewAuthService(userRepo, jwtService, passwordService, sessionService, auth.NewBlacklistService(tokenBlacklist), deps.RedisClient, deps.Config, deps.Logger, deps.DB)
+	if _, err := authService.BootstrapAdmin(context.Background()); err != nil {
+		deps.Logger.Error("Failed to bootstrap admin user", "error", err)
+	}
+	auditLogRepo := postgres.NewAuditLogRepository(deps.DB, postgres.WithMaxDetailBytes(deps.Config.AuditMaxDetailBytes))
+	roleService := services.NewRoleService(roleRepo, auditLogRepo, deps.Logger)
+	userService := services.NewUserService(userRepo, deps.Logger)
 
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(jwtService, deps.Logger)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, deps.Logger,
+		middleware.WithTokenVersionSource(userRepo),
+		middleware.WithTokenBlacklist(tokenBlacklist),
+	)
 	securityMiddleware := middleware.NewSecurityMiddleware(deps.Config, deps.Logger)
-	rateLimiter := middleware.NewRateLimiter(deps.RedisClient, deps.Config, deps.Logger)
+	var rateLimiterOptions []middleware.RateLimiterOption
+	if deps.Config.MetricsEnabled {
+		rateLimiterOptions = append(rateLimiterOptions, middleware.WithRateLimitMetrics(middleware.NewRateLimitMetrics(prometheus.DefaultRegisterer)))
+	}
+	rateLimiter := middleware.NewRateLimiter(deps.RedisClient, deps.Config, deps.Logger, rateLimiterOptions...)
+	featureMiddleware := middleware.NewFeatureMiddleware(deps.Config, deps.Logger)
+	concurrencyLimiter := middleware.NewConcurrencyLimiter(deps.Config.MaxConcurrentRequestsPerUser, deps.Logger)
+	readAuditor := middleware.NewReadAuditor(auditLogRepo, deps.Config.AuditReadRoutes, deps.Logger)
+	sessionMiddleware := middleware.NewSessionMiddleware(sessionService, time.Duration(deps.Config.SessionRefreshIntervalSeconds)*time.Second, deps.Logger)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService, deps.Logger)
-	healthHandler := handlers.NewHealthHandler(deps.DB, deps.RedisClient, deps.Logger)
+	roleHandler := handlers.NewRoleHandler(roleService, deps.Config, deps.Logger)
+	userHandler := handlers.NewUserHandler(userService, deps.Logger)
+	healthHandler := handlers.NewHealthHandler(deps.DB, deps.RedisClient, deps.Logger,
+		handlers.WithHealthCheckTimeout(time.Duration(deps.Config.HealthCheckTimeoutMs)*time.Millisecond),
+	)
 
-	// Global middleware
+	// Global middleware. Recovery follows RequestID so that a panic in any
+	// later middleware still gets the JSON envelope with the request ID.
+	router.Use(securityMiddleware.RequestID())
+	router.Use(securityMiddleware.Recovery())
 	router.Use(securityMiddleware.SecurityHeaders())
 	router.Use(securityMiddleware.CORS())
 	router.Use(rateLimiter.GlobalRateLimit())
+	if deps.Config.CSRFEnabled {
+		router.Use(securityMiddleware.CSRFProtection())
+	}
 	router.Use(middleware.RequestLogger(deps.Logger))
-	router.Use(gin.Recovery())
 
 	// Health check routes (no authentication required)
 	health := router.Group("/health")
@@ -55,15 +145,23 @@ func Setup(router *gin.Engine, deps *Dependencies) {
 		health.GET("/liveness", healthHandler.Liveness)
 	}
 
+	// Public signing keys for token verification
+	if deps.KeySet != nil {
+		router.GET("/.well-known/jwks.json", handlers.JWKSHandler(deps.KeySet))
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
+		v1.GET("/csrf-token", securityMiddleware.CSRFToken())
+
 		// Authentication routes (public)
 		auth := v1.Group("/auth")
 		auth.Use(rateLimiter.AuthRateLimit())
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/login/totp", authHandler.VerifyTOTP)
 			auth.POST("/refresh", authHandler.RefreshToken)
 			auth.POST("/forgot-password", authHandler.ForgotPassword)
 			auth.POST("/reset-password", authHandler.ResetPassword)
@@ -73,15 +171,22 @@ func Setup(router *gin.Engine, deps *Dependencies) {
 		// Protected routes (authentication required)
 		protected := v1.Group("/")
 		protected.Use(authMiddleware.RequireAuth())
+		protected.Use(sessionMiddleware.SlidingExpiration())
+		protected.Use(readAuditor.AuditReads())
+		protected.Use(featureMiddleware.FeatureOverrides())
 		protected.Use(rateLimiter.APIRateLimit())
 		{
 			// User profile routes
-			user := protected.Group("/user")
+			user := protected.Group("/user", middleware.ValidateUUIDParams("session_id"))
 			{
 				user.GET("/profile", authHandler.GetProfile)
 				user.PUT("/profile", authHandler.UpdateProfile)
 				user.POST("/change-password", authHandler.ChangePassword)
+				user.POST("/totp/setup", authHandler.SetupTOTP)
+				user.POST("/totp/enable", authHandler.EnableTOTP)
+				user.POST("/totp/recovery-codes", authHandler.RegenerateRecoveryCodes)
 				user.POST("/logout", authHandler.Logout)
+				user.GET("/security", userHandler.GetOwnSecurityInfo)
 				user.GET("/sessions", authHandler.GetSessions)
 				user.DELETE("/sessions/:session_id", authHandler.RevokeSession)
 			}
@@ -91,15 +196,27 @@ func Setup(router *gin.Engine, deps *Dependencies) {
 			admin.Use(authMiddleware.RequireRole("admin"))
 			{
 				// User management
-				users := admin.Group("/users")
+				users := admin.Group("/users", middleware.ValidateUUIDParams("id"))
 				{
 					users.GET("/", authHandler.ListUsers)
+					users.GET("/export", concurrencyLimiter.Limit(), userHandler.ExportUsers)
+					users.GET("/search", userHandler.SearchUsers)
 					users.GET("/:id", authHandler.GetUser)
 					users.PUT("/:id", authHandler.UpdateUser)
 					users.DELETE("/:id", authHandler.DeleteUser)
 					users.POST("/:id/activate", authHandler.ActivateUser)
 					users.POST("/:id/deactivate", authHandler.DeactivateUser)
 					users.POST("/:id/unlock", authHandler.UnlockUser)
+					users.GET("/:id/permission-source", userHandler.GetPermissionSource)
+					users.GET("/:id/security", userHandler.GetSecurityInfo)
+					users.GET("/:id/role-history", userHandler.GetRoleHistory)
+				}
+
+				// Role management
+				roles := admin.Group("/roles", middleware.ValidateUUIDParams("id"))
+				{
+					roles.GET("/", roleHandler.ListRoles)
+					roles.PUT("/:id", roleHandler.UpdateRole)
 				}
 
 				// System information
@@ -117,11 +234,41 @@ func Setup(router *gin.Engine, deps *Dependencies) {
 		router.GET("/metrics", handlers.PrometheusHandler())
 	}
 
-	// Catch-all route for 404
-	router.NoRoute(func(c *gin.Context) {
-		c.JSON(404, gin.H{
-			"error": "Route not found",
-			"code":  "ROUTE_NOT_FOUND",
-		})
-	})
-}
\ No newline at end of file
+	// Catch-all routes, so unmatched requests still get a JSON error
+	router.HandleMethodNotAllowed = true
+	router.NoRoute(middleware.NotFound())
+	router.NoMethod(middleware.MethodNotAllowed())
+
+	return nil
+}
+
+// sessionTimeoutsByMethod converts the configured per-method session
+// timeouts from seconds to durations
+func sessionTimeoutsByMethod(seconds map[string]int) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(seconds))
+	for method, timeout := range seconds {
+		timeouts[method] = time.Duration(timeout) * time.Second
+	}
+	return timeouts
+}
+
+// sessionEncryptionOptions builds the options for session encryption at
+// rest, or returns none when no session encryption key is configured
+func sessionEncryptionOptions(cfg *config.Config) ([]auth.SessionOption, error) {
+	key, err := cfg.SessionEncryptionKeyBytes()
+	if err != nil || key == nil {
+		return nil, err
+	}
+	sessionCipher, err := auth.NewSessionCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	migrationDeadline, err := cfg.SessionPlaintextMigrationDeadline()
+	if err != nil {
+		return nil, err
+	}
+	return []auth.SessionOption{
+		auth.WithEncryption(sessionCipher),
+		auth.WithPlaintextMigration(migrationDeadline),
+	}, nil
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrMissingTokenID is returned when blacklisting a token without a jti claim
+var ErrMissingTokenID = errors.New("token has no ID")
+
+// ErrBlacklistUnavailable is returned when revoking a token while Redis is
+// disabled, so callers know the token stays valid until it expires
+var ErrBlacklistUnavailable = errors.New("token blacklist unavailable")
+
+// RedisTokenBlacklist stores revoked token IDs in Redis until the tokens
+// would have expired anyway. With a nil client, Redis is treated as
+// intentionally disabled: no token is blacklisted and revocations fail with
+// ErrBlacklistUnavailable.
+type RedisTokenBlacklist struct {
+	redisClient *redis.Client
+	keyPrefix   string
+}
+
+// NewRedisTokenBlacklist creates a Redis-backed token blacklist. keyPrefix is
+// the application's Redis key prefix and may be empty.
+func NewRedisTokenBlacklist(redisClient *redis.Client, keyPrefix string) *RedisTokenBlacklist {
+	return &RedisTokenBlacklist{
+		redisClient: redisClient,
+		keyPrefix:   keyPrefix + "blacklist:",
+	}
+}
+
+// BlacklistToken revokes a token by its ID until expiresAt. Tokens that have
+// already expired are ignored since they are rejected regardless.
+func (b *RedisTokenBlacklist) BlacklistToken(tokenID string, expiresAt time.Time) error {
+	if tokenID == "" {
+		return ErrMissingTokenID
+	}
+
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return nil
+	}
+
+	if b.redisClient == nil {
+		return ErrBlacklistUnavailable
+	}
+
+	if err := b.redisClient.SetEX(context.Background(), b.keyPrefix+tokenID, 1, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to blacklist token: %w", err)
+	}
+
+	return nil
+}
+
+// IsTokenBlacklisted checks whether a token ID has been revoked. A token
+// without an ID cannot have been blacklisted.
+func (b *RedisTokenBlacklist) IsTokenBlacklisted(tokenID string) (bool, error) {
+	if tokenID == "" || b.redisClient == nil {
+		return false, nil
+	}
+
+	exists, err := b.redisClient.Exists(context.Background(), b.keyPrefix+tokenID).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to check token blacklist: %w", err)
+	}
+
+	return exists > 0, nil
+}
+
+// CleanupExpiredTokens is a no-op: entries expire through their Redis TTL
+func (b *RedisTokenBlacklist) CleanupExpiredTokens() error {
+	return nil
+}
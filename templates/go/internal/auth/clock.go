@@ -0,0 +1,17 @@
+package auth
+
+import "time"
+
+// Clock provides the current time, allowing tests to control time-based
+// token checks without sleeping
+type Clock interface {
+	Now() time.Time
+}
+
+// systemClock is the Clock backed by the system time
+type systemClock struct{}
+
+// Now returns the current system time
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"math"
+	"unicode"
+)
+
+// commonPasswords are substrings that make a password trivially guessable
+var commonPasswords = []string{
+	"password", "123456", "123456789", "qwerty", "abc123",
+	"password123", "admin", "letmein", "welcome", "monkey",
+}
+
+// EstimatePasswordEntropy returns a rough estimate of a password's entropy
+// in bits. Like zxcvbn it charges predictable parts far less than random
+// characters: a common password is worth a handful of bits, each character
+// continuing a run of repeated or sequential characters is worth one, and a
+// password made of one repeated chunk is worth little more than the chunk.
+func EstimatePasswordEntropy(password string) float64 {
+	runes := []rune(password)
+	if len(runes) == 0 {
+		return 0
+	}
+
+	if period := repeatPeriod(runes); period < len(runes) {
+		return EstimatePasswordEntropy(string(runes[:period])) + math.Log2(float64(len(runes)/period))
+	}
+
+	charBits := math.Log2(float64(characterPoolSize(runes)))
+	commonBits := math.Log2(float64(len(commonPasswords)))
+	covered := commonPasswordSpans(runes)
+
+	bits := 0.0
+	for i := 0; i < len(runes); i++ {
+		if end, ok := covered[i]; ok {
+			bits += commonBits
+			i = end - 1
+			continue
+		}
+
+		if i > 0 {
+			if diff := unicode.ToLower(runes[i]) - unicode.ToLower(runes[i-1]); diff >= -1 && diff <= 1 {
+				bits++
+				continue
+			}
+		}
+		bits += charBits
+	}
+
+	return bits
+}
+
+// characterPoolSize estimates how many characters an attacker must try per
+// position, from the character classes the password uses
+func characterPoolSize(runes []rune) int {
+	var hasLower, hasUpper, hasNumber, hasSymbol, hasOther bool
+	for _, r := range runes {
+		switch {
+		case r > unicode.MaxASCII:
+			hasOther = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsNumber(r):
+			hasNumber = true
+		default:
+			hasSymbol = true
+		}
+	}
+
+	pool := 0
+	if hasLower {
+		pool += 26
+	}
+	if hasUpper {
+		pool += 26
+	}
+	if hasNumber {
+		pool += 10
+	}
+	if hasSymbol {
+		pool += 33
+	}
+	if hasOther {
+		pool += 100
+	}
+	return pool
+}
+
+// commonPasswordSpans maps the start of each common password found in runes
+// to the index just past it. Overlapping matches keep the longest.
+func commonPasswordSpans(runes []rune) map[int]int {
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+	spans := make(map[int]int)
+	for _, common := range commonPasswords {
+		word := []rune(common)
+		for start := 0; start+len(word) <= len(lower); start++ {
+			if string(lower[start:start+len(word)]) != common {
+				continue
+			}
+			if end, ok := spans[start]; !ok || start+len(word) > end {
+				spans[start] = start + len(word)
+			}
+		}
+	}
+	return spans
+}
+
+// repeatPeriod returns the length of the shortest chunk that repeated makes
+// up runes, or len(runes) when there is none
+func repeatPeriod(runes []rune) int {
+	for period := 1; period <= len(runes)/2; period++ {
+		if len(runes)%period != 0 {
+			continue
+		}
+		repeats := true
+		for i := period; i < len(runes); i++ {
+			if runes[i] != runes[i-period] {
+				repeats = false
+				break
+			}
+		}
+		if repeats {
+			return period
+		}
+	}
+	return len(runes)
+}
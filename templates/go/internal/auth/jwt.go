@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
+	"net"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -12,18 +14,153 @@ import (
 
 // JWTService handles JWT token operations
 type JWTService struct {
-	secretKey      []byte
+	signingMethod  jwt.SigningMethod
+	signingKey     interface{}
+	verifyKey      interface{}
+	publicKeyPEM   []byte
+	keySet         *KeySet
 	issuer         string
 	expirationTime time.Duration
+	notBeforeSkew  time.Duration
+	leeway         time.Duration
+	audiences      []string
+	tokenAudience  []string
+	clock          Clock
+	ipBinding      bool
+	ipv4Prefix     int
+	ipv6Prefix     int
 }
 
-// NewJWTService creates a new JWT service
-func NewJWTService(secretKey, issuer string, expirationHours int) *JWTService {
-	return &JWTService{
-		secretKey:      []byte(secretKey),
+// ErrTokenIPMismatch is returned when an IP-bound token is used from a
+// network other than the one it was issued to
+var ErrTokenIPMismatch = errors.New("token used from a different network")
+
+// Errors returned by ValidateToken, so callers can tell clients why a token
+// was rejected
+var (
+	ErrTokenMalformed        = errors.New("token is malformed")
+	ErrTokenExpired          = errors.New("token has expired")
+	ErrTokenNotYetValid      = errors.New("token not yet valid")
+	ErrTokenSignatureInvalid = errors.New("token signature is invalid")
+	ErrTokenAudienceInvalid  = errors.New("token audience not accepted")
+	ErrTokenInvalid          = errors.New("token is invalid")
+)
+
+// JWTOption configures optional JWTService behaviour
+type JWTOption func(*JWTService)
+
+// WithNotBeforeSkew tolerates tokens whose nbf is up to skew in the future,
+// to absorb small clock differences between issuer and validator. Tokens
+// further in the future are still rejected.
+func WithNotBeforeSkew(skew time.Duration) JWTOption {
+	return func(j *JWTService) {
+		j.notBeforeSkew = skew
+	}
+}
+
+// WithAudiences restricts validation to tokens whose aud claim names at
+// least one of the given audiences. Without it the aud claim is not checked.
+func WithAudiences(audiences ...string) JWTOption {
+	return func(j *JWTService) {
+		j.audiences = audiences
+	}
+}
+
+// WithTokenAudience sets the aud claim carried by generated tokens that are
+// not issued for a specific audience
+func WithTokenAudience(audience ...string) JWTOption {
+	return func(j *JWTService) {
+		j.tokenAudience = audience
+	}
+}
+
+// WithIPBinding records the client IP in tokens issued for one and makes
+// CheckIPBinding reject their use from another network. The prefix lengths
+// set how many leading bits of the address must match.
+func WithIPBinding(ipv4Prefix, ipv6Prefix int) JWTOption {
+	return func(j *JWTService) {
+		j.ipBinding = true
+		j.ipv4Prefix = ipv4Prefix
+		j.ipv6Prefix = ipv6Prefix
+	}
+}
+
+// WithClock sets the clock used to issue and validate tokens
+func WithClock(clock Clock) JWTOption {
+	return func(j *JWTService) {
+		j.clock = clock
+	}
+}
+
+// NewJWTService creates a new JWT service signing tokens with HS256
+func NewJWTService(secretKey, issuer string, expirationHours int, opts ...JWTOption) *JWTService {
+	j := &JWTService{
+		signingMethod:  jwt.SigningMethodHS256,
+		signingKey:     []byte(secretKey),
+		verifyKey:      []byte(secretKey),
 		issuer:         issuer,
 		expirationTime: time.Duration(expirationHours) * time.Hour,
+		clock:          systemClock{},
 	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// NewJWTServiceRSA creates a new JWT service signing tokens with RS256, so
+// other services can verify tokens with the public key alone
+func NewJWTServiceRSA(privateKeyPEM, publicKeyPEM []byte, issuer string, expirationHours int, opts ...JWTOption) (*JWTService, error) {
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA private key: %w", err)
+	}
+
+	publicKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse RSA public key: %w", err)
+	}
+
+	if !privateKey.PublicKey.Equal(publicKey) {
+		return nil, fmt.Errorf("RSA public key does not match private key")
+	}
+
+	j := &JWTService{
+		signingMethod:  jwt.SigningMethodRS256,
+		signingKey:     privateKey,
+		verifyKey:      publicKey,
+		publicKeyPEM:   publicKeyPEM,
+		issuer:         issuer,
+		expirationTime: time.Duration(expirationHours) * time.Hour,
+		clock:          systemClock{},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j, nil
+}
+
+// NewJWTServiceWithKeySet creates a new JWT service signing tokens with
+// RS256 using the newest key in the set. Tokens carry the key's kid so keys
+// can be rotated without invalidating tokens signed with older ones.
+func NewJWTServiceWithKeySet(keySet *KeySet, issuer string, expirationHours int, opts ...JWTOption) *JWTService {
+	j := &JWTService{
+		signingMethod:  jwt.SigningMethodRS256,
+		keySet:         keySet,
+		issuer:         issuer,
+		expirationTime: time.Duration(expirationHours) * time.Hour,
+		clock:          systemClock{},
+	}
+	for _, opt := range opts {
+		opt(j)
+	}
+	return j
+}
+
+// SetLeeway tolerates clock drift of up to leeway on both the exp and nbf
+// checks. It defaults to 0 and must be set before the service is in use.
+func (j *JWTService) SetLeeway(leeway time.Duration) {
+	j.leeway = leeway
 }
 
 // Claims represents the JWT claims structure
@@ -33,37 +170,78 @@ type Claims struct {
 	Username    string    `json:"username"`
 	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"permissions"`
+	AuthMethod  string    `json:"auth_method,omitempty"`
+	// TokenVersion is the user's token version at issue time; tokens with an
+	// older version than the user's current one are rejected
+	TokenVersion int `json:"token_version"`
+	// IP is the client address the token was issued to, set only when IP
+	// binding is enabled
+	IP string `json:"ip,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// resolveSubject makes the sub claim authoritative for the user ID. Tokens
+// without sub fall back to user_id and have sub filled in from it; tokens
+// where both are set must agree.
+func (c *Claims) resolveSubject() error {
+	if c.Subject == "" {
+		if c.UserID == uuid.Nil {
+			return fmt.Errorf("%w: missing subject", ErrTokenInvalid)
+		}
+		c.Subject = c.UserID.String()
+		return nil
+	}
+
+	subject, err := uuid.Parse(c.Subject)
+	if err != nil || subject == uuid.Nil {
+		return fmt.Errorf("%w: subject is not a user ID", ErrTokenInvalid)
+	}
+	if c.UserID != uuid.Nil && c.UserID != subject {
+		return fmt.Errorf("%w: subject does not match user_id", ErrTokenInvalid)
+	}
+	c.UserID = subject
+	return nil
+}
+
 // GenerateToken generates a JWT token for a user
 func (j *JWTService) GenerateToken(user *models.User) (string, error) {
-	now := time.Now()
+	return j.GenerateTokenWithMethod(user, "")
+}
+
+// GenerateTokenWithMethod generates a JWT token for a user recording the
+// method the user authenticated with
+func (j *JWTService) GenerateTokenWithMethod(user *models.User, authMethod string) (string, error) {
+	return j.GenerateTokenForAudience(user, authMethod, "")
+}
+
+// GenerateTokenForAudience generates a JWT token for a user scoped to the
+// given audience; an empty audience falls back to the configured token
+// audience, if any
+func (j *JWTService) GenerateTokenForAudience(user *models.User, authMethod, audience string) (string, error) {
+	return j.GenerateTokenForClient(user, authMethod, audience, "")
+}
+
+// GenerateTokenForClient generates a JWT token like GenerateTokenForAudience
+// and, when IP binding is enabled, binds it to the client's IP address
+func (j *JWTService) GenerateTokenForClient(user *models.User, authMethod, audience, ipAddress string) (string, error) {
+	now := j.clock.Now()
 	expirationTime := now.Add(j.expirationTime)
 
 	// Extract roles and permissions
 	roles := make([]string, len(user.Roles))
-	permissionSet := make(map[string]bool)
-	
 	for i, role := range user.Roles {
 		roles[i] = role.Name
-		for _, permission := range role.Permissions {
-			permissionSet[permission] = true
-		}
-	}
-
-	// Convert permission set to slice
-	permissions := make([]string, 0, len(permissionSet))
-	for permission := range permissionSet {
-		permissions = append(permissions, permission)
 	}
+	permissions := models.AggregatePermissions(user.Roles)
 
 	claims := Claims{
-		UserID:      user.ID,
-		Email:       user.Email,
-		Username:    user.Username,
-		Roles:       roles,
-		Permissions: permissions,
+		UserID:       user.ID,
+		Email:        user.Email,
+		Username:     user.Username,
+		Roles:        roles,
+		Permissions:  permissions,
+		AuthMethod:   authMethod,
+		TokenVersion: user.TokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -74,8 +252,28 @@ func (j *JWTService) GenerateToken(user *models.User) (string, error) {
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(j.secretKey)
+	if audience != "" {
+		claims.Audience = jwt.ClaimStrings{audience}
+	} else if len(j.tokenAudience) > 0 {
+		claims.Audience = jwt.ClaimStrings(j.tokenAudience)
+	}
+
+	if j.ipBinding {
+		claims.IP = ipAddress
+	}
+
+	token := jwt.NewWithClaims(j.signingMethod, claims)
+	signingKey := j.signingKey
+	if j.keySet != nil {
+		key, err := j.keySet.signer()
+		if err != nil {
+			return "", fmt.Errorf("failed to select signing key: %w", err)
+		}
+		token.Header["kid"] = key.id
+		signingKey = key.privateKey
+	}
+
+	tokenString, err := token.SignedString(signingKey)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign token: %w", err)
 	}
@@ -86,35 +284,133 @@ func (j *JWTService) GenerateToken(user *models.User) (string, error) {
 // ValidateToken validates a JWT token and returns the claims
 func (j *JWTService) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Verify the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Only accept the configured algorithm, so an RS256 public key can
+		// never be used as an HMAC secret
+		if token.Method.Alg() != j.signingMethod.Alg() {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return j.secretKey, nil
-	})
+		if j.keySet != nil {
+			kid, _ := token.Header["kid"].(string)
+			return j.keySet.verifier(kid)
+		}
+		return j.verifyKey, nil
+	}, jwt.WithLeeway(max(j.leeway, j.notBeforeSkew)), jwt.WithTimeFunc(j.clock.Now))
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse token: %w", err)
+		return nil, fmt.Errorf("%w: %v", classifyParseError(err), err)
 	}
 
 	claims, ok := token.Claims.(*Claims)
 	if !ok || !token.Valid {
-		return nil, fmt.Errorf("invalid token claims")
+		return nil, fmt.Errorf("%w: invalid token claims", ErrTokenInvalid)
+	}
+
+	now := j.clock.Now()
+
+	// Check if token is expired, allowing for the configured leeway
+	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(now.Add(-j.leeway)) {
+		return nil, ErrTokenExpired
+	}
+
+	// Check if token is not yet valid, allowing for the configured clock skew
+	if claims.NotBefore != nil && claims.NotBefore.Time.After(now.Add(max(j.leeway, j.notBeforeSkew))) {
+		return nil, ErrTokenNotYetValid
 	}
 
-	// Check if token is expired
-	if claims.ExpiresAt != nil && claims.ExpiresAt.Time.Before(time.Now()) {
-		return nil, fmt.Errorf("token has expired")
+	// Check the token is meant for this server
+	if len(j.audiences) > 0 && !hasAudience(claims.Audience, j.audiences) {
+		return nil, ErrTokenAudienceInvalid
 	}
 
-	// Check if token is not yet valid
-	if claims.NotBefore != nil && claims.NotBefore.Time.After(time.Now()) {
-		return nil, fmt.Errorf("token not yet valid")
+	if err := claims.resolveSubject(); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
 }
 
+// ValidateTokenForAudience validates a JWT token and additionally requires
+// its aud claim to include expectedAudience, so a token scoped to one API
+// cannot be replayed against another
+func (j *JWTService) ValidateTokenForAudience(tokenString, expectedAudience string) (*Claims, error) {
+	claims, err := j.ValidateToken(tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	if !hasAudience(claims.Audience, []string{expectedAudience}) {
+		return nil, fmt.Errorf("%w: audience does not include %q", ErrTokenAudienceInvalid, expectedAudience)
+	}
+
+	return claims, nil
+}
+
+// CheckIPBinding returns ErrTokenIPMismatch when IP binding is enabled and
+// ipAddress is outside the network the token was issued to. Tokens without
+// an IP, e.g. issued before binding was enabled, are rejected too since they
+// could be used from anywhere; clients refresh them for bound tokens.
+func (j *JWTService) CheckIPBinding(claims *Claims, ipAddress string) error {
+	if !j.ipBinding {
+		return nil
+	}
+	if claims.IP == "" {
+		return ErrTokenIPMismatch
+	}
+
+	issued := net.ParseIP(claims.IP)
+	current := net.ParseIP(ipAddress)
+	if issued == nil || current == nil {
+		return ErrTokenIPMismatch
+	}
+
+	bits, prefix := 128, j.ipv6Prefix
+	if issued4, current4 := issued.To4(), current.To4(); issued4 != nil || current4 != nil {
+		if issued4 == nil || current4 == nil {
+			return ErrTokenIPMismatch
+		}
+		issued, current = issued4, current4
+		bits, prefix = 32, j.ipv4Prefix
+	}
+
+	mask := net.CIDRMask(prefix, bits)
+	if !issued.Mask(mask).Equal(current.Mask(mask)) {
+		return ErrTokenIPMismatch
+	}
+	return nil
+}
+
+// classifyParseError maps a jwt parse error to the matching validation
+// error. Tokens whose signature cannot be checked, e.g. because they name
+// another algorithm or an unknown key, count as having a bad signature.
+func classifyParseError(err error) error {
+	switch {
+	case errors.Is(err, jwt.ErrTokenMalformed):
+		return ErrTokenMalformed
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		return ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		return ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		return ErrTokenAudienceInvalid
+	default:
+		return ErrTokenInvalid
+	}
+}
+
+// hasAudience reports whether any of the token's audiences is accepted
+func hasAudience(tokenAudiences jwt.ClaimStrings, accepted []string) bool {
+	for _, audience := range tokenAudiences {
+		for _, want := range accepted {
+			if audience == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // RefreshToken generates a new token using existing claims (with updated expiration)
 func (j *JWTService) RefreshToken(user *models.User) (string, error) {
 	return j.GenerateToken(user)
@@ -139,6 +435,18 @@ func (j *JWTService) ExtractTokenFromHeader(authHeader string) (string, error) {
 	return token, nil
 }
 
+// PublicKeyPEM returns the PEM encoded public key tokens can be verified
+// with, or nil when tokens are signed with a shared secret
+func (j *JWTService) PublicKeyPEM() []byte {
+	return j.publicKeyPEM
+}
+
+// KeySet returns the keys tokens are signed with, or nil when the service
+// uses a single key
+func (j *JWTService) KeySet() *KeySet {
+	return j.keySet
+}
+
 // GetTokenExpiration returns the expiration time for tokens
 func (j *JWTService) GetTokenExpiration() time.Duration {
 	return j.expirationTime
@@ -146,25 +454,15 @@ func (j *JWTService) GetTokenExpiration() time.Duration {
 
 // CreateTokenClaims creates token claims from user data
 func (j *JWTService) CreateTokenClaims(user *models.User) models.TokenClaims {
-	now := time.Now()
+	now := j.clock.Now()
 	exp := now.Add(j.expirationTime)
 
 	// Extract roles and permissions
 	roles := make([]string, len(user.Roles))
-	permissionSet := make(map[string]bool)
-	
 	for i, role := range user.Roles {
 		roles[i] = role.Name
-		for _, permission := range role.Permissions {
-			permissionSet[permission] = true
-		}
-	}
-
-	// Convert permission set to slice
-	permissions := make([]string, 0, len(permissionSet))
-	for permission := range permissionSet {
-		permissions = append(permissions, permission)
 	}
+	permissions := models.AggregatePermissions(user.Roles)
 
 	return models.TokenClaims{
 		UserID:      user.ID,
@@ -0,0 +1,138 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownKeyID is returned when a token names a kid that is not in the key set
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
+// signingKey is an RSA key pair identified by its kid
+type signingKey struct {
+	id         string
+	privateKey *rsa.PrivateKey
+}
+
+// KeySet holds the RSA keys tokens are signed and verified with. Keys are
+// kept in the order they were added: the newest key signs new tokens while
+// older keys still verify tokens issued before a rotation.
+type KeySet struct {
+	mu   sync.RWMutex
+	keys []signingKey
+}
+
+// NewKeySet creates an empty key set
+func NewKeySet() *KeySet {
+	return &KeySet{}
+}
+
+// Add parses a PEM encoded RSA private key and makes it the newest key
+func (k *KeySet) Add(kid string, privateKeyPEM []byte) error {
+	if kid == "" {
+		return fmt.Errorf("signing key id must not be empty")
+	}
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse RSA private key %s: %w", kid, err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for _, key := range k.keys {
+		if key.id == kid {
+			return fmt.Errorf("signing key %s already exists", kid)
+		}
+	}
+	k.keys = append(k.keys, signingKey{id: kid, privateKey: privateKey})
+	return nil
+}
+
+// Remove retires a key; tokens signed with it no longer validate
+func (k *KeySet) Remove(kid string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	for i, key := range k.keys {
+		if key.id == kid {
+			k.keys = append(k.keys[:i], k.keys[i+1:]...)
+			return
+		}
+	}
+}
+
+// signer returns the newest key, which signs new tokens
+func (k *KeySet) signer() (signingKey, error) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	if len(k.keys) == 0 {
+		return signingKey{}, fmt.Errorf("key set is empty")
+	}
+	return k.keys[len(k.keys)-1], nil
+}
+
+// verifier returns the public key for kid. Tokens without a kid predate key
+// rotation and are verified with the newest key.
+func (k *KeySet) verifier(kid string) (*rsa.PublicKey, error) {
+	if kid == "" {
+		key, err := k.signer()
+		if err != nil {
+			return nil, err
+		}
+		return &key.privateKey.PublicKey, nil
+	}
+
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	for _, key := range k.keys {
+		if key.id == kid {
+			return &key.privateKey.PublicKey, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// JWK is a public RSA key in JSON Web Key form
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// JWKS is a JSON Web Key Set document
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public half of every key in the set, newest first
+func (k *KeySet) JWKS() JWKS {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+
+	set := JWKS{Keys: make([]JWK, 0, len(k.keys))}
+	for i := len(k.keys) - 1; i >= 0; i-- {
+		publicKey := k.keys[i].privateKey.PublicKey
+		set.Keys = append(set.Keys, JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			Kid: k.keys[i].id,
+			N:   base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes()),
+		})
+	}
+	return set
+}
@@ -0,0 +1,9 @@
+package auth
+
+// Authentication methods recorded on sessions, tokens and audit logs
+const (
+	AuthMethodPassword  = "password"
+	AuthMethodOAuth     = "oauth"
+	AuthMethodMagicLink = "magic_link"
+	AuthMethodTOTP      = "totp"
+)
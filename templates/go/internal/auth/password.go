@@ -12,11 +12,33 @@ import (
 
 // PasswordService handles password operations
 type PasswordService struct {
-	cost int
+	cost           int
+	policy         PasswordPolicy
+	minEntropyBits float64
+}
+
+// PasswordServiceOption configures optional PasswordService behaviour
+type PasswordServiceOption func(*PasswordService)
+
+// WithPasswordPolicy validates passwords against policy instead of the
+// default policy
+func WithPasswordPolicy(policy PasswordPolicy) PasswordServiceOption {
+	return func(p *PasswordService) {
+		p.policy = policy
+	}
+}
+
+// WithMinEntropyBits validates passwords by estimated entropy instead of
+// the password policy, so long passphrases are accepted and short
+// passwords that merely tick every class are not. Zero keeps the policy.
+func WithMinEntropyBits(bits float64) PasswordServiceOption {
+	return func(p *PasswordService) {
+		p.minEntropyBits = bits
+	}
 }
 
 // NewPasswordService creates a new password service
-func NewPasswordService(cost int) *PasswordService {
+func NewPasswordService(cost int, opts ...PasswordServiceOption) *PasswordService {
 	// Ensure cost is within valid range
 	if cost < bcrypt.MinCost {
 		cost = bcrypt.MinCost
@@ -25,9 +47,14 @@ func NewPasswordService(cost int) *PasswordService {
 		cost = bcrypt.MaxCost
 	}
 	
-	return &PasswordService{
-		cost: cost,
+	p := &PasswordService{
+		cost:   cost,
+		policy: DefaultPasswordPolicy(),
 	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
 // HashPassword hashes a password using bcrypt
@@ -46,27 +73,65 @@ func (p *PasswordService) VerifyPassword(hashedPassword, password string) error
 
 // IsPasswordValid checks if a password is valid (returns true) or provides error
 func (p *PasswordService) IsPasswordValid(password string) error {
-	return ValidatePassword(password)
+	if p.minEntropyBits > 0 {
+		return ValidatePasswordEntropy(password, p.minEntropyBits)
+	}
+	return p.policy.Validate(password)
 }
 
-// ValidatePassword validates password strength
-func ValidatePassword(password string) error {
+// ValidatePasswordEntropy validates that a password's estimated entropy is
+// at least minBits
+func ValidatePasswordEntropy(password string, minBits float64) error {
+	if len(password) > 128 {
+		return fmt.Errorf("password must be no more than 128 characters long")
+	}
+
+	if EstimatePasswordEntropy(password) < minBits {
+		return fmt.Errorf("password is too easy to guess, try a longer passphrase")
+	}
+
+	return nil
+}
+
+// PasswordPolicy describes the rules a password must satisfy
+type PasswordPolicy struct {
+	MinLength           int
+	MaxLength           int // 0 means no maximum
+	RequireUppercase    bool
+	RequireLowercase    bool
+	RequireNumbers      bool
+	RequireSpecialChars bool
+	ForbidSequential    bool // Reject runs like "abc", "321" or "qwe"
+	ForbidRepeated      bool // Reject three identical characters in a row
+}
+
+// DefaultPasswordPolicy returns the policy ValidatePassword enforces
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:           8,
+		MaxLength:           128,
+		RequireUppercase:    true,
+		RequireLowercase:    true,
+		RequireNumbers:      true,
+		RequireSpecialChars: true,
+		ForbidSequential:    true,
+		ForbidRepeated:      true,
+	}
+}
+
+// Validate checks a password against the policy. Common passwords are
+// always rejected.
+func (p PasswordPolicy) Validate(password string) error {
 	var (
-		hasMinLen  = false
 		hasUpper   = false
 		hasLower   = false
 		hasNumber  = false
 		hasSpecial = false
 	)
 
-	// Check minimum length
-	if len(password) >= 8 {
-		hasMinLen = true
-	}
-
 	// Check maximum length
-	if len(password) > 128 {
-		return fmt.Errorf("password must be no more than 128 characters long")
+	if p.MaxLength > 0 && len(password) > p.MaxLength {
+		return fmt.Errorf("password must be no more than %d characters long", p.MaxLength)
 	}
 
 	// Check for character types
@@ -85,19 +150,19 @@ func ValidatePassword(password string) error {
 
 	// Build error message for missing requirements
 	var missing []string
-	if !hasMinLen {
-		missing = append(missing, "at least 8 characters")
+	if len(password) < p.MinLength {
+		missing = append(missing, fmt.Sprintf("at least %d characters", p.MinLength))
 	}
-	if !hasUpper {
+	if p.RequireUppercase && !hasUpper {
 		missing = append(missing, "at least one uppercase letter")
 	}
-	if !hasLower {
+	if p.RequireLowercase && !hasLower {
 		missing = append(missing, "at least one lowercase letter")
 	}
-	if !hasNumber {
+	if p.RequireNumbers && !hasNumber {
 		missing = append(missing, "at least one number")
 	}
-	if !hasSpecial {
+	if p.RequireSpecialChars && !hasSpecial {
 		missing = append(missing, "at least one special character")
 	}
 
@@ -106,27 +171,31 @@ func ValidatePassword(password string) error {
 	}
 
 	// Check for common patterns
-	if err := checkCommonPatterns(password); err != nil {
-		return err
+	if containsCommonPassword(password) {
+		return fmt.Errorf("password contains a common pattern and is not secure")
+	}
+
+	if p.ForbidSequential && hasSequentialChars(password) {
+		return fmt.Errorf("password contains sequential characters and is not secure")
+	}
+
+	if p.ForbidRepeated && hasRepeatedChars(password) {
+		return fmt.Errorf("password contains too many repeated characters")
 	}
 
 	return nil
 }
 
+// ValidatePassword validates password strength against the default policy
+func ValidatePassword(password string) error {
+	return DefaultPasswordPolicy().Validate(password)
+}
+
 // checkCommonPatterns checks for common weak password patterns
 func checkCommonPatterns(password string) error {
-	lower := strings.ToLower(password)
-
 	// Check for common passwords
-	commonPasswords := []string{
-		"password", "123456", "123456789", "qwerty", "abc123",
-		"password123", "admin", "letmein", "welcome", "monkey",
-	}
-
-	for _, common := range commonPasswords {
-		if strings.Contains(lower, common) {
-			return fmt.Errorf("password contains a common pattern and is not secure")
-		}
+	if containsCommonPassword(password) {
+		return fmt.Errorf("password contains a common pattern and is not secure")
 	}
 
 	// Check for sequential characters
@@ -142,6 +211,18 @@ func checkCommonPatterns(password string) error {
 	return nil
 }
 
+// containsCommonPassword reports whether the password contains a well-known
+// password, ignoring case
+func containsCommonPassword(password string) bool {
+	lower := strings.ToLower(password)
+	for _, common := range commonPasswords {
+		if strings.Contains(lower, common) {
+			return true
+		}
+	}
+	return false
+}
+
 // hasSequentialChars checks for sequential characters (abc, 123, etc.)
 func hasSequentialChars(password string) bool {
 	sequentialPatterns := []string{
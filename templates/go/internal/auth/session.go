@@ -2,28 +2,122 @@ package auth
 
 import (
 	"context"
+	"crypto/cipher"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 )
 
+// ErrTooManySessions is returned when a user already holds the maximum
+// number of concurrent sessions allowed by their roles
+var ErrTooManySessions = errors.New("too many concurrent sessions")
+
+// ErrSessionsDisabled is returned when a session is created or read while
+// Redis is disabled
+var ErrSessionsDisabled = errors.New("session store disabled")
+
+// ErrSessionTooLarge is returned when serialized session data exceeds the
+// configured size limit
+var ErrSessionTooLarge = errors.New("session data too large")
+
 // SessionService handles user sessions
 type SessionService struct {
 	redisClient    *redis.Client
 	sessionTimeout time.Duration
+	methodTimeouts map[string]time.Duration
+	maxLifetime    time.Duration
 	keyPrefix      string
+	indexPrefix    string
+	limitPolicy    SessionLimitPolicy
+	maxDataSize    int
+	aead           cipher.AEAD
+	plaintextUntil time.Time
 }
 
-// NewSessionService creates a new session service
-func NewSessionService(redisClient *redis.Client, sessionTimeout time.Duration) *SessionService {
-	return &SessionService{
+// SessionOption configures optional SessionService behaviour
+type SessionOption func(*SessionService)
+
+// WithKeyPrefix namespaces session keys under the given prefix so that
+// several applications can share one Redis instance
+func WithKeyPrefix(prefix string) SessionOption {
+	return func(s *SessionService) {
+		s.keyPrefix = prefix + "session:"
+		s.indexPrefix = prefix + "user_sessions:"
+	}
+}
+
+// WithSessionLimitPolicy caps concurrent sessions per user according to the
+// user's roles
+func WithSessionLimitPolicy(policy SessionLimitPolicy) SessionOption {
+	return func(s *SessionService) {
+		s.limitPolicy = policy
+	}
+}
+
+// WithTimeoutsByMethod gives sessions created by the given login methods a
+// different lifetime than the default session timeout
+func WithTimeoutsByMethod(timeouts map[string]time.Duration) SessionOption {
+	return func(s *SessionService) {
+		s.methodTimeouts = timeouts
+	}
+}
+
+// WithMaxLifetime caps how long a session can live in total, however often
+// it is refreshed. The session timeout remains the idle timeout. Zero means
+// sessions can be refreshed indefinitely.
+func WithMaxLifetime(lifetime time.Duration) SessionOption {
+	return func(s *SessionService) {
+		s.maxLifetime = lifetime
+	}
+}
+
+// WithMaxSessionSize rejects sessions whose serialized data exceeds maxBytes.
+// Zero means no limit.
+func WithMaxSessionSize(maxBytes int) SessionOption {
+	return func(s *SessionService) {
+		s.maxDataSize = maxBytes
+	}
+}
+
+// WithEncryption encrypts session data at rest with aead, e.g. one built by
+// NewSessionCipher. Sessions stored in plaintext are rejected unless
+// WithPlaintextMigration allows them.
+func WithEncryption(aead cipher.AEAD) SessionOption {
+	return func(s *SessionService) {
+		s.aead = aead
+	}
+}
+
+// WithPlaintextMigration keeps sessions stored in plaintext before
+// encryption was enabled readable until the given time, so that existing
+// logins survive the switch; they are encrypted when next written.
+func WithPlaintextMigration(until time.Time) SessionOption {
+	return func(s *SessionService) {
+		s.plaintextUntil = until
+	}
+}
+
+// NewSessionService creates a new session service. A nil Redis client
+// disables sessions: creating, reading or updating one fails with
+// ErrSessionsDisabled, while lookups report no sessions and deletes succeed.
+func NewSessionService(redisClient *redis.Client, sessionTimeout time.Duration, opts ...SessionOption) *SessionService {
+	s := &SessionService{
 		redisClient:    redisClient,
 		sessionTimeout: sessionTimeout,
 		keyPrefix:      "session:",
+		indexPrefix:    "user_sessions:",
+	}
+
+	for _, opt := range opts {
+		opt(s)
 	}
+
+	return s
 }
 
 // SessionData represents the data stored in a session
@@ -35,13 +129,43 @@ type SessionData struct {
 	Permissions  []string               `json:"permissions"`
 	IPAddress    string                 `json:"ip_address"`
 	UserAgent    string                 `json:"user_agent"`
+	AuthMethod   string                 `json:"auth_method,omitempty"`
 	LastActivity time.Time              `json:"last_activity"`
 	CreatedAt    time.Time              `json:"created_at"`
 	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+
+	// AbsoluteExpiry is when the session ends regardless of activity; the
+	// zero value means no absolute limit
+	AbsoluteExpiry time.Time `json:"absolute_expiry"`
+}
+
+// expired reports whether the session is past its absolute expiry
+func (d *SessionData) expired(now time.Time) bool {
+	return !d.AbsoluteExpiry.IsZero() && !now.Before(d.AbsoluteExpiry)
 }
 
 // CreateSession creates a new session and returns the session ID
 func (s *SessionService) CreateSession(ctx context.Context, sessionData *SessionData) (string, error) {
+	if s.redisClient == nil {
+		return "", ErrSessionsDisabled
+	}
+
+	// Enforce the concurrent session limit for the user's roles
+	if limit := s.limitPolicy.LimitFor(sessionData.Roles); limit > 0 {
+		count, err := s.GetActiveSessionCount(ctx, sessionData.UserID)
+		if err != nil {
+			return "", fmt.Errorf("failed to count active sessions: %w", err)
+		}
+		if count >= limit {
+			if !s.limitPolicy.EvictOldest {
+				return "", ErrTooManySessions
+			}
+			if err := s.evictOldestSessions(ctx, sessionData.UserID, count-limit+1); err != nil {
+				return "", fmt.Errorf("failed to evict oldest session: %w", err)
+			}
+		}
+	}
+
 	sessionID := uuid.New().String()
 	sessionKey := s.getSessionKey(sessionID)
 
@@ -49,16 +173,25 @@ func (s *SessionService) CreateSession(ctx context.Context, sessionData *Session
 	now := time.Now()
 	sessionData.CreatedAt = now
 	sessionData.LastActivity = now
+	sessionData.AbsoluteExpiry = time.Time{}
+	if s.maxLifetime > 0 {
+		sessionData.AbsoluteExpiry = now.Add(s.maxLifetime)
+	}
 
 	// Serialize session data
-	sessionJSON, err := json.Marshal(sessionData)
+	payload, err := s.encodeSession(sessionID, sessionData)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal session data: %w", err)
+		return "", err
 	}
 
-	// Store session in Redis with expiration
-	err = s.redisClient.SetEX(ctx, sessionKey, sessionJSON, s.sessionTimeout).Err()
-	if err != nil {
+	// Store session in Redis with the lifetime of its login method and add
+	// it to the user's session index
+	indexKey := s.getUserIndexKey(sessionData.UserID)
+	pipe := s.redisClient.TxPipeline()
+	pipe.SetEX(ctx, sessionKey, payload, s.idleTTL(sessionData, now))
+	pipe.SAdd(ctx, indexKey, sessionID)
+	pipe.Expire(ctx, indexKey, s.maxTimeout())
+	if _, err := pipe.Exec(ctx); err != nil {
 		return "", fmt.Errorf("failed to store session: %w", err)
 	}
 
@@ -67,10 +200,14 @@ func (s *SessionService) CreateSession(ctx context.Context, sessionData *Session
 
 // GetSession retrieves session data by session ID
 func (s *SessionService) GetSession(ctx context.Context, sessionID string) (*SessionData, error) {
+	if s.redisClient == nil {
+		return nil, ErrSessionsDisabled
+	}
+
 	sessionKey := s.getSessionKey(sessionID)
 
 	// Get session data from Redis
-	sessionJSON, err := s.redisClient.Get(ctx, sessionKey).Result()
+	payload, err := s.redisClient.Get(ctx, sessionKey).Bytes()
 	if err != nil {
 		if err == redis.Nil {
 			return nil, fmt.Errorf("session not found")
@@ -79,30 +216,39 @@ func (s *SessionService) GetSession(ctx context.Context, sessionID string) (*Ses
 	}
 
 	// Deserialize session data
-	var sessionData SessionData
-	err = json.Unmarshal([]byte(sessionJSON), &sessionData)
+	sessionData, err := s.decodeSession(sessionID, payload)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
+		return nil, err
 	}
 
-	return &sessionData, nil
+	// A session past its absolute lifetime is gone, however active it was
+	if sessionData.expired(time.Now()) {
+		s.removeSession(ctx, sessionID, sessionData)
+		return nil, fmt.Errorf("session not found")
+	}
+
+	return sessionData, nil
 }
 
 // UpdateSession updates existing session data
 func (s *SessionService) UpdateSession(ctx context.Context, sessionID string, sessionData *SessionData) error {
+	if s.redisClient == nil {
+		return ErrSessionsDisabled
+	}
+
 	sessionKey := s.getSessionKey(sessionID)
 
 	// Update last activity
 	sessionData.LastActivity = time.Now()
 
 	// Serialize session data
-	sessionJSON, err := json.Marshal(sessionData)
+	payload, err := s.encodeSession(sessionID, sessionData)
 	if err != nil {
-		return fmt.Errorf("failed to marshal session data: %w", err)
+		return err
 	}
 
 	// Update session in Redis, preserving TTL
-	err = s.redisClient.SetKeepTTL(ctx, sessionKey, sessionJSON).Err()
+	err = s.redisClient.Set(ctx, sessionKey, payload, redis.KeepTTL).Err()
 	if err != nil {
 		return fmt.Errorf("failed to update session: %w", err)
 	}
@@ -114,76 +260,80 @@ func (s *SessionService) UpdateSession(ctx context.Context, sessionID string, se
 func (s *SessionService) RefreshSession(ctx context.Context, sessionID string) error {
 	sessionKey := s.getSessionKey(sessionID)
 
-	// Check if session exists
-	exists, err := s.redisClient.Exists(ctx, sessionKey).Result()
+	// Load the session, which also checks it exists
+	sessionData, err := s.GetSession(ctx, sessionID)
 	if err != nil {
-		return fmt.Errorf("failed to check session existence: %w", err)
-	}
-	if exists == 0 {
-		return fmt.Errorf("session not found")
+		return err
 	}
 
-	// Extend expiration
-	err = s.redisClient.Expire(ctx, sessionKey, s.sessionTimeout).Err()
-	if err != nil {
+	// Extend expiration by the idle timeout of the session's login method,
+	// never past its absolute expiry, and keep the user's session index alive
+	// at least as long
+	pipe := s.redisClient.TxPipeline()
+	pipe.Expire(ctx, sessionKey, s.idleTTL(sessionData, time.Now()))
+	pipe.Expire(ctx, s.getUserIndexKey(sessionData.UserID), s.maxTimeout())
+	if _, err := pipe.Exec(ctx); err != nil {
 		return fmt.Errorf("failed to refresh session: %w", err)
 	}
 
 	// Update last activity
-	sessionData, err := s.GetSession(ctx, sessionID)
-	if err != nil {
-		return err
-	}
-
 	sessionData.LastActivity = time.Now()
 	return s.UpdateSession(ctx, sessionID, sessionData)
 }
 
 // DeleteSession removes a session
 func (s *SessionService) DeleteSession(ctx context.Context, sessionID string) error {
-	sessionKey := s.getSessionKey(sessionID)
+	if s.redisClient == nil {
+		return nil
+	}
 
-	err := s.redisClient.Del(ctx, sessionKey).Err()
+	// Load the owner first so the session can be dropped from their index;
+	// a session that has already expired is pruned from it on the next read
+	sessionData, err := s.GetSession(ctx, sessionID)
 	if err != nil {
+		sessionData = nil
+	}
+
+	if err := s.removeSession(ctx, sessionID, sessionData); err != nil {
 		return fmt.Errorf("failed to delete session: %w", err)
 	}
 
 	return nil
 }
 
+// removeSession deletes a session key and, when its data is known, its
+// entry in the owner's session index
+func (s *SessionService) removeSession(ctx context.Context, sessionID string, sessionData *SessionData) error {
+	pipe := s.redisClient.TxPipeline()
+	pipe.Del(ctx, s.getSessionKey(sessionID))
+	if sessionData != nil {
+		pipe.SRem(ctx, s.getUserIndexKey(sessionData.UserID), sessionID)
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
 // DeleteUserSessions removes all sessions for a specific user
 func (s *SessionService) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
-	// Scan for all session keys
-	pattern := s.keyPrefix + "*"
-	var cursor uint64
-	var deletedCount int
+	if s.redisClient == nil {
+		return nil
+	}
 
-	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return fmt.Errorf("failed to scan sessions: %w", err)
-		}
+	indexKey := s.getUserIndexKey(userID)
 
-		// Check each session for the user ID
-		for _, key := range keys {
-			sessionJSON, err := s.redisClient.Get(ctx, key).Result()
-			if err != nil {
-				continue // Skip if we can't get the session
-			}
+	sessionIDs, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list user sessions: %w", err)
+	}
 
-			var sessionData SessionData
-			if json.Unmarshal([]byte(sessionJSON), &sessionData) == nil {
-				if sessionData.UserID == userID {
-					s.redisClient.Del(ctx, key)
-					deletedCount++
-				}
-			}
-		}
+	keys := make([]string, 0, len(sessionIDs)+1)
+	for _, sessionID := range sessionIDs {
+		keys = append(keys, s.getSessionKey(sessionID))
+	}
+	keys = append(keys, indexKey)
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
-		}
+	if err := s.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete user sessions: %w", err)
 	}
 
 	return nil
@@ -191,6 +341,10 @@ func (s *SessionService) DeleteUserSessions(ctx context.Context, userID uuid.UUI
 
 // IsSessionValid checks if a session exists and is valid
 func (s *SessionService) IsSessionValid(ctx context.Context, sessionID string) (bool, error) {
+	if s.redisClient == nil {
+		return false, nil
+	}
+
 	sessionKey := s.getSessionKey(sessionID)
 
 	exists, err := s.redisClient.Exists(ctx, sessionKey).Result()
@@ -203,87 +357,147 @@ func (s *SessionService) IsSessionValid(ctx context.Context, sessionID string) (
 
 // GetActiveSessionCount returns the number of active sessions for a user
 func (s *SessionService) GetActiveSessionCount(ctx context.Context, userID uuid.UUID) (int, error) {
-	pattern := s.keyPrefix + "*"
-	var cursor uint64
-	var count int
+	if s.redisClient == nil {
+		return 0, nil
+	}
 
-	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
-		if err != nil {
-			return 0, fmt.Errorf("failed to scan sessions: %w", err)
-		}
+	indexKey := s.getUserIndexKey(userID)
 
-		// Check each session for the user ID
-		for _, key := range keys {
-			sessionJSON, err := s.redisClient.Get(ctx, key).Result()
-			if err != nil {
-				continue // Skip if we can't get the session
-			}
+	sessionIDs, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return 0, nil
+	}
 
-			var sessionData SessionData
-			if json.Unmarshal([]byte(sessionJSON), &sessionData) == nil {
-				if sessionData.UserID == userID {
-					count++
-				}
-			}
-		}
+	pipe := s.redisClient.Pipeline()
+	exists := make([]*redis.IntCmd, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		exists[i] = pipe.Exists(ctx, s.getSessionKey(sessionID))
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, fmt.Errorf("failed to check user sessions: %w", err)
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	var count int
+	var stale []string
+	for i, sessionID := range sessionIDs {
+		if exists[i].Val() > 0 {
+			count++
+		} else {
+			stale = append(stale, sessionID)
 		}
 	}
+	s.pruneUserIndex(ctx, indexKey, stale)
 
 	return count, nil
 }
 
-// GetUserSessions returns all active sessions for a user
+// GetUserSessions returns all active sessions for a user, most recently
+// active first. Sessions with the same last activity are ordered by ID.
 func (s *SessionService) GetUserSessions(ctx context.Context, userID uuid.UUID) ([]SessionInfo, error) {
-	pattern := s.keyPrefix + "*"
-	var cursor uint64
+	if s.redisClient == nil {
+		return nil, nil
+	}
+
+	indexKey := s.getUserIndexKey(userID)
+
+	sessionIDs, err := s.redisClient.SMembers(ctx, indexKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user sessions: %w", err)
+	}
+	if len(sessionIDs) == 0 {
+		return nil, nil
+	}
+
+	// Fetch every session and its TTL in a single round trip
+	pipe := s.redisClient.Pipeline()
+	gets := make([]*redis.StringCmd, len(sessionIDs))
+	ttls := make([]*redis.DurationCmd, len(sessionIDs))
+	for i, sessionID := range sessionIDs {
+		sessionKey := s.getSessionKey(sessionID)
+		gets[i] = pipe.Get(ctx, sessionKey)
+		ttls[i] = pipe.TTL(ctx, sessionKey)
+	}
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, fmt.Errorf("failed to get user sessions: %w", err)
+	}
+
 	var sessions []SessionInfo
+	var stale []string
+	for i, sessionID := range sessionIDs {
+		payload, err := gets[i].Bytes()
+		if err == redis.Nil {
+			stale = append(stale, sessionID)
+			continue
+		}
+		if err != nil {
+			continue // Skip if we can't get the session
+		}
 
-	for {
-		keys, nextCursor, err := s.redisClient.Scan(ctx, cursor, pattern, 100).Result()
+		sessionData, err := s.decodeSession(sessionID, payload)
 		if err != nil {
-			return nil, fmt.Errorf("failed to scan sessions: %w", err)
+			continue
 		}
 
-		// Check each session for the user ID
-		for _, key := range keys {
-			sessionJSON, err := s.redisClient.Get(ctx, key).Result()
-			if err != nil {
-				continue // Skip if we can't get the session
-			}
+		sessions = append(sessions, SessionInfo{
+			SessionID:    sessionID,
+			IPAddress:    sessionData.IPAddress,
+			UserAgent:    sessionData.UserAgent,
+			AuthMethod:   sessionData.AuthMethod,
+			CreatedAt:    sessionData.CreatedAt,
+			LastActivity: sessionData.LastActivity,
+			ExpiresAt:    time.Now().Add(ttls[i].Val()),
+		})
+	}
+	s.pruneUserIndex(ctx, indexKey, stale)
 
-			var sessionData SessionData
-			if json.Unmarshal([]byte(sessionJSON), &sessionData) == nil {
-				if sessionData.UserID == userID {
-					// Extract session ID from key
-					sessionID := key[len(s.keyPrefix):]
-					
-					// Get TTL
-					ttl, _ := s.redisClient.TTL(ctx, key).Result()
-					
-					sessions = append(sessions, SessionInfo{
-						SessionID:    sessionID,
-						IPAddress:    sessionData.IPAddress,
-						UserAgent:    sessionData.UserAgent,
-						CreatedAt:    sessionData.CreatedAt,
-						LastActivity: sessionData.LastActivity,
-						ExpiresAt:    time.Now().Add(ttl),
-					})
-				}
-			}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].LastActivity.Equal(sessions[j].LastActivity) {
+			return sessions[i].LastActivity.After(sessions[j].LastActivity)
 		}
+		return sessions[i].SessionID < sessions[j].SessionID
+	})
+
+	return sessions, nil
+}
+
+// evictOldestSessions deletes the user's n least recently created sessions
+func (s *SessionService) evictOldestSessions(ctx context.Context, userID uuid.UUID, n int) error {
+	sessions, err := s.GetUserSessions(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+	if n > len(sessions) {
+		n = len(sessions)
+	}
 
-		cursor = nextCursor
-		if cursor == 0 {
-			break
+	for _, session := range sessions[:n] {
+		if err := s.DeleteSession(ctx, session.SessionID); err != nil {
+			return err
 		}
 	}
 
-	return sessions, nil
+	return nil
+}
+
+// pruneUserIndex drops session IDs whose session key has already expired
+// from a user's session index. Failures are ignored because stale members
+// are harmless and will be pruned again on the next read.
+func (s *SessionService) pruneUserIndex(ctx context.Context, indexKey string, stale []string) {
+	if len(stale) == 0 {
+		return
+	}
+	members := make([]interface{}, len(stale))
+	for i, sessionID := range stale {
+		members[i] = sessionID
+	}
+	s.redisClient.SRem(ctx, indexKey, members...)
 }
 
 // CleanupExpiredSessions removes expired sessions (optional, as Redis handles TTL automatically)
@@ -298,21 +512,100 @@ type SessionInfo struct {
 	SessionID    string    `json:"session_id"`
 	IPAddress    string    `json:"ip_address"`
 	UserAgent    string    `json:"user_agent"`
+	AuthMethod   string    `json:"auth_method,omitempty"`
 	CreatedAt    time.Time `json:"created_at"`
 	LastActivity time.Time `json:"last_activity"`
 	ExpiresAt    time.Time `json:"expires_at"`
 }
 
+// encodeSession serializes session data for storage, encrypting it when
+// encryption is enabled
+func (s *SessionService) encodeSession(sessionID string, sessionData *SessionData) ([]byte, error) {
+	sessionJSON, err := json.Marshal(sessionData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal session data: %w", err)
+	}
+	if err := s.checkSize(sessionJSON); err != nil {
+		return nil, err
+	}
+	if s.aead == nil {
+		return sessionJSON, nil
+	}
+	return sealSession(s.aead, sessionID, sessionJSON)
+}
+
+// decodeSession reverses encodeSession
+func (s *SessionService) decodeSession(sessionID string, payload []byte) (*SessionData, error) {
+	if s.aead != nil && !(isPlaintextSession(payload) && time.Now().Before(s.plaintextUntil)) {
+		var err error
+		if payload, err = openSession(s.aead, sessionID, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	var sessionData SessionData
+	if err := json.Unmarshal(payload, &sessionData); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal session data: %w", err)
+	}
+	return &sessionData, nil
+}
+
+// checkSize enforces the configured limit on serialized session data
+func (s *SessionService) checkSize(sessionJSON []byte) error {
+	if s.maxDataSize > 0 && len(sessionJSON) > s.maxDataSize {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d bytes", ErrSessionTooLarge, len(sessionJSON), s.maxDataSize)
+	}
+	return nil
+}
+
 // getSessionKey generates the Redis key for a session
 func (s *SessionService) getSessionKey(sessionID string) string {
 	return s.keyPrefix + sessionID
 }
 
+// idleTTL returns the Redis TTL for a session: the idle timeout of its login
+// method, shortened so that it never outlives the session's absolute expiry
+func (s *SessionService) idleTTL(sessionData *SessionData, now time.Time) time.Duration {
+	ttl := s.TimeoutFor(sessionData.AuthMethod)
+	if !sessionData.AbsoluteExpiry.IsZero() {
+		if remaining := sessionData.AbsoluteExpiry.Sub(now); remaining < ttl {
+			ttl = remaining
+		}
+	}
+	return ttl
+}
+
+// getUserIndexKey generates the Redis key for the set of a user's session IDs
+func (s *SessionService) getUserIndexKey(userID uuid.UUID) string {
+	return s.indexPrefix + userID.String()
+}
+
+// maxTimeout returns the longest lifetime any session can have, which bounds
+// how long a user's session index needs to live
+func (s *SessionService) maxTimeout() time.Duration {
+	timeout := s.sessionTimeout
+	for _, methodTimeout := range s.methodTimeouts {
+		if methodTimeout > timeout {
+			timeout = methodTimeout
+		}
+	}
+	return timeout
+}
+
 // SetSessionTimeout updates the session timeout duration
 func (s *SessionService) SetSessionTimeout(timeout time.Duration) {
 	s.sessionTimeout = timeout
 }
 
+// TimeoutFor returns the session lifetime for a login method, falling back
+// to the default session timeout for methods without an override
+func (s *SessionService) TimeoutFor(authMethod string) time.Duration {
+	if timeout, ok := s.methodTimeouts[authMethod]; ok {
+		return timeout
+	}
+	return s.sessionTimeout
+}
+
 // GetSessionTimeout returns the current session timeout duration
 func (s *SessionService) GetSessionTimeout() time.Duration {
 	return s.sessionTimeout
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+)
+
+// ErrSessionDecrypt is returned when stored session data cannot be
+// decrypted, e.g. because it was written with another key
+var ErrSessionDecrypt = errors.New("failed to decrypt session data")
+
+// encryptedSessionVersion prefixes encrypted session payloads, so they can
+// be told apart from plaintext JSON and the format can change later
+const encryptedSessionVersion byte = 1
+
+// NewSessionCipher returns an AES-GCM cipher for WithEncryption. The key
+// must be 16, 24 or 32 bytes long, selecting AES-128, AES-192 or AES-256.
+func NewSessionCipher(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid session encryption key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealSession encrypts session JSON as version || nonce || ciphertext. The
+// session ID is authenticated too, so a payload copied to another session
+// key fails to decrypt.
+func sealSession(aead cipher.AEAD, sessionID string, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate session nonce: %w", err)
+	}
+
+	payload := make([]byte, 0, 1+len(nonce)+len(plaintext)+aead.Overhead())
+	payload = append(payload, encryptedSessionVersion)
+	payload = append(payload, nonce...)
+	return aead.Seal(payload, nonce, plaintext, []byte(sessionID)), nil
+}
+
+// openSession decrypts a payload produced by sealSession
+func openSession(aead cipher.AEAD, sessionID string, payload []byte) ([]byte, error) {
+	nonceSize := aead.NonceSize()
+	if len(payload) < 1+nonceSize || payload[0] != encryptedSessionVersion {
+		return nil, ErrSessionDecrypt
+	}
+
+	nonce, ciphertext := payload[1:1+nonceSize], payload[1+nonceSize:]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(sessionID))
+	if err != nil {
+		return nil, ErrSessionDecrypt
+	}
+	return plaintext, nil
+}
+
+// isPlaintextSession reports whether a stored payload is unencrypted JSON,
+// written before encryption was enabled. Such payloads fail openSession,
+// since JSON never starts with encryptedSessionVersion.
+func isPlaintextSession(payload []byte) bool {
+	return len(payload) > 0 && payload[0] == '{'
+}
@@ -0,0 +1,35 @@
+package auth
+
+// SessionLimitPolicy resolves how many concurrent sessions a user may hold.
+// A limit of 0 means unlimited.
+type SessionLimitPolicy struct {
+	Default int            // Limit for users whose roles have no explicit limit
+	ByRole  map[string]int // Per-role limits, e.g. {"admin": 0, "user": 3}
+
+	// EvictOldest makes a new session at the limit replace the user's oldest
+	// session instead of being rejected with ErrTooManySessions
+	EvictOldest bool
+}
+
+// LimitFor returns the effective session limit for a user with the given
+// roles. When several roles have limits, the most permissive one wins.
+func (p SessionLimitPolicy) LimitFor(roles []string) int {
+	limit, matched := 0, false
+	for _, role := range roles {
+		roleLimit, ok := p.ByRole[role]
+		if !ok {
+			continue
+		}
+		if roleLimit == 0 {
+			return 0
+		}
+		if !matched || roleLimit > limit {
+			limit, matched = roleLimit, true
+		}
+	}
+
+	if !matched {
+		return p.Default
+	}
+	return limit
+}
@@ -0,0 +1,168 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"app/internal/models"
+)
+
+// TOTP parameters. Authenticator apps assume these when the provisioning
+// URL leaves them out, so they are fixed rather than configurable.
+const (
+	totpDigits      = 6
+	totpPeriod      = 30 * time.Second
+	totpSecretBytes = 20
+)
+
+// ErrTOTPSecretInvalid is returned when a TOTP secret is not valid base32
+var ErrTOTPSecretInvalid = errors.New("invalid TOTP secret")
+
+// totpEncoding is the base32 alphabet authenticator apps expect, unpadded
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTPService generates and validates RFC 6238 time-based one-time
+// passwords for two-factor authentication
+type TOTPService struct {
+	issuer string
+	skew   int
+	clock  Clock
+}
+
+// TOTPOption configures optional TOTPService behaviour
+type TOTPOption func(*TOTPService)
+
+// WithTOTPSkew accepts codes up to steps periods before or after the
+// current one, to absorb clock drift on the user's device. It defaults to 1.
+func WithTOTPSkew(steps int) TOTPOption {
+	return func(t *TOTPService) {
+		t.skew = steps
+	}
+}
+
+// WithTOTPClock sets the clock codes are validated against
+func WithTOTPClock(clock Clock) TOTPOption {
+	return func(t *TOTPService) {
+		t.clock = clock
+	}
+}
+
+// NewTOTPService creates a TOTP service. issuer names the application in
+// authenticator apps.
+func NewTOTPService(issuer string, opts ...TOTPOption) *TOTPService {
+	t := &TOTPService{
+		issuer: issuer,
+		skew:   1,
+		clock:  systemClock{},
+	}
+	for _, opt := range opts {
+		opt(t)
+	}
+	return t
+}
+
+// GenerateSecret returns a new random base32 secret for user to enrol in
+// an authenticator app
+func (t *TOTPService) GenerateSecret(user *models.User) (string, error) {
+	secret := make([]byte, totpSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return "", fmt.Errorf("failed to generate TOTP secret for user %s: %w", user.ID, err)
+	}
+	return totpEncoding.EncodeToString(secret), nil
+}
+
+// QRCodeURL returns the otpauth:// provisioning URL for secret, to be shown
+// to user as a QR code
+func (t *TOTPService) QRCodeURL(user *models.User, secret string) string {
+	label := user.Email
+	params := url.Values{}
+	params.Set("secret", secret)
+	if t.issuer != "" {
+		label = t.issuer + ":" + label
+		params.Set("issuer", t.issuer)
+	}
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	return "otpauth://totp/" + url.PathEscape(label) + "?" + params.Encode()
+}
+
+// Validate reports whether code is valid for secret at the current time
+func (t *TOTPService) Validate(secret, code string) bool {
+	_, ok := t.Match(secret, code)
+	return ok
+}
+
+// Match reports whether code is valid for secret at the current time and
+// returns the time step it matched. Callers that must refuse a code used
+// twice record the step and only accept later ones.
+func (t *TOTPService) Match(secret, code string) (int64, bool) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil || len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := totpStep(t.clock.Now())
+	for offset := -t.skew; offset <= t.skew; offset++ {
+		step := current + int64(offset)
+		if subtle.ConstantTimeCompare([]byte(hotp(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateCode returns the code for secret at time at
+func (t *TOTPService) GenerateCode(secret string, at time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return hotp(key, totpStep(at)), nil
+}
+
+// decodeTOTPSecret decodes a base32 secret, tolerating the lower case and
+// spaces users type when entering one by hand
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	normalized := strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	key, err := totpEncoding.DecodeString(strings.TrimRight(normalized, "="))
+	if err != nil || len(key) == 0 {
+		return nil, ErrTOTPSecretInvalid
+	}
+	return key, nil
+}
+
+// totpStep returns the RFC 6238 time step containing at
+func totpStep(at time.Time) int64 {
+	return at.Unix() / int64(totpPeriod/time.Second)
+}
+
+// hotp computes the RFC 4226 one-time password for counter
+func hotp(key []byte, counter int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < totpDigits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", totpDigits, value%mod)
+}
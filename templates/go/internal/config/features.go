@@ -0,0 +1,73 @@
+package config
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// featureEnvPrefix is the environment variable prefix used for feature flags
+const featureEnvPrefix = "FEATURE_"
+
+// featureOverridesKey is the context key for request-scoped feature overrides
+type featureOverridesKey struct{}
+
+// FeatureFlags holds the enabled state of named application features
+type FeatureFlags struct {
+	flags map[string]bool
+}
+
+// NewFeatureFlags creates feature flags from a name to enabled map
+func NewFeatureFlags(flags map[string]bool) *FeatureFlags {
+	normalized := make(map[string]bool, len(flags))
+	for name, enabled := range flags {
+		normalized[normalizeFeatureName(name)] = enabled
+	}
+	return &FeatureFlags{flags: normalized}
+}
+
+// LoadFeatureFlags loads feature flags from FEATURE_* environment variables
+// (e.g. FEATURE_AUDIT_LOGGING=true enables the "audit_logging" feature)
+func LoadFeatureFlags() *FeatureFlags {
+	flags := make(map[string]bool)
+	for _, env := range os.Environ() {
+		key, value, found := strings.Cut(env, "=")
+		if !found || !strings.HasPrefix(key, featureEnvPrefix) {
+			continue
+		}
+		if enabled, err := strconv.ParseBool(value); err == nil {
+			flags[strings.TrimPrefix(key, featureEnvPrefix)] = enabled
+		}
+	}
+	return NewFeatureFlags(flags)
+}
+
+// IsEnabled reports whether a feature is enabled, honoring any request-scoped
+// override carried by ctx before falling back to the configured value
+func (f *FeatureFlags) IsEnabled(ctx context.Context, name string) bool {
+	name = normalizeFeatureName(name)
+	if overrides, ok := ctx.Value(featureOverridesKey{}).(map[string]bool); ok {
+		if enabled, exists := overrides[name]; exists {
+			return enabled
+		}
+	}
+	if f == nil {
+		return false
+	}
+	return f.flags[name]
+}
+
+// WithFeatureOverrides returns a context carrying feature overrides for a single request
+func WithFeatureOverrides(ctx context.Context, overrides map[string]bool) context.Context {
+	normalized := make(map[string]bool, len(overrides))
+	for name, enabled := range overrides {
+		normalized[normalizeFeatureName(name)] = enabled
+	}
+	return context.WithValue(ctx, featureOverridesKey{}, normalized)
+}
+
+// normalizeFeatureName lowercases and trims a feature name
+func normalizeFeatureName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
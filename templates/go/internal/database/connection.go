@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -126,8 +127,12 @@ func Migrate(db *gorm.DB) error {
 		&models.User{},
 		&models.Role{},
 		&models.UserRole{},
+		&models.RoleAssignmentHistory{},
 		&models.RefreshToken{},
 		&models.PasswordReset{},
+		&models.Invitation{},
+		&models.MFAChallenge{},
+		&models.RecoveryCode{},
 		&models.AuditLog{},
 	)
 	if err != nil {
@@ -139,9 +144,53 @@ func Migrate(db *gorm.DB) error {
 		return fmt.Errorf("failed to seed default roles: %w", err)
 	}
 
+	if err := hashLegacyTokens(db, "refresh_tokens", models.HashRefreshToken); err != nil {
+		return fmt.Errorf("failed to hash legacy refresh tokens: %w", err)
+	}
+	if err := hashLegacyTokens(db, "password_resets", models.HashPasswordResetToken); err != nil {
+		return fmt.Errorf("failed to hash legacy password reset tokens: %w", err)
+	}
+	if err := hashLegacyTokens(db, "invitations", models.HashInvitationToken); err != nil {
+		return fmt.Errorf("failed to hash legacy invitation tokens: %w", err)
+	}
+
 	return nil
 }
 
+// hashLegacyTokens replaces the tokens in table that are stored in
+// plaintext, from before only hashes were kept, with hash(token) so that they
+// keep working. Each row is marked as it is hashed, so an interrupted run can
+// be resumed.
+func hashLegacyTokens(db *gorm.DB, table string, hash func(string) string) error {
+	for {
+		var rows []struct {
+			ID    uuid.UUID
+			Token string
+		}
+		if err := db.Table(table).
+			Select("id", "token").
+			Where("token_hashed = ?", false).
+			Limit(500).
+			Find(&rows).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+
+		for _, row := range rows {
+			if err := db.Table(table).
+				Where("id = ? AND token_hashed = ?", row.ID, false).
+				Updates(map[string]interface{}{
+					"token":        hash(row.Token),
+					"token_hashed": true,
+				}).Error; err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // seedDefaultRoles creates default system roles
 func seedDefaultRoles(db *gorm.DB) error {
 	defaultRoles := []models.Role{
@@ -179,23 +228,25 @@ func seedDefaultRoles(db *gorm.DB) error {
 	return nil
 }
 
-// HealthCheck checks the health of database connections
-func HealthCheck(db *gorm.DB, redisClient *redis.Client) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
+// HealthCheck checks the health of database connections, giving each
+// check up to timeout to complete
+func HealthCheck(db *gorm.DB, redisClient *redis.Client, timeout time.Duration) error {
 	// Check PostgreSQL connection
 	sqlDB, err := db.DB()
 	if err != nil {
 		return fmt.Errorf("failed to get underlying sql.DB: %w", err)
 	}
 
-	if err := sqlDB.PingContext(ctx); err != nil {
+	dbCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := sqlDB.PingContext(dbCtx); err != nil {
 		return fmt.Errorf("PostgreSQL health check failed: %w", err)
 	}
 
 	// Check Redis connection
-	if err := redisClient.Ping(ctx).Err(); err != nil {
+	redisCtx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := redisClient.Ping(redisCtx).Err(); err != nil {
 		return fmt.Errorf("Redis health check failed: %w", err)
 	}
 
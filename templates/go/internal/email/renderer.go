@@ -0,0 +1,110 @@
+package email
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"app/internal/models"
+)
+
+// Names of the templates in the default set
+const (
+	TemplateVerification   = "verification"
+	TemplatePasswordReset  = "password_reset"
+	TemplateAccountLockout = "account_lockout"
+)
+
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// TemplateData holds the values available to email templates
+type TemplateData struct {
+	AppName   string
+	User      models.UserResponse
+	Link      string
+	ExpiresAt time.Time
+}
+
+// Message is a rendered email ready to be handed to a sender
+type Message struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// Renderer renders named email templates. Each template name maps to three
+// files: <name>.subject.tmpl, <name>.txt.tmpl and <name>.html.tmpl.
+type Renderer struct {
+	subjects *texttemplate.Template
+	texts    *texttemplate.Template
+	htmls    *htmltemplate.Template
+}
+
+// NewRenderer creates a renderer from the templates in fsys
+func NewRenderer(fsys fs.FS) (*Renderer, error) {
+	funcs := map[string]interface{}{
+		"formatTime": formatTime,
+	}
+
+	subjects, err := texttemplate.New("subjects").Funcs(funcs).ParseFS(fsys, "*.subject.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse subject templates: %w", err)
+	}
+
+	texts, err := texttemplate.New("texts").Funcs(funcs).ParseFS(fsys, "*.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse text templates: %w", err)
+	}
+
+	htmls, err := htmltemplate.New("htmls").Funcs(funcs).ParseFS(fsys, "*.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse html templates: %w", err)
+	}
+
+	return &Renderer{
+		subjects: subjects,
+		texts:    texts,
+		htmls:    htmls,
+	}, nil
+}
+
+// NewDefaultRenderer creates a renderer using the built-in template set
+func NewDefaultRenderer() (*Renderer, error) {
+	fsys, err := fs.Sub(defaultTemplates, "templates")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load default templates: %w", err)
+	}
+	return NewRenderer(fsys)
+}
+
+// Render renders the named template with the given data
+func (r *Renderer) Render(name string, data TemplateData) (*Message, error) {
+	var subject, text, html bytes.Buffer
+
+	if err := r.subjects.ExecuteTemplate(&subject, name+".subject.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render subject for %s: %w", name, err)
+	}
+	if err := r.texts.ExecuteTemplate(&text, name+".txt.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render text body for %s: %w", name, err)
+	}
+	if err := r.htmls.ExecuteTemplate(&html, name+".html.tmpl", data); err != nil {
+		return nil, fmt.Errorf("failed to render html body for %s: %w", name, err)
+	}
+
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// formatTime formats expiry times consistently across templates
+func formatTime(t time.Time) string {
+	return t.UTC().Format("January 2, 2006 15:04 MST")
+}
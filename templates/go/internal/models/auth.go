@@ -2,17 +2,25 @@ package models
 
 import (
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
-// RefreshToken represents a JWT refresh token in the database
+// RefreshToken represents a JWT refresh token in the database. Only a hash
+// of the token is stored; the raw value is set on Token when the token is
+// issued and is never persisted.
 type RefreshToken struct {
 	ID           uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Token        string    `json:"-" gorm:"uniqueIndex;not null"`
+	Token        string    `json:"-" gorm:"-"`
+	TokenHash    string    `json:"-" gorm:"column:token;uniqueIndex;not null"`
+	TokenHashed  bool      `json:"-" gorm:"not null;default:false"` // false on rows from before hashing, until database.Migrate hashes them
 	UserID       uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
 	IsRevoked    bool      `json:"is_revoked" gorm:"default:false"`
 	ExpiresAt    time.Time `json:"expires_at" gorm:"not null"`
@@ -22,6 +30,15 @@ type RefreshToken struct {
 	IPAddress    string    `json:"ip_address"`
 	UserAgent    string    `json:"user_agent"`
 	DeviceInfo   string    `json:"device_info"`
+	ClientID     string    `json:"client_id"` // access tokens refreshed with it keep the client's audience
+	AuthMethod   string    `json:"auth_method"` // access tokens refreshed with it keep the login method
+	// FamilyID links every token rotated from the same login. It is null for
+	// tokens issued before families existed.
+	FamilyID uuid.UUID `json:"family_id" gorm:"type:uuid;index"`
+	// RotatedAt is when the token was exchanged for its successor; GraceUsed
+	// records that it was presented once more within the grace period.
+	RotatedAt *time.Time `json:"rotated_at,omitempty"`
+	GraceUsed bool       `json:"-" gorm:"default:false"`
 
 	// Relationships
 	User User `json:"-" gorm:"foreignKey:UserID"`
@@ -32,6 +49,9 @@ func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
 	if rt.ID == uuid.Nil {
 		rt.ID = uuid.New()
 	}
+	if rt.FamilyID == uuid.Nil {
+		rt.FamilyID = rt.ID
+	}
 	if rt.Token == "" {
 		token, err := generateSecureToken(32)
 		if err != nil {
@@ -39,9 +59,18 @@ func (rt *RefreshToken) BeforeCreate(tx *gorm.DB) error {
 		}
 		rt.Token = token
 	}
+	rt.TokenHash = HashRefreshToken(rt.Token)
+	rt.TokenHashed = true
 	return nil
 }
 
+// HashRefreshToken returns the stored form of a raw refresh token. Tokens
+// generated by BeforeCreate are 32 random bytes, so an unsalted SHA-256 is
+// enough to make a leaked table useless without slowing down every refresh.
+func HashRefreshToken(token string) string {
+	return hashToken(token)
+}
+
 // IsExpired checks if the refresh token has expired
 func (rt *RefreshToken) IsExpired() bool {
 	return time.Now().After(rt.ExpiresAt)
@@ -58,11 +87,14 @@ func (rt *RefreshToken) Revoke() {
 	rt.UpdatedAt = time.Now()
 }
 
-// PasswordReset represents a password reset token
+// PasswordReset represents a password reset token. Only a hash of the token
+// is stored, so it is looked up by hash and never compared in plain text.
 type PasswordReset struct {
 	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
 	Email     string    `json:"email" gorm:"not null;index"`
-	Token     string    `json:"-" gorm:"uniqueIndex;not null"`
+	Token     string    `json:"-" gorm:"-"`
+	TokenHash string    `json:"-" gorm:"column:token;uniqueIndex;not null"`
+	TokenHashed bool    `json:"-" gorm:"not null;default:false"` // false on rows from before hashing, until database.Migrate hashes them
 	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
 	IsUsed    bool      `json:"is_used" gorm:"default:false"`
 	ExpiresAt time.Time `json:"expires_at" gorm:"not null"`
@@ -87,6 +119,8 @@ func (pr *PasswordReset) BeforeCreate(tx *gorm.DB) error {
 		}
 		pr.Token = token
 	}
+	pr.TokenHash = HashPasswordResetToken(pr.Token)
+	pr.TokenHashed = true
 	// Set expiration to 1 hour from now
 	if pr.ExpiresAt.IsZero() {
 		pr.ExpiresAt = time.Now().Add(time.Hour)
@@ -94,6 +128,11 @@ func (pr *PasswordReset) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// HashPasswordResetToken returns the stored form of a raw reset token
+func HashPasswordResetToken(token string) string {
+	return hashToken(token)
+}
+
 // IsExpired checks if the password reset token has expired
 func (pr *PasswordReset) IsExpired() bool {
 	return time.Now().After(pr.ExpiresAt)
@@ -165,6 +204,72 @@ func (ev *EmailVerification) MarkAsUsed() {
 	ev.UpdatedAt = now
 }
 
+// Invitation allows registering the invited email address when
+// registration is invite-only. Only a hash of the token is stored, so it is
+// looked up by hash and never compared in plain text.
+type Invitation struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Email       string     `json:"email" gorm:"not null;index"`
+	Token       string     `json:"-" gorm:"-"`
+	TokenHash   string     `json:"-" gorm:"column:token;uniqueIndex;not null"`
+	TokenHashed bool       `json:"-" gorm:"not null;default:false"` // false on rows from before hashing, until database.Migrate hashes them
+	InvitedBy   uuid.UUID  `json:"invited_by" gorm:"type:uuid;not null;index"`
+	IsUsed      bool       `json:"is_used" gorm:"default:false"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	UsedAt      *time.Time `json:"used_at"`
+	UsedBy      *uuid.UUID `json:"used_by" gorm:"type:uuid"`
+
+	// Relationships
+	Inviter User `json:"-" gorm:"foreignKey:InvitedBy"`
+}
+
+// BeforeCreate is a GORM hook that runs before creating an invitation
+func (inv *Invitation) BeforeCreate(tx *gorm.DB) error {
+	if inv.ID == uuid.Nil {
+		inv.ID = uuid.New()
+	}
+	if inv.Token == "" {
+		token, err := generateSecureToken(32)
+		if err != nil {
+			return err
+		}
+		inv.Token = token
+	}
+	inv.TokenHash = HashInvitationToken(inv.Token)
+	inv.TokenHashed = true
+	// Set expiration to 7 days from now
+	if inv.ExpiresAt.IsZero() {
+		inv.ExpiresAt = time.Now().Add(7 * 24 * time.Hour)
+	}
+	return nil
+}
+
+// HashInvitationToken returns the stored form of a raw invitation token
+func HashInvitationToken(token string) string {
+	return hashToken(token)
+}
+
+// IsExpired checks if the invitation has expired
+func (inv *Invitation) IsExpired() bool {
+	return time.Now().After(inv.ExpiresAt)
+}
+
+// IsValid checks if the invitation is valid (not used and not expired)
+func (inv *Invitation) IsValid() bool {
+	return !inv.IsUsed && !inv.IsExpired()
+}
+
+// MarkAsUsed marks the invitation as used by the user who registered with it
+func (inv *Invitation) MarkAsUsed(userID uuid.UUID) {
+	inv.IsUsed = true
+	now := time.Now()
+	inv.UsedAt = &now
+	inv.UsedBy = &userID
+	inv.UpdatedAt = now
+}
+
 // AuditLog represents an audit log entry for security events
 type AuditLog struct {
 	ID          uuid.UUID              `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
@@ -175,7 +280,7 @@ type AuditLog struct {
 	Details     map[string]interface{} `json:"details" gorm:"type:jsonb"`
 	IPAddress   string                 `json:"ip_address"`
 	UserAgent   string                 `json:"user_agent"`
-	Success     bool                   `json:"success" gorm:"default:true;index"`
+	Success     bool                   `json:"success" gorm:"default:false;index"`
 	ErrorMessage *string               `json:"error_message,omitempty"`
 	CreatedAt   time.Time              `json:"created_at"`
 
@@ -191,13 +296,166 @@ func (al *AuditLog) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
-// AuthResponse represents the response structure for authentication
+// TruncateDetails caps the JSON encoding of Details at maxBytes. Oversized
+// details keep as many entries as fit, in key order, and are marked with
+// details_truncated and their original size in details_bytes. The entry's
+// other fields are untouched. It reports whether Details was truncated; a
+// non-positive maxBytes means no limit.
+func (al *AuditLog) TruncateDetails(maxBytes int) bool {
+	if maxBytes <= 0 || al.Details == nil {
+		return false
+	}
+
+	data, err := json.Marshal(al.Details)
+	if err == nil && len(data) <= maxBytes {
+		return false
+	}
+
+	truncated := map[string]interface{}{
+		"details_truncated": true,
+		"details_bytes":     len(data),
+	}
+	keys := make([]string, 0, len(al.Details))
+	for key := range al.Details {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, reserved := truncated[key]; reserved {
+			continue
+		}
+		truncated[key] = al.Details[key]
+		if data, err := json.Marshal(truncated); err != nil || len(data) > maxBytes {
+			delete(truncated, key)
+		}
+	}
+
+	al.Details = truncated
+	return true
+}
+
+// MFAChallenge is issued when a correct password still needs a second
+// factor. Its token is exchanged for the real tokens once a TOTP or
+// recovery code is verified. Only a hash of the token is stored.
+type MFAChallenge struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	Token     string     `json:"-" gorm:"-"`
+	TokenHash string     `json:"-" gorm:"column:token;uniqueIndex;not null"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	ClientID  string     `json:"client_id"`
+	IPAddress string     `json:"ip_address"`
+	UserAgent string     `json:"user_agent"`
+	Attempts  int        `json:"attempts" gorm:"not null;default:0"`
+	ExpiresAt time.Time  `json:"expires_at" gorm:"not null"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate is a GORM hook that runs before creating an MFA challenge
+func (mc *MFAChallenge) BeforeCreate(tx *gorm.DB) error {
+	if mc.ID == uuid.Nil {
+		mc.ID = uuid.New()
+	}
+	if mc.Token == "" {
+		token, err := generateSecureToken(32)
+		if err != nil {
+			return err
+		}
+		mc.Token = token
+	}
+	mc.TokenHash = HashMFAChallengeToken(mc.Token)
+	return nil
+}
+
+// IsValid checks if the challenge can still be answered
+func (mc *MFAChallenge) IsValid() bool {
+	return mc.UsedAt == nil && time.Now().Before(mc.ExpiresAt)
+}
+
+// HashMFAChallengeToken returns the stored form of a raw challenge token
+func HashMFAChallengeToken(token string) string {
+	return hashToken(token)
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when
+// the user has lost their authenticator. Only a hash of the code is stored.
+type RecoveryCode struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	CodeHash  string     `json:"-" gorm:"not null;index"`
+	CreatedAt time.Time  `json:"created_at"`
+	UsedAt    *time.Time `json:"used_at"`
+
+	// Relationships
+	User User `json:"-" gorm:"foreignKey:UserID"`
+}
+
+// BeforeCreate is a GORM hook that runs before creating a recovery code
+func (rc *RecoveryCode) BeforeCreate(tx *gorm.DB) error {
+	if rc.ID == uuid.Nil {
+		rc.ID = uuid.New()
+	}
+	return nil
+}
+
+// GenerateRecoveryCode returns a new random recovery code of 80 bits,
+// formatted as four groups of five characters, e.g. "3f9a1-c07d2-9be04-17a3c".
+// That is enough for the unsalted HashRecoveryCode to resist brute force.
+func GenerateRecoveryCode() (string, error) {
+	code, err := generateSecureToken(10)
+	if err != nil {
+		return "", err
+	}
+	return code[:5] + "-" + code[5:10] + "-" + code[10:15] + "-" + code[15:], nil
+}
+
+// HashRecoveryCode returns the stored form of a recovery code. Case, spaces
+// and the group separator are ignored, so codes can be typed loosely.
+func HashRecoveryCode(code string) string {
+	normalized := strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(code))
+	return hashToken(normalized)
+}
+
+// AuthResponse represents the response structure for authentication. When
+// the user has two-factor authentication enabled, a correct password only
+// yields MFARequired and a ChallengeToken to complete the login with.
 type AuthResponse struct {
-	AccessToken  string       `json:"access_token"`
-	RefreshToken string       `json:"refresh_token"`
-	TokenType    string       `json:"token_type"`
-	ExpiresIn    int          `json:"expires_in"`
-	User         UserResponse `json:"user"`
+	AccessToken    string       `json:"access_token"`
+	RefreshToken   string       `json:"refresh_token"`
+	TokenType      string       `json:"token_type"`
+	ExpiresIn      int          `json:"expires_in"`
+	SessionID      string       `json:"session_id,omitempty"` // send as X-Session-ID to keep the session alive
+	User           UserResponse `json:"user"`
+	MFARequired    bool         `json:"mfa_required,omitempty"`
+	ChallengeToken string       `json:"challenge_token,omitempty"`
+}
+
+// VerifyTOTPRequest completes a login that requires a second factor. Code
+// is either a TOTP code or an unused recovery code.
+type VerifyTOTPRequest struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required"`
+}
+
+// EnableTOTPRequest confirms two-factor enrolment with a code from the
+// authenticator app
+type EnableTOTPRequest struct {
+	Code string `json:"code" validate:"required"`
+}
+
+// TOTPSetupResponse carries the secret to enrol in an authenticator app
+type TOTPSetupResponse struct {
+	Secret    string `json:"secret"`
+	QRCodeURL string `json:"qr_code_url"`
+}
+
+// RecoveryCodesResponse carries newly generated recovery codes. They are
+// shown once and cannot be retrieved again.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 // TokenClaims represents the JWT token claims
@@ -224,6 +482,13 @@ type VerifyEmailRequest struct {
 }
 
 // generateSecureToken generates a cryptographically secure random token
+// hashToken returns the hex SHA-256 of a high-entropy token, the form
+// tokens are stored in
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
 func generateSecureToken(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
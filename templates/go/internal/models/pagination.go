@@ -0,0 +1,86 @@
+package models
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// Paginated represents a single page of results from a list endpoint
+type Paginated[T any] struct {
+	Items      []T    `json:"items"`
+	Total      int64  `json:"total"`
+	Page       int    `json:"page"`
+	PageSize   int    `json:"page_size"`
+	TotalPages int    `json:"total_pages"`
+	Links      *Links `json:"links,omitempty"`
+}
+
+// Links holds navigation URLs for a page of results. Next and Prev are empty
+// on the last and first pages respectively.
+type Links struct {
+	Self  string `json:"self"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	First string `json:"first"`
+	Last  string `json:"last"`
+}
+
+// NewPaginated creates a page of results, computing the total number of pages
+func NewPaginated[T any](items []T, total int64, page, pageSize int) Paginated[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	totalPages := 0
+	if pageSize > 0 {
+		totalPages = int((total + int64(pageSize) - 1) / int64(pageSize))
+	}
+
+	return Paginated[T]{
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	}
+}
+
+// SetLinks fills in the navigation links from the request URL. Only the page
+// and page_size query parameters are rewritten, so filters and sorting carry
+// over. Links are relative to the host the request was made to.
+func (p *Paginated[T]) SetLinks(requestURL *url.URL) {
+	pageURL := func(page int) string {
+		query := requestURL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("page_size", strconv.Itoa(p.PageSize))
+		return (&url.URL{Path: requestURL.Path, RawQuery: query.Encode()}).String()
+	}
+
+	lastPage := p.TotalPages
+	if lastPage < 1 {
+		lastPage = 1
+	}
+
+	links := &Links{
+		Self:  pageURL(p.Page),
+		First: pageURL(1),
+		Last:  pageURL(lastPage),
+	}
+	if p.Page < lastPage {
+		links.Next = pageURL(p.Page + 1)
+	}
+	if p.Page > 1 {
+		// Past the end, step back onto the last real page
+		links.Prev = pageURL(min(p.Page-1, lastPage))
+	}
+
+	p.Links = links
+}
+
+// Offset returns the row offset for a 1-based page number
+func Offset(page, pageSize int) int {
+	if page < 1 {
+		page = 1
+	}
+	return (page - 1) * pageSize
+}
@@ -4,6 +4,8 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,10 +20,11 @@ func (p Permissions) Value() (driver.Value, error) {
 	return json.Marshal(p)
 }
 
-// Scan implements the sql.Scanner interface for database retrieval
+// Scan implements the sql.Scanner interface for database retrieval. A NULL
+// column or JSON null scans to an empty, non-nil list.
 func (p *Permissions) Scan(value interface{}) error {
 	if value == nil {
-		*p = nil
+		*p = Permissions{}
 		return nil
 	}
 
@@ -30,7 +33,13 @@ func (p *Permissions) Scan(value interface{}) error {
 		return fmt.Errorf("cannot scan %T into Permissions", value)
 	}
 
-	return json.Unmarshal(bytes, p)
+	if err := json.Unmarshal(bytes, p); err != nil {
+		return err
+	}
+	if *p == nil {
+		*p = Permissions{}
+	}
+	return nil
 }
 
 // Role represents a role in the system
@@ -97,8 +106,8 @@ func (r *Role) RemovePermission(permission string) {
 // UserRole represents the many-to-many relationship between users and roles
 type UserRole struct {
 	ID        uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index"`
-	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;not null;index"`
+	UserID    uuid.UUID `json:"user_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_user_roles_user_role"`
+	RoleID    uuid.UUID `json:"role_id" gorm:"type:uuid;not null;index;uniqueIndex:idx_user_roles_user_role"`
 	GrantedAt time.Time `json:"granted_at" gorm:"default:CURRENT_TIMESTAMP"`
 	GrantedBy uuid.UUID `json:"granted_by" gorm:"type:uuid"` // ID of user who granted this role
 	ExpiresAt *time.Time `json:"expires_at"`
@@ -120,6 +129,32 @@ func (ur *UserRole) IsActive() bool {
 	return !ur.IsExpired()
 }
 
+// Role assignment history actions
+const (
+	RoleAssignmentGranted = "granted"
+	RoleAssignmentRevoked = "revoked"
+)
+
+// RoleAssignmentHistory records a role being granted to or revoked from a
+// user. Entries are kept after the assignment itself is deleted.
+type RoleAssignmentHistory struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
+	UserID    uuid.UUID  `json:"user_id" gorm:"type:uuid;not null;index"`
+	RoleID    uuid.UUID  `json:"role_id" gorm:"type:uuid;not null"`
+	RoleName  string     `json:"role_name" gorm:"->"` // read from roles when listing
+	Action    string     `json:"action" gorm:"not null"`
+	ActorID   *uuid.UUID `json:"actor_id" gorm:"type:uuid"` // nil when the system made the change
+	CreatedAt time.Time  `json:"created_at" gorm:"index"`
+}
+
+// BeforeCreate is a GORM hook that runs before creating a history entry
+func (h *RoleAssignmentHistory) BeforeCreate(tx *gorm.DB) error {
+	if h.ID == uuid.Nil {
+		h.ID = uuid.New()
+	}
+	return nil
+}
+
 // RoleCreateRequest represents the request structure for creating a role
 type RoleCreateRequest struct {
 	Name        string   `json:"name" validate:"required,min=2,max=50"`
@@ -135,6 +170,53 @@ type RoleUpdateRequest struct {
 	IsActive    *bool    `json:"is_active,omitempty"`
 }
 
+// PermissionDiff lists the permissions added to and removed from a role
+type PermissionDiff struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// IsEmpty reports whether the diff contains no changes
+func (d PermissionDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0
+}
+
+// DiffPermissions compares two permission lists and returns the sorted
+// permissions added and removed going from before to after
+func DiffPermissions(before, after []string) PermissionDiff {
+	beforeSet := make(map[string]bool, len(before))
+	for _, permission := range before {
+		beforeSet[permission] = true
+	}
+	afterSet := make(map[string]bool, len(after))
+	for _, permission := range after {
+		afterSet[permission] = true
+	}
+
+	diff := PermissionDiff{Added: []string{}, Removed: []string{}}
+	for permission := range afterSet {
+		if !beforeSet[permission] {
+			diff.Added = append(diff.Added, permission)
+		}
+	}
+	for permission := range beforeSet {
+		if !afterSet[permission] {
+			diff.Removed = append(diff.Removed, permission)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	return diff
+}
+
+// RoleUpdateResponse represents the response structure for a role update,
+// including the permission changes it made
+type RoleUpdateResponse struct {
+	Role              RoleResponse   `json:"role"`
+	PermissionChanges PermissionDiff `json:"permission_changes"`
+}
+
 // RoleResponse represents the response structure for role data
 type RoleResponse struct {
 	ID          uuid.UUID `json:"id"`
@@ -145,6 +227,15 @@ type RoleResponse struct {
 	UserCount   int       `json:"user_count,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
+
+	// ExpandedPermissions lists the concrete permissions the role grants,
+	// with wildcards resolved; only set when expansion is requested
+	ExpandedPermissions []string `json:"expanded_permissions,omitempty"`
+}
+
+// Expand resolves the role's wildcard permissions into ExpandedPermissions
+func (r *RoleResponse) Expand() {
+	r.ExpandedPermissions = ExpandPermissions(r.Permissions)
 }
 
 // ToResponse converts a Role model to RoleResponse
@@ -153,7 +244,7 @@ func (r *Role) ToResponse() RoleResponse {
 		ID:          r.ID,
 		Name:        r.Name,
 		Description: r.Description,
-		Permissions: []string(r.Permissions),
+		Permissions: append([]string{}, r.Permissions...),
 		IsActive:    r.IsActive,
 		CreatedAt:   r.CreatedAt,
 		UpdatedAt:   r.UpdatedAt,
@@ -214,4 +305,69 @@ var DefaultPermissions = map[string][]string{
 		PermissionUserUpdate,
 		PermissionRoleRead,
 	},
-}
\ No newline at end of file
+}
+
+// KnownPermissions lists every concrete permission, which bounds what a
+// wildcard such as "user:*" or "*" expands to
+var KnownPermissions = []string{
+	PermissionUserRead,
+	PermissionUserCreate,
+	PermissionUserUpdate,
+	PermissionUserDelete,
+	PermissionRoleRead,
+	PermissionRoleCreate,
+	PermissionRoleUpdate,
+	PermissionRoleDelete,
+	PermissionRoleAssign,
+	PermissionRoleRevoke,
+	PermissionSystemRead,
+	PermissionSystemUpdate,
+}
+
+// AggregatePermissions returns the sorted, de-duplicated permissions granted
+// by roles. It is never nil, so a user without roles or whose roles grant
+// nothing serializes as [] rather than null.
+func AggregatePermissions(roles []Role) []string {
+	set := make(map[string]bool)
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			set[permission] = true
+		}
+	}
+
+	permissions := make([]string, 0, len(set))
+	for permission := range set {
+		permissions = append(permissions, permission)
+	}
+	sort.Strings(permissions)
+
+	return permissions
+}
+
+// ExpandPermissions returns the sorted concrete permissions granted by a
+// permission list. Wildcards resolve to the known permissions they cover;
+// concrete permissions are kept even when they are not known.
+func ExpandPermissions(permissions []string) []string {
+	expanded := make(map[string]bool)
+	for _, permission := range permissions {
+		if !strings.HasSuffix(permission, "*") {
+			expanded[permission] = true
+			continue
+		}
+
+		prefix := strings.TrimSuffix(permission, "*")
+		for _, known := range KnownPermissions {
+			if strings.HasPrefix(known, prefix) {
+				expanded[known] = true
+			}
+		}
+	}
+
+	result := make([]string, 0, len(expanded))
+	for permission := range expanded {
+		result = append(result, permission)
+	}
+	sort.Strings(result)
+
+	return result
+}
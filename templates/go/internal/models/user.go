@@ -1,6 +1,8 @@
 package models
 
 import (
+	"html"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,7 +12,7 @@ import (
 // User represents a user in the system
 type User struct {
 	ID                uuid.UUID `json:"id" gorm:"type:uuid;primary_key;default:gen_random_uuid()"`
-	Email             string    `json:"email" gorm:"uniqueIndex;not null" validate:"required,email"`
+	Email             string    `json:"email" gorm:"uniqueIndex;index:idx_users_email_lower,unique,expression:LOWER(email);not null" validate:"required,email"`
 	Username          string    `json:"username" gorm:"uniqueIndex;not null" validate:"required,min=3,max=50"`
 	PasswordHash      string    `json:"-" gorm:"not null"`
 	FirstName         string    `json:"first_name" gorm:"not null" validate:"required,min=1,max=50"`
@@ -21,6 +23,13 @@ type User struct {
 	FailedLoginCount  int       `json:"-" gorm:"default:0"`
 	LockedUntil       *time.Time `json:"-"`
 	PasswordChangedAt time.Time `json:"-" gorm:"default:CURRENT_TIMESTAMP"`
+	TokenVersion      int       `json:"-" gorm:"not null;default:0"` // bumped to invalidate issued access tokens
+	// TOTPSecret is set when two-factor enrolment starts; TOTPEnabled once
+	// the user has confirmed it with a code. TOTPLastUsedStep is the time
+	// step of the last accepted code, so a code cannot be used twice.
+	TOTPSecret        string    `json:"-"`
+	TOTPEnabled       bool      `json:"totp_enabled" gorm:"default:false"`
+	TOTPLastUsedStep  int64     `json:"-" gorm:"not null;default:0"`
 	CreatedAt         time.Time `json:"created_at"`
 	UpdatedAt         time.Time `json:"updated_at"`
 	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
@@ -70,6 +79,60 @@ func (u *User) HasPermission(permission string) bool {
 	return false
 }
 
+// Permission source types reported by ResolvePermissionSource
+const (
+	PermissionSourceDirect    = "direct"    // the role lists the permission itself
+	PermissionSourceInherited = "inherited" // a prefix pattern such as "user:*" covers it
+	PermissionSourceWildcard  = "wildcard"  // the role grants every permission with "*"
+)
+
+// PermissionSource describes which role, and which entry on it, grants a
+// permission to a user
+type PermissionSource struct {
+	Permission string `json:"permission"`
+	Granted    bool   `json:"granted"`
+	Role       string `json:"role,omitempty"`
+	Grant      string `json:"grant,omitempty"`
+	Type       string `json:"type,omitempty"`
+}
+
+// ResolvePermissionSource reports which of the user's roles grants the
+// permission. When several roles grant it, the most specific grant wins:
+// a direct grant over an inherited prefix pattern over the "*" wildcard.
+func ResolvePermissionSource(user *User, permission string) PermissionSource {
+	source := PermissionSource{Permission: permission}
+	rank := map[string]int{
+		PermissionSourceDirect:    3,
+		PermissionSourceInherited: 2,
+		PermissionSourceWildcard:  1,
+	}
+
+	for _, role := range user.Roles {
+		for _, grant := range role.Permissions {
+			var grantType string
+			switch {
+			case grant == permission:
+				grantType = PermissionSourceDirect
+			case grant == "*":
+				grantType = PermissionSourceWildcard
+			case strings.HasSuffix(grant, "*") && strings.HasPrefix(permission, strings.TrimSuffix(grant, "*")):
+				grantType = PermissionSourceInherited
+			default:
+				continue
+			}
+
+			if rank[grantType] > rank[source.Type] {
+				source.Granted = true
+				source.Role = role.Name
+				source.Grant = grant
+				source.Type = grantType
+			}
+		}
+	}
+
+	return source
+}
+
 // IsAdmin checks if the user has admin role
 func (u *User) IsAdmin() bool {
 	return u.HasRole("admin")
@@ -87,6 +150,8 @@ type UserCreateRequest struct {
 	Password  string `json:"password" validate:"required,min=8,max=128"`
 	FirstName string `json:"first_name" validate:"required,min=1,max=50"`
 	LastName  string `json:"last_name" validate:"required,min=1,max=50"`
+	// InviteToken is required when registration is invite-only
+	InviteToken string `json:"invite_token,omitempty" validate:"omitempty,max=128"`
 }
 
 // UserUpdateRequest represents the request structure for updating a user
@@ -111,7 +176,7 @@ type UserResponse struct {
 	LastLoginAt *time.Time `json:"last_login_at"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
-	Roles       []RoleResponse `json:"roles"`
+	Roles       []RoleResponse `json:"roles,omitempty"`
 }
 
 // ToResponse converts a User model to UserResponse
@@ -137,10 +202,37 @@ func (u *User) ToResponse() UserResponse {
 	}
 }
 
+// SecurityInfo reports account security details that are hidden from the
+// regular user representation
+type SecurityInfo struct {
+	UserID            uuid.UUID  `json:"user_id"`
+	PasswordChangedAt time.Time  `json:"password_changed_at"`
+	LastLoginAt       *time.Time `json:"last_login_at"`
+	FailedLoginCount  int        `json:"failed_login_count"`
+	LockedUntil       *time.Time `json:"locked_until,omitempty"`
+	TOTPEnabled       bool       `json:"totp_enabled"`
+}
+
+// ToSecurityInfo returns the user's account security details
+func (u *User) ToSecurityInfo() SecurityInfo {
+	info := SecurityInfo{
+		UserID:            u.ID,
+		PasswordChangedAt: u.PasswordChangedAt,
+		LastLoginAt:       u.LastLoginAt,
+		FailedLoginCount:  u.FailedLoginCount,
+		TOTPEnabled:       u.TOTPEnabled,
+	}
+	if u.IsLocked() {
+		info.LockedUntil = u.LockedUntil
+	}
+	return info
+}
+
 // LoginRequest represents the login request structure
 type LoginRequest struct {
 	Login    string `json:"login" validate:"required"` // Can be email or username
 	Password string `json:"password" validate:"required"`
+	ClientID string `json:"client_id,omitempty"` // selects the token audience
 }
 
 // ChangePasswordRequest represents the change password request structure
@@ -158,4 +250,61 @@ type ForgotPasswordRequest struct {
 type ResetPasswordRequest struct {
 	Token       string `json:"token" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required,min=8,max=128"`
-}
\ No newline at end of file
+}
+
+// SearchMatch describes which field of a user matched a search query, with
+// the matched text wrapped in <em> tags. The rest of the snippet is HTML-escaped.
+type SearchMatch struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
+
+// UserSearchResult represents a user search hit with optional match metadata
+type UserSearchResult struct {
+	UserResponse
+	Match *SearchMatch `json:"match,omitempty"`
+}
+
+// MatchUser returns the first field of the user, in the order email, username,
+// first_name, last_name, that contains the query case-insensitively, or nil
+// if none does
+func MatchUser(user *User, query string) *SearchMatch {
+	if query == "" {
+		return nil
+	}
+
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"email", user.Email},
+		{"username", user.Username},
+		{"first_name", user.FirstName},
+		{"last_name", user.LastName},
+	}
+
+	needle := strings.ToLower(query)
+	for _, field := range fields {
+		lower := strings.ToLower(field.value)
+		index := strings.Index(lower, needle)
+		if index < 0 {
+			continue
+		}
+
+		// Lowercasing can change byte lengths for some runes; fall back to
+		// an unhighlighted snippet rather than slicing at the wrong offsets
+		if len(lower) != len(field.value) {
+			return &SearchMatch{Field: field.name, Snippet: html.EscapeString(field.value)}
+		}
+
+		end := index + len(needle)
+		return &SearchMatch{
+			Field: field.name,
+			Snippet: html.EscapeString(field.value[:index]) +
+				"<em>" + html.EscapeString(field.value[index:end]) + "</em>" +
+				html.EscapeString(field.value[end:]),
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,506 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+)
+
+// cachingUserRepository caches user lookups by ID and email in Redis in
+// front of another UserRepository. Every write that changes a user evicts
+// that user's entry, so reads after a write see it.
+type cachingUserRepository struct {
+	next        interfaces.UserRepository
+	redisClient *redis.Client
+	ttl         time.Duration
+	keyPrefix   string
+
+	// inTx is set on repositories bound to a transaction, whose reads must
+	// see uncommitted writes and so bypass the cache
+	inTx bool
+
+	// pending, when bound to a transaction from BeginTransaction, collects
+	// evictions to repeat after it commits
+	pending *evictOnCommit
+}
+
+// CachingUserRepositoryOption configures optional caching behaviour
+type CachingUserRepositoryOption func(*cachingUserRepository)
+
+// WithKeyPrefix namespaces cache keys under the given prefix so that several
+// applications can share one Redis instance
+func WithKeyPrefix(prefix string) CachingUserRepositoryOption {
+	return func(r *cachingUserRepository) {
+		r.keyPrefix = prefix + "user_cache:"
+	}
+}
+
+// NewCachingUserRepository wraps next so that GetByID and GetByEmail are
+// served from Redis for up to ttl. Cached users include the password hash,
+// like the rows they are read from, but not their roles: those are read from
+// next on every hit, so that role grants, expiry and permission changes take
+// effect immediately. A nil Redis client or non-positive ttl disables caching
+// and returns next unchanged.
+//
+// Redis errors never fail a request: reads fall through to next, and a
+// failed eviction leaves the stale entry to expire with its TTL.
+func NewCachingUserRepository(next interfaces.UserRepository, redisClient *redis.Client, ttl time.Duration, opts ...CachingUserRepositoryOption) interfaces.UserRepository {
+	if redisClient == nil || ttl <= 0 {
+		return next
+	}
+
+	r := &cachingUserRepository{
+		next:        next,
+		redisClient: redisClient,
+		ttl:         ttl,
+		keyPrefix:   "user_cache:",
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Create creates a new user
+func (r *cachingUserRepository) Create(ctx context.Context, user *models.User) error {
+	return r.next.Create(ctx, user)
+}
+
+// GetByID retrieves a user by ID, from the cache when possible
+func (r *cachingUserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	if r.inTx {
+		return r.next.GetByID(ctx, id)
+	}
+
+	if user, ok := r.getCached(ctx, id); ok {
+		return user, nil
+	}
+
+	user, err := r.next.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	r.setCached(ctx, user)
+	return user, nil
+}
+
+// GetByEmail retrieves a user by email, from the cache when possible. The
+// cache maps the email to a user ID, so evicting the user by ID also
+// covers lookups by email.
+func (r *cachingUserRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
+	if r.inTx {
+		return r.next.GetByEmail(ctx, email)
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if id, err := r.redisClient.Get(ctx, r.emailKey(email)).Result(); err == nil {
+		if userID, err := uuid.Parse(id); err == nil {
+			// The user's email may have changed since the mapping was cached
+			if user, ok := r.getCached(ctx, userID); ok && strings.EqualFold(user.Email, email) {
+				return user, nil
+			}
+		}
+	}
+
+	user, err := r.next.GetByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	r.setCached(ctx, user)
+	return user, nil
+}
+
+// GetByUsername retrieves a user by username
+func (r *cachingUserRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	return r.next.GetByUsername(ctx, username)
+}
+
+// GetByEmailOrUsername retrieves a user by email or username
+func (r *cachingUserRepository) GetByEmailOrUsername(ctx context.Context, login string) (*models.User, error) {
+	return r.next.GetByEmailOrUsername(ctx, login)
+}
+
+// Update updates a user
+func (r *cachingUserRepository) Update(ctx context.Context, user *models.User) error {
+	defer r.evict(ctx, user.ID)
+	return r.next.Update(ctx, user)
+}
+
+// Delete permanently deletes a user
+func (r *cachingUserRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.evict(ctx, id)
+	return r.next.Delete(ctx, id)
+}
+
+// SoftDelete soft deletes a user
+func (r *cachingUserRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	defer r.evict(ctx, id)
+	return r.next.SoftDelete(ctx, id)
+}
+
+// List retrieves users with filters
+func (r *cachingUserRepository) List(ctx context.Context, filters interfaces.UserFilters) ([]*models.User, error) {
+	return r.next.List(ctx, filters)
+}
+
+// Count counts users with filters
+func (r *cachingUserRepository) Count(ctx context.Context, filters interfaces.UserFilters) (int64, error) {
+	return r.next.Count(ctx, filters)
+}
+
+// ListWithPagination retrieves users with pagination
+func (r *cachingUserRepository) ListWithPagination(ctx context.Context, filters interfaces.UserFilters, offset, limit int) ([]*models.User, int64, error) {
+	return r.next.ListWithPagination(ctx, filters, offset, limit)
+}
+
+// ListAfter retrieves up to limit users ordered by ID after afterID
+func (r *cachingUserRepository) ListAfter(ctx context.Context, filters interfaces.UserFilters, afterID uuid.UUID, limit int) ([]*models.User, error) {
+	return r.next.ListAfter(ctx, filters, afterID, limit)
+}
+
+// ListWithCursor retrieves up to limit users after cursor
+func (r *cachingUserRepository) ListWithCursor(ctx context.Context, filters interfaces.UserFilters, cursor string, limit int) ([]*models.User, string, error) {
+	return r.next.ListWithCursor(ctx, filters, cursor, limit)
+}
+
+// UpdatePassword updates a user's password
+func (r *cachingUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
+	defer r.evict(ctx, userID)
+	return r.next.UpdatePassword(ctx, userID, hashedPassword)
+}
+
+// UpdateLastLogin updates the user's last login timestamp
+func (r *cachingUserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.UpdateLastLogin(ctx, userID)
+}
+
+// IncrementFailedLoginCount increments the failed login count
+func (r *cachingUserRepository) IncrementFailedLoginCount(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.IncrementFailedLoginCount(ctx, userID)
+}
+
+// ResetFailedLoginCount resets the failed login count
+func (r *cachingUserRepository) ResetFailedLoginCount(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.ResetFailedLoginCount(ctx, userID)
+}
+
+// LockUser locks a user account for the given number of minutes
+func (r *cachingUserRepository) LockUser(ctx context.Context, userID uuid.UUID, lockDuration int) error {
+	defer r.evict(ctx, userID)
+	return r.next.LockUser(ctx, userID, lockDuration)
+}
+
+// UnlockUser unlocks a user account
+func (r *cachingUserRepository) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.UnlockUser(ctx, userID)
+}
+
+// ResetExpiredLockout clears an expired lock and the failed login count
+func (r *cachingUserRepository) ResetExpiredLockout(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.ResetExpiredLockout(ctx, userID)
+}
+
+// SetTOTPSecret stores a new TOTP secret for the user
+func (r *cachingUserRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	defer r.evict(ctx, userID)
+	return r.next.SetTOTPSecret(ctx, userID, secret)
+}
+
+// EnableTOTP turns on two-factor authentication for the user
+func (r *cachingUserRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.EnableTOTP(ctx, userID)
+}
+
+// ConsumeTOTPStep records the user's last accepted TOTP time step
+func (r *cachingUserRepository) ConsumeTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	defer r.evict(ctx, userID)
+	return r.next.ConsumeTOTPStep(ctx, userID, step)
+}
+
+// MarkEmailAsVerified marks a user's email as verified
+func (r *cachingUserRepository) MarkEmailAsVerified(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.MarkEmailAsVerified(ctx, userID)
+}
+
+// IsEmailVerified checks if a user's email is verified
+func (r *cachingUserRepository) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return r.next.IsEmailVerified(ctx, userID)
+}
+
+// ActivateUser activates a user account
+func (r *cachingUserRepository) ActivateUser(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.ActivateUser(ctx, userID)
+}
+
+// DeactivateUser deactivates a user account
+func (r *cachingUserRepository) DeactivateUser(ctx context.Context, userID uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.DeactivateUser(ctx, userID)
+}
+
+// IsUserActive checks if a user is active
+func (r *cachingUserRepository) IsUserActive(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return r.next.IsUserActive(ctx, userID)
+}
+
+// AssignRole assigns a role to a user
+func (r *cachingUserRepository) AssignRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID, expiresAt *time.Time) error {
+	defer r.evict(ctx, userID)
+	return r.next.AssignRole(ctx, userID, roleID, actorID, expiresAt)
+}
+
+// RevokeRole removes a role from a user
+func (r *cachingUserRepository) RevokeRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID) error {
+	defer r.evict(ctx, userID)
+	return r.next.RevokeRole(ctx, userID, roleID, actorID)
+}
+
+// GetRoleAssignmentHistory returns a user's role grants and revocations
+func (r *cachingUserRepository) GetRoleAssignmentHistory(ctx context.Context, userID uuid.UUID) ([]*models.RoleAssignmentHistory, error) {
+	return r.next.GetRoleAssignmentHistory(ctx, userID)
+}
+
+// GetUserRoles retrieves a user's roles
+func (r *cachingUserRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error) {
+	return r.next.GetUserRoles(ctx, userID)
+}
+
+// HasRole checks if a user has a specific role
+func (r *cachingUserRepository) HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error) {
+	return r.next.HasRole(ctx, userID, roleName)
+}
+
+// HasPermission checks if a user has a specific permission
+func (r *cachingUserRepository) HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error) {
+	return r.next.HasPermission(ctx, userID, permission)
+}
+
+// GetTokenVersion returns a user's current token version
+func (r *cachingUserRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	return r.next.GetTokenVersion(ctx, userID)
+}
+
+// SearchUsers searches users by a free-text query
+func (r *cachingUserRepository) SearchUsers(ctx context.Context, query string, filters interfaces.UserFilters) ([]*models.User, error) {
+	return r.next.SearchUsers(ctx, query, filters)
+}
+
+// GetActiveUsers retrieves all active users
+func (r *cachingUserRepository) GetActiveUsers(ctx context.Context) ([]*models.User, error) {
+	return r.next.GetActiveUsers(ctx)
+}
+
+// GetInactiveUsers retrieves all inactive users
+func (r *cachingUserRepository) GetInactiveUsers(ctx context.Context) ([]*models.User, error) {
+	return r.next.GetInactiveUsers(ctx)
+}
+
+// GetUsersCreatedAfter retrieves users created after the given time
+func (r *cachingUserRepository) GetUsersCreatedAfter(ctx context.Context, after time.Time) ([]*models.User, error) {
+	return r.next.GetUsersCreatedAfter(ctx, after)
+}
+
+// GetUsersWithRole retrieves users holding a role
+func (r *cachingUserRepository) GetUsersWithRole(ctx context.Context, roleName string) ([]*models.User, error) {
+	return r.next.GetUsersWithRole(ctx, roleName)
+}
+
+// GetUserStats retrieves user statistics
+func (r *cachingUserRepository) GetUserStats(ctx context.Context, loc *time.Location) (*interfaces.UserStats, error) {
+	return r.next.GetUserStats(ctx, loc)
+}
+
+// GetUserGrowth returns the number of users created per interval
+func (r *cachingUserRepository) GetUserGrowth(ctx context.Context, from, to time.Time, interval interfaces.GrowthInterval) ([]interfaces.GrowthBucket, error) {
+	return r.next.GetUserGrowth(ctx, from, to, interval)
+}
+
+// GetLoginStats retrieves login statistics for a user
+func (r *cachingUserRepository) GetLoginStats(ctx context.Context, userID uuid.UUID) (*interfaces.LoginStats, error) {
+	return r.next.GetLoginStats(ctx, userID)
+}
+
+// BulkUpdate updates multiple users
+func (r *cachingUserRepository) BulkUpdate(ctx context.Context, userIDs []uuid.UUID, updates map[string]interface{}) error {
+	defer r.evict(ctx, userIDs...)
+	return r.next.BulkUpdate(ctx, userIDs, updates)
+}
+
+// BulkDelete deletes multiple users
+func (r *cachingUserRepository) BulkDelete(ctx context.Context, userIDs []uuid.UUID) error {
+	defer r.evict(ctx, userIDs...)
+	return r.next.BulkDelete(ctx, userIDs)
+}
+
+// BulkAssignRole assigns a role to multiple users
+func (r *cachingUserRepository) BulkAssignRole(ctx context.Context, userIDs []uuid.UUID, roleID uuid.UUID, actorID *uuid.UUID) error {
+	defer r.evict(ctx, userIDs...)
+	return r.next.BulkAssignRole(ctx, userIDs, roleID, actorID)
+}
+
+// BeginTransaction starts a new database transaction. Users written through
+// WithTransaction on it are evicted again once it commits.
+func (r *cachingUserRepository) BeginTransaction(ctx context.Context) (*gorm.DB, error) {
+	tx, err := r.next.BeginTransaction(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tx.Statement.ConnPool = &evictOnCommit{ConnPool: tx.Statement.ConnPool}
+	return tx, nil
+}
+
+// WithTransaction returns a repository bound to tx. Its reads bypass the
+// cache and its writes evict as usual. Because a concurrent read may cache
+// the old row again before tx commits, writes on a transaction started by
+// BeginTransaction are evicted a second time after the commit.
+func (r *cachingUserRepository) WithTransaction(tx *gorm.DB) interfaces.UserRepository {
+	txRepo := &cachingUserRepository{
+		next:        r.next.WithTransaction(tx),
+		redisClient: r.redisClient,
+		ttl:         r.ttl,
+		keyPrefix:   r.keyPrefix,
+		inTx:        true,
+	}
+	if pool, ok := tx.Statement.ConnPool.(*evictOnCommit); ok {
+		txRepo.pending = pool
+	}
+	return txRepo
+}
+
+// evictOnCommit wraps a transaction's connection so that the users written
+// in it are evicted once it has committed
+type evictOnCommit struct {
+	gorm.ConnPool
+
+	mu     sync.Mutex
+	evicts []func()
+}
+
+// Commit commits the transaction, then runs the pending evictions
+func (p *evictOnCommit) Commit() error {
+	committer, ok := p.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	evicts := p.evicts
+	p.evicts = nil
+	p.mu.Unlock()
+
+	for _, evict := range evicts {
+		evict()
+	}
+	return nil
+}
+
+// Rollback rolls the transaction back; nothing was written, so nothing
+// needs evicting
+func (p *evictOnCommit) Rollback() error {
+	committer, ok := p.ConnPool.(gorm.TxCommitter)
+	if !ok {
+		return gorm.ErrInvalidTransaction
+	}
+	return committer.Rollback()
+}
+
+// add queues an eviction to run after the commit
+func (p *evictOnCommit) add(evict func()) {
+	p.mu.Lock()
+	p.evicts = append(p.evicts, evict)
+	p.mu.Unlock()
+}
+
+// getCached returns the cached user with the given ID, if any, with its
+// current roles
+func (r *cachingUserRepository) getCached(ctx context.Context, id uuid.UUID) (*models.User, bool) {
+	data, err := r.redisClient.Get(ctx, r.idKey(id)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+
+	var user models.User
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&user); err != nil {
+		return nil, false
+	}
+
+	roles, err := r.next.GetUserRoles(ctx, id)
+	if err != nil {
+		return nil, false
+	}
+	user.Roles = make([]models.Role, len(roles))
+	for i, role := range roles {
+		user.Roles[i] = *role
+	}
+	return &user, true
+}
+
+// setCached stores user, without its roles, under its ID and maps its email
+// to the ID. Gob is used rather than JSON because the JSON form omits fields
+// such as the password hash.
+func (r *cachingUserRepository) setCached(ctx context.Context, user *models.User) {
+	cached := *user
+	cached.Roles = nil
+
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&cached); err != nil {
+		return
+	}
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Set(ctx, r.idKey(user.ID), buf.Bytes(), r.ttl)
+	pipe.Set(ctx, r.emailKey(strings.ToLower(user.Email)), user.ID.String(), r.ttl)
+	pipe.Exec(ctx)
+}
+
+// evict removes the cached entries of the given users, and again after the
+// commit when bound to a transaction. Email mappings are left to expire,
+// since they are checked against the user they resolve to.
+func (r *cachingUserRepository) evict(ctx context.Context, userIDs ...uuid.UUID) {
+	if len(userIDs) == 0 {
+		return
+	}
+
+	keys := make([]string, len(userIDs))
+	for i, id := range userIDs {
+		keys[i] = r.idKey(id)
+	}
+	r.redisClient.Del(ctx, keys...)
+
+	if r.pending != nil {
+		r.pending.add(func() {
+			r.redisClient.Del(context.Background(), keys...)
+		})
+	}
+}
+
+// idKey returns the cache key of the user with the given ID
+func (r *cachingUserRepository) idKey(id uuid.UUID) string {
+	return r.keyPrefix + "id:" + id.String()
+}
+
+// emailKey returns the cache key mapping an email to a user ID
+func (r *cachingUserRepository) emailKey(email string) string {
+	return r.keyPrefix + "email:" + email
+}
@@ -0,0 +1,12 @@
+package interfaces
+
+import (
+	"context"
+
+	"app/internal/models"
+)
+
+// AuditLogRepository defines the interface for audit log data operations
+type AuditLogRepository interface {
+	Create(ctx context.Context, auditLog *models.AuditLog) error
+}
@@ -0,0 +1,30 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"app/internal/models"
+)
+
+// RoleRepository defines the interface for role data operations
+type RoleRepository interface {
+	// Basic CRUD operations
+	Create(ctx context.Context, role *models.Role) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error)
+	GetByName(ctx context.Context, name string) (*models.Role, error)
+	Update(ctx context.Context, role *models.Role) error
+
+	// List operations
+	List(ctx context.Context, filters RoleFilters, offset, limit int) ([]*models.Role, int64, error)
+	CountUsers(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID]int64, error)
+}
+
+// RoleFilters represents filters for role queries
+type RoleFilters struct {
+	IsActive  *bool
+	Name      string // Case-insensitive partial match on the role name
+	SortBy    string // "name", "created_at", "updated_at"
+	SortOrder string // "asc", "desc"
+}
@@ -2,6 +2,7 @@ package interfaces
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -10,6 +11,13 @@ import (
 	"app/internal/models"
 )
 
+// ErrTooManyRoles is returned when a role assignment would give a user more
+// roles than the configured maximum
+var ErrTooManyRoles = errors.New("too many roles")
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
 // UserRepository defines the interface for user data operations
 type UserRepository interface {
 	// Basic CRUD operations
@@ -26,6 +34,8 @@ type UserRepository interface {
 	List(ctx context.Context, filters UserFilters) ([]*models.User, error)
 	Count(ctx context.Context, filters UserFilters) (int64, error)
 	ListWithPagination(ctx context.Context, filters UserFilters, offset, limit int) ([]*models.User, int64, error)
+	ListAfter(ctx context.Context, filters UserFilters, afterID uuid.UUID, limit int) ([]*models.User, error)
+	ListWithCursor(ctx context.Context, filters UserFilters, cursor string, limit int) (users []*models.User, nextCursor string, err error)
 
 	// Authentication related
 	UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error
@@ -34,6 +44,12 @@ type UserRepository interface {
 	ResetFailedLoginCount(ctx context.Context, userID uuid.UUID) error
 	LockUser(ctx context.Context, userID uuid.UUID, lockDuration int) error
 	UnlockUser(ctx context.Context, userID uuid.UUID) error
+	ResetExpiredLockout(ctx context.Context, userID uuid.UUID) error
+
+	// Two-factor authentication
+	SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error
+	EnableTOTP(ctx context.Context, userID uuid.UUID) error
+	ConsumeTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
 
 	// Email verification
 	MarkEmailAsVerified(ctx context.Context, userID uuid.UUID) error
@@ -45,11 +61,13 @@ type UserRepository interface {
 	IsUserActive(ctx context.Context, userID uuid.UUID) (bool, error)
 
 	// Role management
-	AssignRole(ctx context.Context, userID, roleID uuid.UUID) error
-	RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error
+	AssignRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID, expiresAt *time.Time) error
+	RevokeRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID) error
+	GetRoleAssignmentHistory(ctx context.Context, userID uuid.UUID) ([]*models.RoleAssignmentHistory, error)
 	GetUserRoles(ctx context.Context, userID uuid.UUID) ([]*models.Role, error)
 	HasRole(ctx context.Context, userID uuid.UUID, roleName string) (bool, error)
 	HasPermission(ctx context.Context, userID uuid.UUID, permission string) (bool, error)
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error)
 
 	// Search and filtering
 	SearchUsers(ctx context.Context, query string, filters UserFilters) ([]*models.User, error)
@@ -59,13 +77,14 @@ type UserRepository interface {
 	GetUsersWithRole(ctx context.Context, roleName string) ([]*models.User, error)
 
 	// Statistics
-	GetUserStats(ctx context.Context) (*UserStats, error)
+	GetUserStats(ctx context.Context, loc *time.Location) (*UserStats, error)
+	GetUserGrowth(ctx context.Context, from, to time.Time, interval GrowthInterval) ([]GrowthBucket, error)
 	GetLoginStats(ctx context.Context, userID uuid.UUID) (*LoginStats, error)
 
 	// Bulk operations
 	BulkUpdate(ctx context.Context, userIDs []uuid.UUID, updates map[string]interface{}) error
 	BulkDelete(ctx context.Context, userIDs []uuid.UUID) error
-	BulkAssignRole(ctx context.Context, userIDs []uuid.UUID, roleID uuid.UUID) error
+	BulkAssignRole(ctx context.Context, userIDs []uuid.UUID, roleID uuid.UUID, actorID *uuid.UUID) error
 
 	// Database operations
 	BeginTransaction(ctx context.Context) (*gorm.DB, error)
@@ -98,6 +117,47 @@ type UserStats struct {
 	NewUsersToday   int64 `json:"new_users_today"`
 	NewUsersThisWeek int64 `json:"new_users_this_week"`
 	NewUsersThisMonth int64 `json:"new_users_this_month"`
+	UsersByRole     map[string]int64 `json:"users_by_role"` // every role, including those with no users
+	Timezone        string `json:"timezone"` // zone the today/week/month windows are computed in
+}
+
+// StatsWindows holds the starts of the "today", "this week" and "this month"
+// windows reported by GetUserStats. Weeks start on Sunday.
+type StatsWindows struct {
+	Today time.Time
+	Week  time.Time
+	Month time.Time
+}
+
+// NewStatsWindows returns the windows containing now, with day boundaries at
+// midnight in loc. A nil loc means UTC.
+func NewStatsWindows(now time.Time, loc *time.Location) StatsWindows {
+	if loc == nil {
+		loc = time.UTC
+	}
+	now = now.In(loc)
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	return StatsWindows{
+		Today: today,
+		Week:  time.Date(now.Year(), now.Month(), now.Day()-int(now.Weekday()), 0, 0, 0, 0, loc),
+		Month: time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc),
+	}
+}
+
+// GrowthInterval is the bucket size for user growth metrics
+type GrowthInterval string
+
+const (
+	GrowthIntervalDay   GrowthInterval = "day"
+	GrowthIntervalWeek  GrowthInterval = "week"
+	GrowthIntervalMonth GrowthInterval = "month"
+)
+
+// GrowthBucket holds the number of users created in the bucket starting at Start
+type GrowthBucket struct {
+	Start time.Time `json:"start"`
+	Count int64     `json:"count"`
 }
 
 // LoginStats represents login statistics for a user
@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+)
+
+// auditLogRepository implements the AuditLogRepository interface using PostgreSQL
+type auditLogRepository struct {
+	db             *gorm.DB
+	maxDetailBytes int
+}
+
+// AuditLogRepositoryOption configures optional audit log repository behaviour
+type AuditLogRepositoryOption func(*auditLogRepository)
+
+// WithMaxDetailBytes truncates the details of entries whose JSON encoding
+// exceeds maxBytes before they are stored. 0 means unlimited.
+func WithMaxDetailBytes(maxBytes int) AuditLogRepositoryOption {
+	return func(r *auditLogRepository) {
+		r.maxDetailBytes = maxBytes
+	}
+}
+
+// NewAuditLogRepository creates a new audit log repository
+func NewAuditLogRepository(db *gorm.DB, opts ...AuditLogRepositoryOption) interfaces.AuditLogRepository {
+	r := &auditLogRepository{db: db}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Create creates a new audit log entry, truncating oversized details
+func (r *auditLogRepository) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	auditLog.TruncateDetails(r.maxDetailBytes)
+	if err := r.db.WithContext(ctx).Create(auditLog).Error; err != nil {
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+)
+
+// roleSortColumns lists the columns roles may be sorted by
+var roleSortColumns = map[string]bool{
+	"name":       true,
+	"created_at": true,
+	"updated_at": true,
+}
+
+// roleRepository implements the RoleRepository interface using PostgreSQL
+type roleRepository struct {
+	db *gorm.DB
+}
+
+// NewRoleRepository creates a new role repository
+func NewRoleRepository(db *gorm.DB) interfaces.RoleRepository {
+	return &roleRepository{db: db}
+}
+
+// Create creates a new role
+func (r *roleRepository) Create(ctx context.Context, role *models.Role) error {
+	if err := r.db.WithContext(ctx).Create(role).Error; err != nil {
+		return fmt.Errorf("failed to create role: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a role by ID
+func (r *roleRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Where("id = ?", id).First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role: %w", err)
+	}
+
+	return &role, nil
+}
+
+// GetByName retrieves a role by name
+func (r *roleRepository) GetByName(ctx context.Context, name string) (*models.Role, error) {
+	var role models.Role
+	err := r.db.WithContext(ctx).Where("name = ?", name).First(&role).Error
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, fmt.Errorf("role not found")
+		}
+		return nil, fmt.Errorf("failed to get role by name: %w", err)
+	}
+
+	return &role, nil
+}
+
+// Update updates a role
+func (r *roleRepository) Update(ctx context.Context, role *models.Role) error {
+	if err := r.db.WithContext(ctx).Save(role).Error; err != nil {
+		return fmt.Errorf("failed to update role: %w", err)
+	}
+	return nil
+}
+
+// List retrieves roles matching the filters with pagination
+func (r *roleRepository) List(ctx context.Context, filters interfaces.RoleFilters, offset, limit int) ([]*models.Role, int64, error) {
+	var roles []*models.Role
+	var total int64
+
+	query := r.buildQuery(filters)
+
+	// Get total count
+	if err := query.WithContext(ctx).Model(&models.Role{}).Count(&total).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to count roles: %w", err)
+	}
+
+	// Get paginated results
+	if err := query.WithContext(ctx).
+		Order(r.buildOrder(filters)).
+		Offset(offset).
+		Limit(limit).
+		Find(&roles).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, total, nil
+}
+
+// CountUsers returns the number of users assigned to each of the given roles
+func (r *roleRepository) CountUsers(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	counts := make(map[uuid.UUID]int64, len(roleIDs))
+	if len(roleIDs) == 0 {
+		return counts, nil
+	}
+
+	var rows []struct {
+		RoleID uuid.UUID
+		Count  int64
+	}
+	if err := r.db.WithContext(ctx).
+		Table("user_roles").
+		Select("role_id, COUNT(*) AS count").
+		Where("role_id IN ?", roleIDs).
+		Group("role_id").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count role users: %w", err)
+	}
+
+	for _, row := range rows {
+		counts[row.RoleID] = row.Count
+	}
+
+	return counts, nil
+}
+
+// buildQuery builds a GORM query with filters
+func (r *roleRepository) buildQuery(filters interfaces.RoleFilters) *gorm.DB {
+	query := r.db.Model(&models.Role{})
+
+	if filters.IsActive != nil {
+		query = query.Where("is_active = ?", *filters.IsActive)
+	}
+
+	if filters.Name != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(filters.Name)+"%")
+	}
+
+	return query
+}
+
+// buildOrder returns a safe ORDER BY clause for the filters
+func (r *roleRepository) buildOrder(filters interfaces.RoleFilters) string {
+	sortBy := filters.SortBy
+	if !roleSortColumns[sortBy] {
+		sortBy = "name"
+	}
+
+	sortOrder := strings.ToLower(filters.SortOrder)
+	if sortOrder != "desc" {
+		sortOrder = "asc"
+	}
+
+	return fmt.Sprintf("%s %s", sortBy, sortOrder)
+}
@@ -2,12 +2,15 @@ package postgres
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"app/internal/models"
 	"app/internal/repository/interfaces"
@@ -15,17 +18,57 @@ import (
 
 // userRepository implements the UserRepository interface using PostgreSQL
 type userRepository struct {
-	db *gorm.DB
+	db              *gorm.DB
+	maxRolesPerUser int
+	bulkBatchSize   int
+}
+
+// defaultBulkBatchSize is how many IDs bulk operations put in one statement
+// unless configured otherwise
+const defaultBulkBatchSize = 1000
+
+// UserRepositoryOption configures optional user repository behaviour
+type UserRepositoryOption func(*userRepository)
+
+// WithMaxRolesPerUser caps how many roles a user may hold; assignments
+// beyond it fail with interfaces.ErrTooManyRoles. 0 means unlimited.
+func WithMaxRolesPerUser(limit int) UserRepositoryOption {
+	return func(r *userRepository) {
+		r.maxRolesPerUser = limit
+	}
+}
+
+// normalizeEmail returns the form emails are stored and compared in. Emails
+// are unique regardless of case.
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// WithBulkBatchSize caps how many user IDs bulk operations put in a single
+// statement; larger sets are processed in batches within one transaction.
+// Non-positive values keep the default of 1000.
+func WithBulkBatchSize(size int) UserRepositoryOption {
+	return func(r *userRepository) {
+		if size > 0 {
+			r.bulkBatchSize = size
+		}
+	}
 }
 
 // NewUserRepository creates a new user repository
-func NewUserRepository(db *gorm.DB) interfaces.UserRepository {
-	return &userRepository{db: db}
+func NewUserRepository(db *gorm.DB, opts ...UserRepositoryOption) interfaces.UserRepository {
+	r := &userRepository{db: db, bulkBatchSize: defaultBulkBatchSize}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
-// Create creates a new user
+// Create creates a new user. Database-assigned columns such as the
+// timestamps are read back so the caller sees the persisted values.
 func (r *userRepository) Create(ctx context.Context, user *models.User) error {
-	if err := r.db.WithContext(ctx).Create(user).Error; err != nil {
+	user.Email = normalizeEmail(user.Email)
+	if err := r.db.WithContext(ctx).Clauses(clause.Returning{}).Create(user).Error; err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
 	return nil
@@ -41,11 +84,15 @@ func (r *userRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -54,16 +101,20 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	var user models.User
 	err := r.db.WithContext(ctx).
 		Preload("Roles").
-		Where("email = ?", email).
+		Where("LOWER(email) = ?", normalizeEmail(email)).
 		First(&user).Error
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -77,11 +128,15 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -90,21 +145,26 @@ func (r *userRepository) GetByEmailOrUsername(ctx context.Context, login string)
 	var user models.User
 	err := r.db.WithContext(ctx).
 		Preload("Roles").
-		Where("email = ? OR username = ?", login, login).
+		Where("LOWER(email) = ? OR username = ?", normalizeEmail(login), login).
 		First(&user).Error
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
-			return nil, fmt.Errorf("user not found")
+			return nil, fmt.Errorf("user not found: %w", err)
 		}
 		return nil, fmt.Errorf("failed to get user by login: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, &user); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
 // Update updates a user
 func (r *userRepository) Update(ctx context.Context, user *models.User) error {
+	user.Email = normalizeEmail(user.Email)
 	if err := r.db.WithContext(ctx).Save(user).Error; err != nil {
 		return fmt.Errorf("failed to update user: %w", err)
 	}
@@ -136,6 +196,10 @@ func (r *userRepository) List(ctx context.Context, filters interfaces.UserFilter
 		return nil, fmt.Errorf("failed to list users: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, users...); err != nil {
+		return nil, err
+	}
+
 	return users, nil
 }
 
@@ -172,9 +236,113 @@ func (r *userRepository) ListWithPagination(ctx context.Context, filters interfa
 		return nil, 0, fmt.Errorf("failed to list users with pagination: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, users...); err != nil {
+		return nil, 0, err
+	}
+
 	return users, total, nil
 }
 
+// ListAfter retrieves up to limit users ordered by ID with an ID greater than
+// afterID, allowing callers to walk large result sets in bounded chunks
+func (r *userRepository) ListAfter(ctx context.Context, filters interfaces.UserFilters, afterID uuid.UUID, limit int) ([]*models.User, error) {
+	var users []*models.User
+
+	query := r.buildFilterQuery(filters)
+	if afterID != uuid.Nil {
+		query = query.Where("users.id > ?", afterID)
+	}
+
+	if err := query.WithContext(ctx).
+		Preload("Roles").
+		Order("users.id asc").
+		Limit(limit).
+		Find(&users).Error; err != nil {
+		return nil, fmt.Errorf("failed to list users after cursor: %w", err)
+	}
+
+	if err := r.dropExpiredRoles(ctx, users...); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// ListWithCursor retrieves up to limit users ordered by creation time and ID,
+// starting after cursor. An empty cursor starts at the first page; the
+// returned cursor is empty once there are no more users. Rows are ordered
+// ascending unless filters.SortOrder is "desc"; filters.SortBy is ignored,
+// since the keyset must match the order.
+func (r *userRepository) ListWithCursor(ctx context.Context, filters interfaces.UserFilters, cursor string, limit int) ([]*models.User, string, error) {
+	if limit <= 0 {
+		return nil, "", fmt.Errorf("limit must be positive")
+	}
+
+	descending := strings.EqualFold(filters.SortOrder, "desc")
+	direction, comparison := "asc", ">"
+	if descending {
+		direction, comparison = "desc", "<"
+	}
+
+	query := r.buildFilterQuery(filters)
+	if cursor != "" {
+		key, err := decodeUserCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		query = query.Where("(users.created_at, users.id) "+comparison+" (?, ?)", key.CreatedAt, key.ID)
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	var users []*models.User
+	if err := query.WithContext(ctx).
+		Preload("Roles").
+		Order("users.created_at " + direction).
+		Order("users.id " + direction).
+		Limit(limit + 1).
+		Find(&users).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to list users with cursor: %w", err)
+	}
+
+	var nextCursor string
+	if len(users) > limit {
+		users = users[:limit]
+		last := users[len(users)-1]
+		nextCursor = encodeUserCursor(userCursor{CreatedAt: last.CreatedAt, ID: last.ID})
+	}
+
+	if err := r.dropExpiredRoles(ctx, users...); err != nil {
+		return nil, "", err
+	}
+
+	return users, nextCursor, nil
+}
+
+// userCursor is the keyset position encoded in a ListWithCursor cursor
+type userCursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        uuid.UUID `json:"i"`
+}
+
+// encodeUserCursor encodes key as an opaque, URL-safe cursor
+func encodeUserCursor(key userCursor) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserCursor decodes a cursor produced by encodeUserCursor
+func decodeUserCursor(cursor string) (userCursor, error) {
+	var key userCursor
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, interfaces.ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &key); err != nil || key.ID == uuid.Nil || key.CreatedAt.IsZero() {
+		return key, interfaces.ErrInvalidCursor
+	}
+	return key, nil
+}
+
 // UpdatePassword updates a user's password
 func (r *userRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, hashedPassword string) error {
 	updates := map[string]interface{}{
@@ -250,6 +418,70 @@ func (r *userRepository) LockUser(ctx context.Context, userID uuid.UUID, lockDur
 	return nil
 }
 
+// ResetExpiredLockout clears the lock and failed login count of a user whose
+// lock has expired. A lock that is still in force, e.g. one set again by a
+// concurrent failed attempt, is left alone.
+func (r *userRepository) ResetExpiredLockout(ctx context.Context, userID uuid.UUID) error {
+	updates := map[string]interface{}{
+		"locked_until":       nil,
+		"failed_login_count": 0,
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND locked_until <= ?", userID, time.Now()).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to reset expired lockout: %w", err)
+	}
+
+	return nil
+}
+
+// SetTOTPSecret stores a new, not yet enabled, TOTP secret for the user
+func (r *userRepository) SetTOTPSecret(ctx context.Context, userID uuid.UUID, secret string) error {
+	updates := map[string]interface{}{
+		"totp_secret":         secret,
+		"totp_enabled":        false,
+		"totp_last_used_step": 0,
+	}
+
+	if err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(updates).Error; err != nil {
+		return fmt.Errorf("failed to set TOTP secret: %w", err)
+	}
+
+	return nil
+}
+
+// EnableTOTP turns on two-factor authentication with the stored secret
+func (r *userRepository) EnableTOTP(ctx context.Context, userID uuid.UUID) error {
+	if err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ?", userID).
+		Update("totp_enabled", true).Error; err != nil {
+		return fmt.Errorf("failed to enable TOTP: %w", err)
+	}
+
+	return nil
+}
+
+// ConsumeTOTPStep records step as the user's last accepted TOTP time step.
+// It reports false, leaving the user untouched, when a code from that step
+// or a later one was already accepted, so every code works only once.
+func (r *userRepository) ConsumeTOTPStep(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Where("id = ? AND totp_last_used_step < ?", userID, step).
+		Update("totp_last_used_step", step)
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to consume TOTP step: %w", result.Error)
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
 // UnlockUser unlocks a user account
 func (r *userRepository) UnlockUser(ctx context.Context, userID uuid.UUID) error {
 	updates := map[string]interface{}{
@@ -335,29 +567,227 @@ func (r *userRepository) IsUserActive(ctx context.Context, userID uuid.UUID) (bo
 	return user.IsActive, nil
 }
 
-// AssignRole assigns a role to a user
-func (r *userRepository) AssignRole(ctx context.Context, userID, roleID uuid.UUID) error {
-	userRole := &models.UserRole{
-		UserID:    userID,
-		RoleID:    roleID,
-		GrantedAt: time.Now(),
+// AssignRole assigns a role to a user, records who granted it and bumps the
+// user's token version so that tokens carrying the old permissions are
+// rejected. A nil actorID marks the change as made by the system, and a nil
+// expiresAt grants the role indefinitely. Assigning a role the user already
+// holds does nothing.
+func (r *userRepository) AssignRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID, expiresAt *time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return r.grantRole(tx, []uuid.UUID{userID}, roleID, actorID, expiresAt)
+	})
+}
+
+// grantRole gives roleID to those of userIDs not already holding it,
+// recording the grants and bumping their token versions. Expired
+// assignments are replaced.
+func (r *userRepository) grantRole(tx *gorm.DB, userIDs []uuid.UUID, roleID uuid.UUID, actorID *uuid.UUID, expiresAt *time.Time) error {
+	var holders []uuid.UUID
+	if err := tx.Model(&models.UserRole{}).
+		Where("user_id IN ? AND role_id = ? AND "+activeUserRole, userIDs, roleID).
+		Pluck("user_id", &holders).Error; err != nil {
+		return fmt.Errorf("failed to check existing roles: %w", err)
 	}
-	
-	if err := r.db.WithContext(ctx).Create(userRole).Error; err != nil {
+	held := make(map[uuid.UUID]bool, len(holders))
+	for _, id := range holders {
+		held[id] = true
+	}
+
+	var pending []uuid.UUID
+	for _, id := range userIDs {
+		if !held[id] {
+			pending = append(pending, id)
+			held[id] = true
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	if err := r.checkRoleLimit(tx, pending, roleID); err != nil {
+		return err
+	}
+
+	if err := tx.
+		Where("user_id IN ? AND role_id = ? AND NOT "+activeUserRole, pending, roleID).
+		Delete(&models.UserRole{}).Error; err != nil {
+		return fmt.Errorf("failed to remove expired roles: %w", err)
+	}
+
+	now := time.Now()
+	userRoles := make([]*models.UserRole, len(pending))
+	for i, userID := range pending {
+		userRoles[i] = &models.UserRole{
+			UserID:    userID,
+			RoleID:    roleID,
+			GrantedAt: now,
+			ExpiresAt: expiresAt,
+		}
+		if actorID != nil {
+			userRoles[i].GrantedBy = *actorID
+		}
+	}
+
+	if err := tx.Create(&userRoles).Error; err != nil {
 		return fmt.Errorf("failed to assign role: %w", err)
 	}
+	if err := recordRoleAssignments(tx, pending, roleID, models.RoleAssignmentGranted, actorID); err != nil {
+		return err
+	}
+	return bumpTokenVersions(tx, pending)
+}
+
+// RevokeRole revokes a role from a user, records who revoked it and bumps
+// the user's token version
+func (r *userRepository) RevokeRole(ctx context.Context, userID, roleID uuid.UUID, actorID *uuid.UUID) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.
+			Where("user_id = ? AND role_id = ?", userID, roleID).
+			Delete(&models.UserRole{})
+		if result.Error != nil {
+			return fmt.Errorf("failed to revoke role: %w", result.Error)
+		}
+		if result.RowsAffected > 0 {
+			if err := recordRoleAssignments(tx, []uuid.UUID{userID}, roleID, models.RoleAssignmentRevoked, actorID); err != nil {
+				return err
+			}
+		}
+		return bumpTokenVersions(tx, []uuid.UUID{userID})
+	})
+}
+
+// GetRoleAssignmentHistory returns the roles granted to and revoked from a
+// user, newest first
+func (r *userRepository) GetRoleAssignmentHistory(ctx context.Context, userID uuid.UUID) ([]*models.RoleAssignmentHistory, error) {
+	var history []*models.RoleAssignmentHistory
+	if err := r.db.WithContext(ctx).
+		Select("role_assignment_histories.*, roles.name AS role_name").
+		Joins("LEFT JOIN roles ON roles.id = role_assignment_histories.role_id").
+		Where("role_assignment_histories.user_id = ?", userID).
+		Order("role_assignment_histories.created_at DESC").
+		Find(&history).Error; err != nil {
+		return nil, fmt.Errorf("failed to get role assignment history: %w", err)
+	}
 	
+	return history, nil
+}
+
+// checkRoleLimit rejects granting roleID to any user already holding the
+// maximum number of other roles. The users are locked so concurrent
+// assignments cannot both pass the check.
+func (r *userRepository) checkRoleLimit(tx *gorm.DB, userIDs []uuid.UUID, roleID uuid.UUID) error {
+	if r.maxRolesPerUser <= 0 {
+		return nil
+	}
+
+	var locked []uuid.UUID
+	if err := tx.Model(&models.User{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id IN ?", userIDs).
+		Pluck("id", &locked).Error; err != nil {
+		return fmt.Errorf("failed to lock users: %w", err)
+	}
+
+	var full []uuid.UUID
+	if err := tx.Model(&models.UserRole{}).
+		Where("user_id IN ? AND role_id <> ? AND "+activeUserRole, userIDs, roleID).
+		Group("user_id").
+		Having("COUNT(*) >= ?", r.maxRolesPerUser).
+		Pluck("user_id", &full).Error; err != nil {
+		return fmt.Errorf("failed to count user roles: %w", err)
+	}
+	if len(full) > 0 {
+		return fmt.Errorf("%w: user %s already holds %d roles", interfaces.ErrTooManyRoles, full[0], r.maxRolesPerUser)
+	}
+
 	return nil
 }
 
-// RevokeRole revokes a role from a user
-func (r *userRepository) RevokeRole(ctx context.Context, userID, roleID uuid.UUID) error {
+// recordRoleAssignments writes a history entry for each user
+func recordRoleAssignments(tx *gorm.DB, userIDs []uuid.UUID, roleID uuid.UUID, action string, actorID *uuid.UUID) error {
+	entries := make([]*models.RoleAssignmentHistory, len(userIDs))
+	for i, userID := range userIDs {
+		entries[i] = &models.RoleAssignmentHistory{
+			UserID:  userID,
+			RoleID:  roleID,
+			Action:  action,
+			ActorID: actorID,
+		}
+	}
+	
+	if err := tx.Create(&entries).Error; err != nil {
+		return fmt.Errorf("failed to record role assignment history: %w", err)
+	}
+	return nil
+}
+
+// GetTokenVersion returns the user's current token version
+func (r *userRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	var user models.User
 	if err := r.db.WithContext(ctx).
-		Where("user_id = ? AND role_id = ?", userID, roleID).
-		Delete(&models.UserRole{}).Error; err != nil {
-		return fmt.Errorf("failed to revoke role: %w", err)
+		Select("token_version").
+		Where("id = ?", userID).
+		First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return 0, fmt.Errorf("user not found")
+		}
+		return 0, fmt.Errorf("failed to get token version: %w", err)
 	}
 	
+	return user.TokenVersion, nil
+}
+
+// bumpTokenVersions increments the token version of the given users
+func bumpTokenVersions(tx *gorm.DB, userIDs []uuid.UUID) error {
+	if err := tx.Model(&models.User{}).
+		Where("id IN ?", userIDs).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+		return fmt.Errorf("failed to bump token version: %w", err)
+	}
+	return nil
+}
+
+// activeUserRole matches user_roles rows whose assignment has not expired
+const activeUserRole = "(user_roles.expires_at IS NULL OR user_roles.expires_at > NOW())"
+
+// dropExpiredRoles removes roles whose assignment has expired from the
+// users' preloaded roles, which Preload cannot filter by the join table
+func (r *userRepository) dropExpiredRoles(ctx context.Context, users ...*models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	var expired []models.UserRole
+	if err := r.db.WithContext(ctx).
+		Where("user_id IN ? AND NOT "+activeUserRole, userIDs).
+		Find(&expired).Error; err != nil {
+		return fmt.Errorf("failed to check role expiry: %w", err)
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	type assignment struct{ userID, roleID uuid.UUID }
+	isExpired := make(map[assignment]bool, len(expired))
+	for _, userRole := range expired {
+		isExpired[assignment{userRole.UserID, userRole.RoleID}] = true
+	}
+
+	for _, user := range users {
+		active := user.Roles[:0]
+		for _, role := range user.Roles {
+			if !isExpired[assignment{user.ID, role.ID}] {
+				active = append(active, role)
+			}
+		}
+		user.Roles = active
+	}
+
 	return nil
 }
 
@@ -367,7 +797,7 @@ func (r *userRepository) GetUserRoles(ctx context.Context, userID uuid.UUID) ([]
 	if err := r.db.WithContext(ctx).
 		Table("roles").
 		Joins("JOIN user_roles ON roles.id = user_roles.role_id").
-		Where("user_roles.user_id = ?", userID).
+		Where("user_roles.user_id = ? AND "+activeUserRole, userID).
 		Find(&roles).Error; err != nil {
 		return nil, fmt.Errorf("failed to get user roles: %w", err)
 	}
@@ -381,7 +811,7 @@ func (r *userRepository) HasRole(ctx context.Context, userID uuid.UUID, roleName
 	if err := r.db.WithContext(ctx).
 		Table("user_roles").
 		Joins("JOIN roles ON user_roles.role_id = roles.id").
-		Where("user_roles.user_id = ? AND roles.name = ?", userID, roleName).
+		Where("user_roles.user_id = ? AND roles.name = ? AND "+activeUserRole, userID, roleName).
 		Count(&count).Error; err != nil {
 		return false, fmt.Errorf("failed to check user role: %w", err)
 	}
@@ -395,7 +825,7 @@ func (r *userRepository) HasPermission(ctx context.Context, userID uuid.UUID, pe
 	if err := r.db.WithContext(ctx).
 		Table("user_roles").
 		Joins("JOIN roles ON user_roles.role_id = roles.id").
-		Where("user_roles.user_id = ? AND (roles.permissions @> ? OR roles.permissions @> ?)", 
+		Where("user_roles.user_id = ? AND "+activeUserRole+" AND (roles.permissions @> ? OR roles.permissions @> ?)",
 			userID, `["`+permission+`"]`, `["*"]`).
 		Count(&count).Error; err != nil {
 		return false, fmt.Errorf("failed to check user permission: %w", err)
@@ -419,6 +849,10 @@ func (r *userRepository) SearchUsers(ctx context.Context, query string, filters
 		return nil, fmt.Errorf("failed to search users: %w", err)
 	}
 	
+	if err := r.dropExpiredRoles(ctx, users...); err != nil {
+		return nil, err
+	}
+
 	return users, nil
 }
 
@@ -442,9 +876,11 @@ func (r *userRepository) GetUsersWithRole(ctx context.Context, roleName string)
 	return r.List(ctx, interfaces.UserFilters{RoleName: roleName})
 }
 
-// GetUserStats retrieves user statistics
-func (r *userRepository) GetUserStats(ctx context.Context) (*interfaces.UserStats, error) {
-	stats := &interfaces.UserStats{}
+// GetUserStats retrieves user statistics. The new-user windows start at
+// midnight in loc, or in UTC when loc is nil.
+func (r *userRepository) GetUserStats(ctx context.Context, loc *time.Location) (*interfaces.UserStats, error) {
+	windows := interfaces.NewStatsWindows(time.Now(), loc)
+	stats := &interfaces.UserStats{Timezone: windows.Today.Location().String()}
 	
 	// Total users
 	r.db.WithContext(ctx).Model(&models.User{}).Count(&stats.TotalUsers)
@@ -465,20 +901,117 @@ func (r *userRepository) GetUserStats(ctx context.Context) (*interfaces.UserStat
 	r.db.WithContext(ctx).Model(&models.User{}).Where("locked_until > ?", time.Now()).Count(&stats.LockedUsers)
 	
 	// New users today
-	today := time.Now().Truncate(24 * time.Hour)
-	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", today).Count(&stats.NewUsersToday)
+	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", windows.Today).Count(&stats.NewUsersToday)
 	
 	// New users this week
-	weekStart := today.AddDate(0, 0, -int(today.Weekday()))
-	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", weekStart).Count(&stats.NewUsersThisWeek)
+	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", windows.Week).Count(&stats.NewUsersThisWeek)
 	
 	// New users this month
-	monthStart := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, today.Location())
-	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", monthStart).Count(&stats.NewUsersThisMonth)
+	r.db.WithContext(ctx).Model(&models.User{}).Where("created_at >= ?", windows.Month).Count(&stats.NewUsersThisMonth)
+	
+	// Users by role
+	usersByRole, err := r.countUsersByRole(ctx)
+	if err != nil {
+		return nil, err
+	}
+	stats.UsersByRole = usersByRole
 	
 	return stats, nil
 }
 
+// countUsersByRole returns the number of non-deleted users holding each role.
+// Roles without users are included with a zero count.
+func (r *userRepository) countUsersByRole(ctx context.Context) (map[string]int64, error) {
+	var rows []struct {
+		Name  string
+		Count int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.Role{}).
+		Select("roles.name, COUNT(users.id) AS count").
+		Joins("LEFT JOIN user_roles ON user_roles.role_id = roles.id AND " + activeUserRole).
+		Joins("LEFT JOIN users ON users.id = user_roles.user_id AND users.deleted_at IS NULL").
+		Group("roles.name").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count users by role: %w", err)
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Name] = row.Count
+	}
+
+	return counts, nil
+}
+
+// GetUserGrowth returns the number of users created in [from, to), bucketed
+// by interval in UTC. Buckets with no new users are included with a zero count.
+// Weeks start on Monday.
+func (r *userRepository) GetUserGrowth(ctx context.Context, from, to time.Time, interval interfaces.GrowthInterval) ([]interfaces.GrowthBucket, error) {
+	switch interval {
+	case interfaces.GrowthIntervalDay, interfaces.GrowthIntervalWeek, interfaces.GrowthIntervalMonth:
+	default:
+		return nil, fmt.Errorf("invalid growth interval: %s", interval)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("invalid growth range: from must be before to")
+	}
+
+	var rows []struct {
+		Bucket time.Time
+		Count  int64
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&models.User{}).
+		Select("date_trunc(?, created_at AT TIME ZONE 'UTC') AS bucket, COUNT(*) AS count", string(interval)).
+		Where("created_at >= ? AND created_at < ?", from, to).
+		Group("bucket").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get user growth: %w", err)
+	}
+
+	counts := make(map[time.Time]int64, len(rows))
+	for _, row := range rows {
+		start := time.Date(row.Bucket.Year(), row.Bucket.Month(), row.Bucket.Day(), 0, 0, 0, 0, time.UTC)
+		counts[start] = row.Count
+	}
+
+	var buckets []interfaces.GrowthBucket
+	for start := truncateToInterval(from, interval); start.Before(to); start = nextInterval(start, interval) {
+		buckets = append(buckets, interfaces.GrowthBucket{Start: start, Count: counts[start]})
+	}
+
+	return buckets, nil
+}
+
+// truncateToInterval returns the UTC start of the bucket containing t
+func truncateToInterval(t time.Time, interval interfaces.GrowthInterval) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch interval {
+	case interfaces.GrowthIntervalWeek:
+		// date_trunc('week') starts weeks on Monday
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case interfaces.GrowthIntervalMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// nextInterval returns the start of the bucket following start
+func nextInterval(start time.Time, interval interfaces.GrowthInterval) time.Time {
+	switch interval {
+	case interfaces.GrowthIntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case interfaces.GrowthIntervalMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
 // GetLoginStats retrieves login statistics for a user
 func (r *userRepository) GetLoginStats(ctx context.Context, userID uuid.UUID) (*interfaces.LoginStats, error) {
 	var user models.User
@@ -491,55 +1024,108 @@ func (r *userRepository) GetLoginStats(ctx context.Context, userID uuid.UUID) (*
 		}
 		return nil, fmt.Errorf("failed to get user login stats: %w", err)
 	}
-	
-	return &interfaces.LoginStats{
+
+	stats := &interfaces.LoginStats{
 		UserID:      user.ID,
 		LastLogin:   user.LastLoginAt,
 		IsLocked:    user.IsLocked(),
 		LockedUntil: user.LockedUntil,
-	}, nil
+	}
+
+	// Every login attempt is audited, so count them per outcome. Failures
+	// folded into a sampling summary are counted from its per-user tally.
+	var rows []struct {
+		Success bool
+		Count   int64
+		LastAt  *time.Time
+	}
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT success, COUNT(*) AS count, MAX(created_at) AS last_at
+		FROM audit_logs
+		WHERE user_id = ? AND action = ?
+		GROUP BY success
+		UNION ALL
+		SELECT false, COALESCE(SUM((details->'suppressed_by_user'->>?)::bigint), 0), MAX(created_at)
+		FROM audit_logs
+		WHERE action = ? AND details->'suppressed_by_user'->>? IS NOT NULL`,
+		userID, "user.login", userID.String(), "user.login_failed_summary", userID.String()).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count user logins: %w", err)
+	}
+
+	for _, row := range rows {
+		if row.Count == 0 {
+			continue
+		}
+		stats.TotalLogins += row.Count
+		if row.Success {
+			stats.SuccessfulLogins += row.Count
+			continue
+		}
+		stats.FailedLogins += row.Count
+		if stats.LastFailedLogin == nil || (row.LastAt != nil && row.LastAt.After(*stats.LastFailedLogin)) {
+			stats.LastFailedLogin = row.LastAt
+		}
+	}
+
+	return stats, nil
 }
 
-// BulkUpdate updates multiple users
+// BulkUpdate updates multiple users, in batches within one transaction
 func (r *userRepository) BulkUpdate(ctx context.Context, userIDs []uuid.UUID, updates map[string]interface{}) error {
-	if err := r.db.WithContext(ctx).
-		Model(&models.User{}).
-		Where("id IN ?", userIDs).
-		Updates(updates).Error; err != nil {
-		return fmt.Errorf("failed to bulk update users: %w", err)
-	}
-	
-	return nil
+	return r.inBatches(ctx, userIDs, func(tx *gorm.DB, batch []uuid.UUID) error {
+		if err := tx.
+			Model(&models.User{}).
+			Where("id IN ?", batch).
+			Updates(updates).Error; err != nil {
+			return fmt.Errorf("failed to bulk update users: %w", err)
+		}
+		return nil
+	})
 }
 
-// BulkDelete deletes multiple users
+// BulkDelete deletes multiple users, in batches within one transaction
 func (r *userRepository) BulkDelete(ctx context.Context, userIDs []uuid.UUID) error {
-	if err := r.db.WithContext(ctx).
-		Delete(&models.User{}, userIDs).Error; err != nil {
-		return fmt.Errorf("failed to bulk delete users: %w", err)
-	}
-	
-	return nil
+	return r.inBatches(ctx, userIDs, func(tx *gorm.DB, batch []uuid.UUID) error {
+		if err := tx.Delete(&models.User{}, batch).Error; err != nil {
+			return fmt.Errorf("failed to bulk delete users: %w", err)
+		}
+		return nil
+	})
 }
 
-// BulkAssignRole assigns a role to multiple users
-func (r *userRepository) BulkAssignRole(ctx context.Context, userIDs []uuid.UUID, roleID uuid.UUID) error {
-	userRoles := make([]*models.UserRole, len(userIDs))
-	now := time.Now()
-	
-	for i, userID := range userIDs {
-		userRoles[i] = &models.UserRole{
-			UserID:    userID,
-			RoleID:    roleID,
-			GrantedAt: now,
+// BulkAssignRole assigns a role to multiple users, in batches within one
+// transaction. Users already holding the role are left untouched.
+func (r *userRepository) BulkAssignRole(ctx context.Context, userIDs []uuid.UUID, roleID uuid.UUID, actorID *uuid.UUID) error {
+	return r.inBatches(ctx, userIDs, func(tx *gorm.DB, batch []uuid.UUID) error {
+		if err := r.grantRole(tx, batch, roleID, actorID, nil); err != nil {
+			return fmt.Errorf("failed to bulk assign role: %w", err)
 		}
+		return nil
+	})
+}
+
+// inBatches runs fn over userIDs in slices of at most the bulk batch size,
+// all in one transaction so a failing batch rolls back the earlier ones
+func (r *userRepository) inBatches(ctx context.Context, userIDs []uuid.UUID, fn func(tx *gorm.DB, batch []uuid.UUID) error) error {
+	if len(userIDs) == 0 {
+		return nil
 	}
-	
-	if err := r.db.WithContext(ctx).Create(&userRoles).Error; err != nil {
-		return fmt.Errorf("failed to bulk assign role: %w", err)
+
+	size := r.bulkBatchSize
+	if size <= 0 {
+		size = defaultBulkBatchSize
 	}
-	
-	return nil
+
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(userIDs); start += size {
+			end := min(start+size, len(userIDs))
+			if err := fn(tx, userIDs[start:end]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
 // BeginTransaction starts a new transaction
@@ -549,11 +1135,31 @@ func (r *userRepository) BeginTransaction(ctx context.Context) (*gorm.DB, error)
 
 // WithTransaction returns a repository instance with the given transaction
 func (r *userRepository) WithTransaction(tx *gorm.DB) interfaces.UserRepository {
-	return &userRepository{db: tx}
+	return &userRepository{db: tx, maxRolesPerUser: r.maxRolesPerUser, bulkBatchSize: r.bulkBatchSize}
 }
 
 // buildQuery builds a GORM query with filters
 func (r *userRepository) buildQuery(filters interfaces.UserFilters) *gorm.DB {
+	query := r.buildFilterQuery(filters)
+
+	// Add sorting
+	sortBy := filters.SortBy
+	if sortBy == "" {
+		sortBy = "created_at"
+	}
+
+	sortOrder := filters.SortOrder
+	if sortOrder == "" {
+		sortOrder = "desc"
+	}
+
+	query = query.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
+
+	return query
+}
+
+// buildFilterQuery builds a GORM query applying the filters without ordering
+func (r *userRepository) buildFilterQuery(filters interfaces.UserFilters) *gorm.DB {
 	query := r.db.Model(&models.User{})
 	
 	if filters.IsActive != nil {
@@ -565,7 +1171,7 @@ func (r *userRepository) buildQuery(filters interfaces.UserFilters) *gorm.DB {
 	}
 	
 	if filters.Email != "" {
-		query = query.Where("email = ?", filters.Email)
+		query = query.Where("LOWER(email) = ?", normalizeEmail(filters.Email))
 	}
 	
 	if filters.Username != "" {
@@ -575,7 +1181,7 @@ func (r *userRepository) buildQuery(filters interfaces.UserFilters) *gorm.DB {
 	if filters.RoleName != "" {
 		query = query.Joins("JOIN user_roles ON users.id = user_roles.user_id").
 			Joins("JOIN roles ON user_roles.role_id = roles.id").
-			Where("roles.name = ?", filters.RoleName)
+			Where("roles.name = ? AND "+activeUserRole, filters.RoleName)
 	}
 	
 	if filters.CreatedFrom != nil {
@@ -594,18 +1200,5 @@ func (r *userRepository) buildQuery(filters interfaces.UserFilters) *gorm.DB {
 		query = query.Where("last_login_at <= ?", *filters.LastLoginTo)
 	}
 	
-	// Add sorting
-	sortBy := filters.SortBy
-	if sortBy == "" {
-		sortBy = "created_at"
-	}
-	
-	sortOrder := filters.SortOrder
-	if sortOrder == "" {
-		sortOrder = "desc"
-	}
-	
-	query = query.Order(fmt.Sprintf("%s %s", sortBy, sortOrder))
-	
 	return query
 }
\ No newline at end of file
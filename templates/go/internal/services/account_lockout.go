@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+
+	"app/internal/models"
+)
+
+// Lockout policy used when the config leaves it unset
+const (
+	defaultMaxLoginAttempts      = 5
+	defaultAccountLockoutMinutes = 30
+)
+
+// maxLoginAttempts is the number of consecutive failed logins that locks an
+// account
+func (s *AuthService) maxLoginAttempts() int {
+	if s.config.MaxLoginAttempts > 0 {
+		return s.config.MaxLoginAttempts
+	}
+	return defaultMaxLoginAttempts
+}
+
+// accountLockoutMinutes is how long an account stays locked once the failed
+// login threshold is reached
+func (s *AuthService) accountLockoutMinutes() int {
+	if s.config.AccountLockoutMinutes > 0 {
+		return s.config.AccountLockoutMinutes
+	}
+	return defaultAccountLockoutMinutes
+}
+
+// resetExpiredLockout gives a user whose lock has run out a fresh attempt
+// budget. Without it the failed count stays at the threshold and the next
+// single failure would lock the account again.
+func (s *AuthService) resetExpiredLockout(ctx context.Context, user *models.User) {
+	if user.LockedUntil == nil || user.IsLocked() {
+		return
+	}
+	if err := s.userRepo.ResetExpiredLockout(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to reset expired lockout", "error", err, "user_id", user.ID)
+		return
+	}
+	user.LockedUntil = nil
+	user.FailedLoginCount = 0
+}
+
+// countFailedAttempt counts a failed password or second-factor attempt
+// against the account, locking it once the threshold is reached
+func (s *AuthService) countFailedAttempt(ctx context.Context, user *models.User, ipAddress, userAgent string) {
+	// Increment failed login count
+	s.userRepo.IncrementFailedLoginCount(ctx, user.ID)
+
+	// Check if account should be locked
+	if user.FailedLoginCount+1 < s.maxLoginAttempts() {
+		return
+	}
+
+	lockMinutes := s.accountLockoutMinutes()
+	s.userRepo.LockUser(ctx, user.ID, lockMinutes)
+	s.logger.Warn("User account locked due to too many failed attempts",
+		"user_id", user.ID,
+		"ip_address", ipAddress)
+
+	// Notify the user, at most once per cooldown window
+	notified := s.claimLockoutNotification(ctx, user.ID)
+	if notified {
+		go s.sendLockoutEmail(ctx, user)
+	}
+
+	// Lockouts are always audited, even when failures are sampled
+	s.createAuditLog(ctx, &user.ID, "user.lockout", "user", &user.ID, map[string]interface{}{
+		"ip_address":     ipAddress,
+		"user_agent":     userAgent,
+		"locked_minutes": lockMinutes,
+		"notified":       notified,
+	}, ipAddress, userAgent, true, nil)
+}
+
+// resetFailedAttempts clears the user's failed attempts after a successful
+// authentication
+func (s *AuthService) resetFailedAttempts(ctx context.Context, user *models.User) {
+	if user.FailedLoginCount == 0 {
+		return
+	}
+	if err := s.userRepo.ResetFailedLoginCount(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to reset failed login count", "error", err, "user_id", user.ID)
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+)
+
+// BootstrapAdmin creates the first admin account from the configured
+// bootstrap credentials when no admin exists yet. It reports whether an
+// admin was created. Without configured credentials, or once any admin
+// exists, it does nothing, so it is safe to run on every start.
+func (s *AuthService) BootstrapAdmin(ctx context.Context) (bool, error) {
+	admins, err := s.userRepo.Count(ctx, interfaces.UserFilters{RoleName: "admin"})
+	if err != nil {
+		return false, fmt.Errorf("failed to count admin users: %w", err)
+	}
+	if admins > 0 {
+		return false, nil
+	}
+
+	if s.config.BootstrapAdminEmail == "" || s.config.BootstrapAdminPassword == "" {
+		s.logger.Warn("No admin user exists and no bootstrap admin is configured")
+		return false, nil
+	}
+
+	// The bootstrap password is held to the same policy as any other
+	if err := s.passwordService.IsPasswordValid(s.config.BootstrapAdminPassword); err != nil {
+		return false, fmt.Errorf("bootstrap admin password validation failed: %w", err)
+	}
+
+	email, err := s.NormalizeEmail(ctx, s.config.BootstrapAdminEmail)
+	if err != nil {
+		return false, fmt.Errorf("invalid bootstrap admin email: %w", err)
+	}
+
+	hashedPassword, err := s.passwordService.HashPassword(s.config.BootstrapAdminPassword)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	username := s.config.BootstrapAdminUsername
+	if username == "" {
+		username = "admin"
+	}
+
+	user := &models.User{
+		Email:        email,
+		Username:     username,
+		PasswordHash: hashedPassword,
+		FirstName:    "Admin",
+		IsActive:     true,
+		IsVerified:   true,
+	}
+
+	var adminRole models.Role
+	if err := s.db.WithContext(ctx).Where("name = ?", "admin").First(&adminRole).Error; err != nil {
+		return false, fmt.Errorf("admin role not found: %w", err)
+	}
+
+	tx, err := s.userRepo.BeginTransaction(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userRepoTx := s.userRepo.WithTransaction(tx)
+	if err := userRepoTx.Create(ctx, user); err != nil {
+		return false, fmt.Errorf("failed to create bootstrap admin: %w", err)
+	}
+	if err := userRepoTx.AssignRole(ctx, user.ID, adminRole.ID, nil, nil); err != nil {
+		return false, fmt.Errorf("failed to assign admin role: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return false, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Warn("Created bootstrap admin user; change its password and remove the bootstrap credentials",
+		"user_id", user.ID,
+		"email", user.Email)
+
+	s.createAuditLog(ctx, &user.ID, "user.admin_bootstrap", "user", &user.ID, map[string]interface{}{
+		"email":    user.Email,
+		"username": user.Username,
+	}, "", "", true, nil)
+
+	return true, nil
+}
@@ -2,12 +2,15 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"app/internal/auth"
 	"app/internal/config"
@@ -16,16 +19,64 @@ import (
 	"app/internal/utils"
 )
 
+// ErrUserNotFound is returned when an operation targets a user that does not exist
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrUnknownClient is returned when a login names a client_id with no configured audience
+var ErrUnknownClient = errors.New("unknown client")
+
+// ErrRefreshTokenReuse is returned when an already rotated-out refresh token
+// is presented again, which suggests it was stolen
+var ErrRefreshTokenReuse = errors.New("refresh token reuse detected")
+
+// ErrAccountLocked is matched by the AccountLockedError returned when a
+// login targets an account that is temporarily locked
+var ErrAccountLocked = errors.New("account is locked")
+
+// AccountLockedError reports a login against a locked account together with
+// when the lock expires
+type AccountLockedError struct {
+	LockedUntil time.Time
+}
+
+// RemainingSeconds returns the whole seconds until the account unlocks,
+// rounded up so that clients never retry early
+func (e *AccountLockedError) RemainingSeconds() int {
+	seconds := int(math.Ceil(time.Until(e.LockedUntil).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("login not allowed: %s, try again in %d seconds", ErrAccountLocked, e.RemainingSeconds())
+}
+
+func (e *AccountLockedError) Unwrap() error {
+	return ErrAccountLocked
+}
+
+// ErrPasswordChangeSuperseded is returned when the password was changed by
+// another request after this change verified the current password
+var ErrPasswordChangeSuperseded = errors.New("password was changed by another request")
+
+// ErrLoginIdentifierTooLong is returned when a login identifier exceeds the configured maximum length
+var ErrLoginIdentifierTooLong = errors.New("login identifier too long")
+
 // AuthService handles authentication and authorization logic
 type AuthService struct {
 	userRepo        interfaces.UserRepository
 	jwtService      *auth.JWTService
 	passwordService *auth.PasswordService
 	sessionService  *auth.SessionService
+	blacklist       *auth.BlacklistService
 	redisClient     *redis.Client
 	config          *config.Config
 	logger          *utils.Logger
 	db              *gorm.DB
+	emailValidator  *utils.EmailValidator
+	totp            *auth.TOTPService
 }
 
 // NewAuthService creates a new authentication service
@@ -34,6 +85,7 @@ func NewAuthService(
 	jwtService *auth.JWTService,
 	passwordService *auth.PasswordService,
 	sessionService *auth.SessionService,
+	blacklist *auth.BlacklistService,
 	redisClient *redis.Client,
 	config *config.Config,
 	logger *utils.Logger,
@@ -44,20 +96,34 @@ func NewAuthService(
 		jwtService:      jwtService,
 		passwordService: passwordService,
 		sessionService:  sessionService,
+		blacklist:       blacklist,
 		redisClient:     redisClient,
 		config:          config,
 		logger:          logger,
 		db:              db,
+		emailValidator:  utils.NewEmailValidator(config.EmailCheckMX),
+		totp:            auth.NewTOTPService(config.TOTPIssuer),
 	}
 }
 
-// Register registers a new user
-func (s *AuthService) Register(ctx context.Context, req *models.UserCreateRequest) (*models.AuthResponse, error) {
+// Register registers a new user. The tokens it issues are bound to
+// ipAddress when IP binding is enabled.
+func (s *AuthService) Register(ctx context.Context, req *models.UserCreateRequest, ipAddress, userAgent string) (*models.AuthResponse, error) {
+	if s.config.RequireInvitation && req.InviteToken == "" {
+		return nil, ErrInvitationRequired
+	}
+
 	// Validate password strength
 	if err := s.passwordService.IsPasswordValid(req.Password); err != nil {
 		return nil, fmt.Errorf("password validation failed: %w", err)
 	}
 
+	email, err := s.NormalizeEmail(ctx, req.Email)
+	if err != nil {
+		return nil, err
+	}
+	req.Email = email
+
 	// Check if user already exists
 	if _, err := s.userRepo.GetByEmail(ctx, req.Email); err == nil {
 		return nil, fmt.Errorf("user with this email already exists")
@@ -84,7 +150,8 @@ func (s *AuthService) Register(ctx context.Context, req *models.UserCreateReques
 		IsVerified:   false, // Email verification required
 	}
 
-	// Begin transaction
+	// Begin transaction. Every write of the registration, including the
+	// refresh token, happens in it, so a failure leaves nothing behind.
 	tx, err := s.userRepo.BeginTransaction(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -97,69 +164,105 @@ func (s *AuthService) Register(ctx context.Context, req *models.UserCreateReques
 		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
+	// Redeem the invitation in the same transaction, so it is only used up
+	// by a registration that succeeds
+	if s.config.RequireInvitation {
+		if err := s.redeemInvitation(tx, req.InviteToken, req.Email, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Assign default user role
-	if err := s.assignDefaultRole(ctx, userRepoTx, user.ID); err != nil {
+	if err := s.assignDefaultRole(ctx, tx, userRepoTx, user.ID); err != nil {
 		return nil, fmt.Errorf("failed to assign default role: %w", err)
 	}
 
+	// Hold the account for admin activation when required. This is a separate
+	// update because GORM replaces a false is_active with its column default.
+	if s.config.RequireAccountActivation {
+		if err := userRepoTx.DeactivateUser(ctx, user.ID); err != nil {
+			return nil, fmt.Errorf("failed to mark user pending activation: %w", err)
+		}
+	}
+
 	// Reload user with roles
 	user, err = userRepoTx.GetByID(ctx, user.ID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to reload user: %w", err)
 	}
 
-	// Commit transaction
-	if err := tx.Commit().Error; err != nil {
-		return nil, fmt.Errorf("failed to commit transaction: %w", err)
-	}
+	// Accounts pending activation cannot log in, so no tokens are issued
+	var response *models.AuthResponse
+	if user.IsActive {
+		accessToken, err := s.jwtService.GenerateTokenForClient(user, auth.AuthMethodPassword, "", ipAddress)
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate access token: %w", err)
+		}
 
-	// Generate tokens
-	accessToken, err := s.jwtService.GenerateToken(user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		refreshToken, err := s.createRefreshTokenIn(ctx, tx, user.ID, uuid.Nil, auth.AuthMethodPassword, "", ipAddress, userAgent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create refresh token: %w", err)
+		}
+
+		response = &models.AuthResponse{
+			AccessToken:  accessToken,
+			RefreshToken: refreshToken,
+			TokenType:    "Bearer",
+			ExpiresIn:    int(s.jwtService.GetTokenExpiration().Seconds()),
+			User:         user.ToResponse(),
+		}
+	} else {
+		response = &models.AuthResponse{User: user.ToResponse()}
 	}
 
-	refreshToken, err := s.createRefreshToken(ctx, user.ID, "", "")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+	// Commit transaction
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
 	}
 
 	// Log successful registration
 	s.logger.Info("User registered successfully", 
 		"user_id", user.ID, 
 		"email", user.Email,
-		"username", user.Username)
+		"username", user.Username,
+		"pending_activation", !user.IsActive)
 
 	// Create audit log
-	s.createAuditLog(ctx, &user.ID, "user.register", "user", &user.ID, nil, "", "", true, nil)
+	s.createAuditLog(ctx, &user.ID, "user.register", "user", &user.ID, nil, ipAddress, userAgent, true, nil)
 
 	// Send verification email (implement based on your email service)
 	go s.sendVerificationEmail(ctx, user)
 
-	return &models.AuthResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    int(s.jwtService.GetTokenExpiration().Seconds()),
-		User:         user.ToResponse(),
-	}, nil
+	return response, nil
 }
 
 // Login authenticates a user and returns tokens
 func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAddress, userAgent string) (*models.AuthResponse, error) {
+	// Reject unknown clients before touching credentials
+	if _, err := s.clientAudience(req.ClientID); err != nil {
+		return nil, err
+	}
+
+	// Reject oversized identifiers before they reach the database
+	if s.config.LoginIdentifierMaxLength > 0 && len(req.Login) > s.config.LoginIdentifierMaxLength {
+		return nil, ErrLoginIdentifierTooLong
+	}
+
 	// Get user by email or username
 	user, err := s.userRepo.GetByEmailOrUsername(ctx, req.Login)
 	if err != nil {
 		// Log failed login attempt
-		s.createAuditLog(ctx, nil, "user.login", "user", nil, map[string]interface{}{
+		s.recordFailedLogin(ctx, nil, map[string]interface{}{
 			"login":      req.Login,
 			"ip_address": ipAddress,
 			"user_agent": userAgent,
-		}, ipAddress, userAgent, false, &[]string{"user not found"}[0])
+		}, ipAddress, userAgent, "user not found")
 		
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
+	s.resetExpiredLockout(ctx, user)
+
 	// Check if user can login (active, verified, not locked)
 	if !user.CanLogin() {
 		var reason string
@@ -172,67 +275,103 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		}
 
 		// Log failed login attempt
-		s.createAuditLog(ctx, &user.ID, "user.login", "user", &user.ID, map[string]interface{}{
+		s.recordFailedLogin(ctx, &user.ID, map[string]interface{}{
 			"reason":     reason,
 			"ip_address": ipAddress,
 			"user_agent": userAgent,
-		}, ipAddress, userAgent, false, &reason)
+		}, ipAddress, userAgent, reason)
 
+		if user.IsLocked() && user.IsActive && user.IsVerified {
+			return nil, &AccountLockedError{LockedUntil: *user.LockedUntil}
+		}
 		return nil, fmt.Errorf("login not allowed: %s", reason)
 	}
 
 	// Verify password
 	if err := s.passwordService.VerifyPassword(user.PasswordHash, req.Password); err != nil {
-		// Increment failed login count
-		s.userRepo.IncrementFailedLoginCount(ctx, user.ID)
-
-		// Check if account should be locked
-		if user.FailedLoginCount+1 >= 5 {
-			s.userRepo.LockUser(ctx, user.ID, 30) // Lock for 30 minutes
-			s.logger.Warn("User account locked due to too many failed attempts", 
-				"user_id", user.ID, 
-				"ip_address", ipAddress)
-		}
+		s.countFailedAttempt(ctx, user, ipAddress, userAgent)
 
 		// Log failed login attempt
-		s.createAuditLog(ctx, &user.ID, "user.login", "user", &user.ID, map[string]interface{}{
+		s.recordFailedLogin(ctx, &user.ID, map[string]interface{}{
 			"ip_address": ipAddress,
 			"user_agent": userAgent,
-		}, ipAddress, userAgent, false, &[]string{"invalid password"}[0])
+		}, ipAddress, userAgent, "invalid password")
 
 		return nil, fmt.Errorf("invalid credentials")
 	}
 
-	// Update last login and reset failed login count
-	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
-		s.logger.Error("Failed to update last login", "error", err, "user_id", user.ID)
+	// With two-factor authentication the login is only complete once the
+	// second factor is verified, so failures keep counting until then
+	if user.TOTPEnabled {
+		return s.createMFAChallenge(ctx, user, req.ClientID, ipAddress, userAgent)
 	}
 
-	// Generate tokens
-	accessToken, err := s.jwtService.GenerateToken(user)
-	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
-	}
+	// The password was right, so earlier failures no longer count towards a
+	// lockout, even if the login is refused further on
+	s.resetFailedAttempts(ctx, user)
+
+	return s.completeLogin(ctx, user, auth.AuthMethodPassword, req.ClientID, ipAddress, userAgent)
+}
+
+// NormalizeEmail lowercases and trims an address and validates it more
+// strictly than the request tag. It must be applied wherever an email is
+// set, so stored addresses stay comparable.
+func (s *AuthService) NormalizeEmail(ctx context.Context, address string) (string, error) {
+	return s.emailValidator.Normalize(ctx, address)
+}
 
-	refreshToken, err := s.createRefreshToken(ctx, user.ID, ipAddress, userAgent)
+// completeLogin issues tokens and a session for an authenticated user and
+// records the method used in the session, the token and the audit log. The
+// access token is scoped to the audience configured for clientID, if any.
+func (s *AuthService) completeLogin(ctx context.Context, user *models.User, authMethod, clientID, ipAddress, userAgent string) (*models.AuthResponse, error) {
+	audience, err := s.clientAudience(clientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create refresh token: %w", err)
+		return nil, err
 	}
 
-	// Create session
+	// Create session first so that session limits are enforced before
+	// any tokens are issued
 	sessionData := &auth.SessionData{
 		UserID:      user.ID,
 		Email:       user.Email,
 		Username:    user.Username,
 		Roles:       extractRoleNames(user.Roles),
-		Permissions: extractPermissions(user.Roles),
+		Permissions: models.AggregatePermissions(user.Roles),
 		IPAddress:   ipAddress,
 		UserAgent:   userAgent,
+		AuthMethod:  authMethod,
 	}
 
 	sessionID, err := s.sessionService.CreateSession(ctx, sessionData)
 	if err != nil {
-		s.logger.Error("Failed to create session", "error", err, "user_id", user.ID)
+		if errors.Is(err, auth.ErrTooManySessions) {
+			s.createAuditLog(ctx, &user.ID, "user.login", "user", &user.ID, map[string]interface{}{
+				"ip_address":  ipAddress,
+				"user_agent":  userAgent,
+				"auth_method": authMethod,
+			}, ipAddress, userAgent, false, &[]string{"too many concurrent sessions"}[0])
+
+			return nil, fmt.Errorf("login not allowed: %w", err)
+		}
+		if !errors.Is(err, auth.ErrSessionsDisabled) {
+			s.logger.Error("Failed to create session", "error", err, "user_id", user.ID)
+		}
+	}
+
+	// Update last login and reset failed login count
+	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
+		s.logger.Error("Failed to update last login", "error", err, "user_id", user.ID)
+	}
+
+	// Generate tokens
+	accessToken, err := s.jwtService.GenerateTokenForClient(user, authMethod, audience, ipAddress)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+
+	refreshToken, err := s.createRefreshToken(ctx, user.ID, uuid.Nil, authMethod, clientID, ipAddress, userAgent)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create refresh token: %w", err)
 	}
 
 	// Log successful login
@@ -240,13 +379,15 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		"user_id", user.ID, 
 		"email", user.Email,
 		"ip_address", ipAddress,
-		"session_id", sessionID)
+		"session_id", sessionID,
+		"auth_method", authMethod)
 
 	// Create audit log
 	s.createAuditLog(ctx, &user.ID, "user.login", "user", &user.ID, map[string]interface{}{
-		"ip_address": ipAddress,
-		"user_agent": userAgent,
-		"session_id": sessionID,
+		"ip_address":  ipAddress,
+		"user_agent":  userAgent,
+		"session_id":  sessionID,
+		"auth_method": authMethod,
 	}, ipAddress, userAgent, true, nil)
 
 	return &models.AuthResponse{
@@ -254,7 +395,8 @@ func (s *AuthService) Login(ctx context.Context, req *models.LoginRequest, ipAdd
 		RefreshToken: refreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int(s.jwtService.GetTokenExpiration().Seconds()),
-		User:         user.ToResponse(),
+		SessionID:    sessionID,
+		User:         s.authUserResponse(user),
 	}, nil
 }
 
@@ -264,7 +406,7 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenStr string,
 	var refreshToken models.RefreshToken
 	if err := s.db.WithContext(ctx).
 		Preload("User.Roles").
-		Where("token = ?", refreshTokenStr).
+		Where("token = ?", models.HashRefreshToken(refreshTokenStr)).
 		First(&refreshToken).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
 			return nil, fmt.Errorf("invalid refresh token")
@@ -272,8 +414,21 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenStr string,
 		return nil, fmt.Errorf("failed to find refresh token: %w", err)
 	}
 
+	// A rotated-out token being presented again means either the client or
+	// an attacker holds a stale copy; revoke the whole family so neither can
+	// keep refreshing. Once within the grace period it is instead taken as a
+	// client that never received the successor, and rotation starts over.
+	graced := false
+	if refreshToken.IsRevoked && s.wasRotatedOut(ctx, &refreshToken) {
+		if !s.claimRefreshGrace(ctx, &refreshToken) {
+			s.handleRefreshTokenReuse(ctx, &refreshToken, ipAddress, userAgent)
+			return nil, ErrRefreshTokenReuse
+		}
+		graced = true
+	}
+
 	// Check if token is valid
-	if !refreshToken.IsValid() {
+	if refreshToken.IsExpired() || (refreshToken.IsRevoked && !graced) {
 		return nil, fmt.Errorf("refresh token expired or revoked")
 	}
 
@@ -283,22 +438,35 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenStr string,
 		s.logger.Error("Failed to update refresh token usage", "error", err)
 	}
 
-	// Generate new access token
-	accessToken, err := s.jwtService.GenerateToken(&refreshToken.User)
+	// Generate new access token for the same client and login method the
+	// session started with
+	audience, err := s.clientAudience(refreshToken.ClientID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to generate access token: %w", err)
+		return nil, err
 	}
 
-	// Optionally generate new refresh token (token rotation)
-	newRefreshToken, err := s.createRefreshToken(ctx, refreshToken.UserID, ipAddress, userAgent)
+	accessToken, err := s.jwtService.GenerateTokenForClient(&refreshToken.User, refreshToken.AuthMethod, audience, ipAddress)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create new refresh token: %w", err)
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
 
-	// Revoke old refresh token
-	refreshToken.Revoke()
-	if err := s.db.WithContext(ctx).Save(&refreshToken).Error; err != nil {
-		s.logger.Error("Failed to revoke old refresh token", "error", err)
+	// Rotate the refresh token unless rotation is disabled, in which case
+	// the client keeps using the same token until it expires
+	newRefreshToken := refreshTokenStr
+	if s.config.RefreshTokenRotation {
+		// Revoke the old refresh token first, so it does not count against
+		// the user's refresh token limit when its successor is issued
+		refreshToken.Revoke()
+		rotatedAt := time.Now()
+		refreshToken.RotatedAt = &rotatedAt
+		if err := s.db.WithContext(ctx).Save(&refreshToken).Error; err != nil {
+			s.logger.Error("Failed to revoke old refresh token", "error", err)
+		}
+
+		newRefreshToken, err = s.createRefreshToken(ctx, refreshToken.UserID, refreshToken.FamilyID, refreshToken.AuthMethod, refreshToken.ClientID, ipAddress, userAgent)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create new refresh token: %w", err)
+		}
 	}
 
 	// Log token refresh
@@ -310,6 +478,8 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenStr string,
 	s.createAuditLog(ctx, &refreshToken.UserID, "user.token_refresh", "token", nil, map[string]interface{}{
 		"ip_address": ipAddress,
 		"user_agent": userAgent,
+		"rotated":    s.config.RefreshTokenRotation,
+		"grace":      graced,
 	}, ipAddress, userAgent, true, nil)
 
 	return &models.AuthResponse{
@@ -317,17 +487,30 @@ func (s *AuthService) RefreshToken(ctx context.Context, refreshTokenStr string,
 		RefreshToken: newRefreshToken,
 		TokenType:    "Bearer",
 		ExpiresIn:    int(s.jwtService.GetTokenExpiration().Seconds()),
-		User:         refreshToken.User.ToResponse(),
+		User:         s.authUserResponse(&refreshToken.User),
 	}, nil
 }
 
-// Logout logs out a user and revokes tokens
-func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, refreshTokenStr string) error {
+// Logout logs out a user and revokes tokens. accessClaims are the claims of
+// the access token used for the request, which is blacklisted until expiry.
+func (s *AuthService) Logout(ctx context.Context, userID uuid.UUID, accessClaims *auth.Claims, refreshTokenStr string) error {
+	// Blacklist the current access token. Without Redis it stays valid until
+	// it expires, but the session and refresh token are still revoked.
+	if accessClaims != nil && s.blacklist != nil {
+		if accessClaims.ID == "" {
+			s.logger.Warn("Access token has no ID and cannot be blacklisted", "user_id", userID)
+		} else if err := s.blacklist.BlacklistToken(accessClaims); errors.Is(err, auth.ErrBlacklistUnavailable) {
+			s.logger.Warn("Access token not blacklisted; it stays valid until it expires", "error", err, "user_id", userID)
+		} else if err != nil {
+			return fmt.Errorf("failed to revoke access token: %w", err)
+		}
+	}
+
 	// Revoke refresh token if provided
 	if refreshTokenStr != "" {
 		var refreshToken models.RefreshToken
 		if err := s.db.WithContext(ctx).
-			Where("token = ? AND user_id = ?", refreshTokenStr, userID).
+			Where("token = ? AND user_id = ?", models.HashRefreshToken(refreshTokenStr), userID).
 			First(&refreshToken).Error; err == nil {
 			refreshToken.Revoke()
 			s.db.WithContext(ctx).Save(&refreshToken)
@@ -372,14 +555,40 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req
 		return fmt.Errorf("failed to hash new password: %w", err)
 	}
 
+	// Lock the user row so concurrent changes are serialized, and only apply
+	// this change if the password it verified is still the current one
+	tx, err := s.userRepo.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var current models.User
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "password_hash").
+		First(&current, "id = ?", userID).Error; err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+	if current.PasswordHash != user.PasswordHash {
+		s.logger.Warn("Password change superseded by a concurrent change", "user_id", userID)
+		s.createAuditLog(ctx, &userID, "user.password_change", "user", &userID, nil, "", "", false, nil)
+		return ErrPasswordChangeSuperseded
+	}
+
 	// Update password
-	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+	if err := s.userRepo.WithTransaction(tx).UpdatePassword(ctx, userID, hashedPassword); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
-	// Revoke all refresh tokens for the user
-	if err := s.revokeAllUserTokens(ctx, userID); err != nil {
-		s.logger.Error("Failed to revoke user tokens after password change", "error", err, "user_id", userID)
+	// Revoke all refresh tokens for the user along with the change
+	if err := tx.Model(&models.RefreshToken{}).
+		Where("user_id = ?", userID).
+		Update("is_revoked", true).Error; err != nil {
+		return fmt.Errorf("failed to revoke user tokens: %w", err)
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return fmt.Errorf("failed to commit password change: %w", err)
 	}
 
 	// Delete user sessions
@@ -398,6 +607,10 @@ func (s *AuthService) ChangePassword(ctx context.Context, userID uuid.UUID, req
 
 // ForgotPassword initiates password reset process
 func (s *AuthService) ForgotPassword(ctx context.Context, req *models.ForgotPasswordRequest, ipAddress string) error {
+	// Known and unknown emails do different amounts of work; pad both to
+	// the same duration so timing does not reveal which one this was
+	defer padDuration(ctx, time.Now(), time.Duration(s.config.PasswordResetMinDurationMs)*time.Millisecond)
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err != nil {
@@ -438,13 +651,20 @@ func (s *AuthService) ForgotPassword(ctx context.Context, req *models.ForgotPass
 
 // ResetPassword resets a user's password using a reset token
 func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswordRequest, ipAddress string) error {
+	// Throttle IPs that keep submitting invalid tokens
+	if s.resetAttemptsExceeded(ctx, ipAddress) {
+		s.logger.Warn("Password reset attempts exceeded", "ip_address", ipAddress)
+		return ErrTooManyResetAttempts
+	}
+
 	// Find password reset token
 	var resetToken models.PasswordReset
 	if err := s.db.WithContext(ctx).
 		Preload("User").
-		Where("token = ?", req.Token).
+		Where("token = ?", models.HashPasswordResetToken(req.Token)).
 		First(&resetToken).Error; err != nil {
 		if err == gorm.ErrRecordNotFound {
+			s.recordFailedResetAttempt(ctx, ipAddress)
 			return fmt.Errorf("invalid or expired reset token")
 		}
 		return fmt.Errorf("failed to find reset token: %w", err)
@@ -452,6 +672,7 @@ func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswo
 
 	// Check if token is valid
 	if !resetToken.IsValid() {
+		s.recordFailedResetAttempt(ctx, ipAddress)
 		return fmt.Errorf("reset token expired or already used")
 	}
 
@@ -467,18 +688,15 @@ func (s *AuthService) ResetPassword(ctx context.Context, req *models.ResetPasswo
 	}
 
 	// Begin transaction
-	tx := s.db.WithContext(ctx).Begin()
+	tx, err := s.userRepo.BeginTransaction(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
 	defer tx.Rollback()
 
-	// Update password
-	if err := tx.Model(&models.User{}).
-		Where("id = ?", resetToken.UserID).
-		Updates(map[string]interface{}{
-			"password_hash":       hashedPassword,
-			"password_changed_at": time.Now(),
-			"failed_login_count":  0,
-			"locked_until":        nil,
-		}).Error; err != nil {
+	// Update password through the repository, so cached copies of the user
+	// are evicted once the transaction commits
+	if err := s.userRepo.WithTransaction(tx).UpdatePassword(ctx, resetToken.UserID, hashedPassword); err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
@@ -565,33 +783,174 @@ func (s *AuthService) VerifyEmail(ctx context.Context, req *models.VerifyEmailRe
 	return nil
 }
 
+// ActivateUser activates a user account, allowing it to log in. It is used
+// by admins to approve accounts created while activation is required.
+func (s *AuthService) ActivateUser(ctx context.Context, userID, adminID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.IsActive {
+		return nil
+	}
+
+	if err := s.userRepo.ActivateUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to activate user: %w", err)
+	}
+
+	// Log activation
+	s.logger.Info("User activated", "user_id", userID, "admin_id", adminID)
+
+	// Create audit log
+	s.createAuditLog(ctx, &adminID, "user.activate", "user", &userID, nil, "", "", true, nil)
+
+	return nil
+}
+
 // Helper functions
 
-func (s *AuthService) assignDefaultRole(ctx context.Context, userRepo interfaces.UserRepository, userID uuid.UUID) error {
+// assignDefaultRole gives a new user the default role. db and userRepo must
+// share the caller's transaction.
+func (s *AuthService) assignDefaultRole(ctx context.Context, db *gorm.DB, userRepo interfaces.UserRepository, userID uuid.UUID) error {
 	// Find default user role
 	var role models.Role
-	if err := s.db.WithContext(ctx).Where("name = ?", "user").First(&role).Error; err != nil {
+	if err := db.WithContext(ctx).Where("name = ?", "user").First(&role).Error; err != nil {
 		return fmt.Errorf("default user role not found: %w", err)
 	}
 
-	return userRepo.AssignRole(ctx, userID, role.ID)
+	return userRepo.AssignRole(ctx, userID, role.ID, nil, nil)
+}
+
+// authUserResponse builds the user included in login and refresh responses,
+// leaving out roles and permissions when configured for slim responses
+func (s *AuthService) authUserResponse(user *models.User) models.UserResponse {
+	response := user.ToResponse()
+	if !s.config.LoginResponseIncludeRoles {
+		response.Roles = nil
+	}
+	return response
 }
 
-func (s *AuthService) createRefreshToken(ctx context.Context, userID uuid.UUID, ipAddress, userAgent string) (string, error) {
+// clientAudience returns the token audience configured for a client. An
+// empty client ID selects no audience.
+func (s *AuthService) clientAudience(clientID string) (string, error) {
+	if clientID == "" {
+		return "", nil
+	}
+
+	audience, ok := s.config.JWTClientAudiences[clientID]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownClient, clientID)
+	}
+	return audience, nil
+}
+
+// createRefreshToken issues a refresh token in the given family; a nil
+// family ID starts a new one. authMethod is the method the family's login
+// used, which access tokens refreshed with it keep.
+func (s *AuthService) createRefreshToken(ctx context.Context, userID, familyID uuid.UUID, authMethod, clientID, ipAddress, userAgent string) (string, error) {
+	return s.createRefreshTokenIn(ctx, s.db, userID, familyID, authMethod, clientID, ipAddress, userAgent)
+}
+
+// createRefreshTokenIn is createRefreshToken run against db, which may be a
+// transaction
+func (s *AuthService) createRefreshTokenIn(ctx context.Context, db *gorm.DB, userID, familyID uuid.UUID, authMethod, clientID, ipAddress, userAgent string) (string, error) {
 	refreshToken := &models.RefreshToken{
-		UserID:    userID,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour), // 7 days
-		IPAddress: ipAddress,
-		UserAgent: userAgent,
+		UserID:     userID,
+		FamilyID:   familyID,
+		AuthMethod: authMethod,
+		ClientID:   clientID,
+		ExpiresAt:  time.Now().Add(7 * 24 * time.Hour), // 7 days
+		IPAddress:  ipAddress,
+		UserAgent:  userAgent,
 	}
 
-	if err := s.db.WithContext(ctx).Create(refreshToken).Error; err != nil {
+	if err := db.WithContext(ctx).Create(refreshToken).Error; err != nil {
 		return "", fmt.Errorf("failed to create refresh token: %w", err)
 	}
 
+	if limit := s.config.MaxRefreshTokensPerUser; limit > 0 {
+		if err := s.revokeExcessRefreshTokens(ctx, db, userID, limit); err != nil {
+			s.logger.Error("Failed to enforce refresh token limit", "error", err, "user_id", userID)
+		}
+	}
+
 	return refreshToken.Token, nil
 }
 
+// revokeExcessRefreshTokens revokes a user's oldest active refresh tokens
+// so that at most limit remain
+func (s *AuthService) revokeExcessRefreshTokens(ctx context.Context, db *gorm.DB, userID uuid.UUID, limit int) error {
+	var activeIDs []uuid.UUID
+	if err := db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("user_id = ? AND is_revoked = ? AND expires_at > ?", userID, false, time.Now()).
+		Order("created_at DESC").
+		Pluck("id", &activeIDs).Error; err != nil {
+		return fmt.Errorf("failed to list refresh tokens: %w", err)
+	}
+
+	if len(activeIDs) <= limit {
+		return nil
+	}
+
+	return db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id IN ?", activeIDs[limit:]).
+		Update("is_revoked", true).Error
+}
+
+// wasRotatedOut reports whether a revoked refresh token was replaced by a
+// newer token of its family, as opposed to revoked by logout or the refresh
+// token limit. Tokens without a family cannot tell and count as rotated out.
+func (s *AuthService) wasRotatedOut(ctx context.Context, refreshToken *models.RefreshToken) bool {
+	if refreshToken.FamilyID == uuid.Nil {
+		return true
+	}
+
+	var successors int64
+	if err := s.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND id <> ? AND created_at >= ?", refreshToken.FamilyID, refreshToken.ID, refreshToken.CreatedAt).
+		Count(&successors).Error; err != nil {
+		s.logger.Error("Failed to look up refresh token successors", "error", err, "family_id", refreshToken.FamilyID)
+		return true
+	}
+	return successors > 0
+}
+
+// handleRefreshTokenReuse revokes every refresh token descended from the
+// same login as a reused token and signs the user out everywhere. Tokens
+// issued before families existed have no family, so all of the user's
+// tokens are revoked instead.
+func (s *AuthService) handleRefreshTokenReuse(ctx context.Context, refreshToken *models.RefreshToken, ipAddress, userAgent string) {
+	query := s.db.WithContext(ctx).Model(&models.RefreshToken{}).Where("user_id = ?", refreshToken.UserID)
+	if refreshToken.FamilyID != uuid.Nil {
+		query = query.Where("family_id = ?", refreshToken.FamilyID)
+	}
+	if err := query.Update("is_revoked", true).Error; err != nil {
+		s.logger.Error("Failed to revoke refresh token family", "error", err, "user_id", refreshToken.UserID)
+	}
+
+	if err := s.sessionService.DeleteUserSessions(ctx, refreshToken.UserID); err != nil {
+		s.logger.Error("Failed to delete user sessions after refresh token reuse", "error", err, "user_id", refreshToken.UserID)
+	}
+
+	s.logger.Warn("Refresh token reuse detected",
+		"user_id", refreshToken.UserID,
+		"family_id", refreshToken.FamilyID,
+		"ip_address", ipAddress)
+
+	s.createAuditLog(ctx, &refreshToken.UserID, "user.token_reuse", "token", &refreshToken.FamilyID, map[string]interface{}{
+		"ip_address": ipAddress,
+		"user_agent": userAgent,
+	}, ipAddress, userAgent, false, &[]string{ErrRefreshTokenReuse.Error()}[0])
+}
+
 func (s *AuthService) revokeAllUserTokens(ctx context.Context, userID uuid.UUID) error {
 	return s.db.WithContext(ctx).
 		Model(&models.RefreshToken{}).
@@ -611,6 +970,9 @@ func (s *AuthService) createAuditLog(ctx context.Context, userID *uuid.UUID, act
 		Success:      success,
 		ErrorMessage: errorMessage,
 	}
+	if auditLog.TruncateDetails(s.config.AuditMaxDetailBytes) {
+		s.logger.Warn("Audit log details truncated", "action", action, "max_bytes", s.config.AuditMaxDetailBytes)
+	}
 
 	if err := s.db.WithContext(ctx).Create(auditLog).Error; err != nil {
 		s.logger.Error("Failed to create audit log", "error", err)
@@ -627,6 +989,11 @@ func (s *AuthService) sendPasswordResetEmail(ctx context.Context, user *models.U
 	s.logger.Info("Password reset email would be sent", "user_id", user.ID, "email", user.Email)
 }
 
+func (s *AuthService) sendLockoutEmail(ctx context.Context, user *models.User) {
+	// Implement email sending logic
+	s.logger.Info("Account lockout email would be sent", "user_id", user.ID, "email", user.Email)
+}
+
 func extractRoleNames(roles []models.Role) []string {
 	roleNames := make([]string, len(roles))
 	for i, role := range roles {
@@ -634,18 +1001,3 @@ func extractRoleNames(roles []models.Role) []string {
 	}
 	return roleNames
 }
-
-func extractPermissions(roles []models.Role) []string {
-	permissionSet := make(map[string]bool)
-	for _, role := range roles {
-		for _, permission := range role.Permissions {
-			permissionSet[permission] = true
-		}
-	}
-
-	permissions := make([]string, 0, len(permissionSet))
-	for permission := range permissionSet {
-		permissions = append(permissions, permission)
-	}
-	return permissions
-}
\ No newline at end of file
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"app/internal/models"
+)
+
+// recordFailedLogin writes the audit entry for a failed login. When audit
+// sampling is configured, only the first FailedLoginAuditMaxPerWindow failures
+// from an IP within the window are recorded individually; the rest are folded
+// into a single summary entry whose suppressed_count, and per-user counts in
+// suppressed_by_user, are kept up to date so login stats still see them.
+func (s *AuthService) recordFailedLogin(ctx context.Context, userID *uuid.UUID, details map[string]interface{}, ipAddress, userAgent, reason string) {
+	windowSeconds := s.config.FailedLoginAuditWindowSeconds
+	if windowSeconds <= 0 || s.redisClient == nil {
+		s.createAuditLog(ctx, userID, "user.login", "user", userID, details, ipAddress, userAgent, false, &reason)
+		return
+	}
+
+	window := time.Duration(windowSeconds) * time.Second
+	countKey := fmt.Sprintf("%saudit:failed_login:%s:%d", s.config.RedisKeyPrefix, ipAddress, time.Now().Unix()/int64(windowSeconds))
+
+	pipe := s.redisClient.TxPipeline()
+	incrCmd := pipe.Incr(ctx, countKey)
+	pipe.Expire(ctx, countKey, window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		// Fail open: an unsampled entry is better than a missing one
+		s.logger.Error("Failed to sample failed login audit", "error", err)
+		s.createAuditLog(ctx, userID, "user.login", "user", userID, details, ipAddress, userAgent, false, &reason)
+		return
+	}
+
+	count := incrCmd.Val()
+	maxPerWindow := int64(s.config.FailedLoginAuditMaxPerWindow)
+	if count <= maxPerWindow {
+		s.createAuditLog(ctx, userID, "user.login", "user", userID, details, ipAddress, userAgent, false, &reason)
+		return
+	}
+
+	suppressed := count - maxPerWindow
+	summaryKey := countKey + ":summary"
+
+	var userSuppressed int64
+	usersKey := summaryKey + ":users"
+	if userID != nil {
+		pipe := s.redisClient.TxPipeline()
+		userIncrCmd := pipe.HIncrBy(ctx, usersKey, userID.String(), 1)
+		pipe.Expire(ctx, usersKey, window)
+		if _, err := pipe.Exec(ctx); err != nil {
+			s.logger.Error("Failed to count suppressed failed login", "error", err)
+		}
+		userSuppressed = userIncrCmd.Val()
+	}
+
+	if suppressed == 1 {
+		suppressedByUser := map[string]interface{}{}
+		if userID != nil && userSuppressed > 0 {
+			suppressedByUser[userID.String()] = userSuppressed
+		}
+		summary := &models.AuditLog{
+			Action:   "user.login_failed_summary",
+			Resource: "user",
+			Details: map[string]interface{}{
+				"ip_address":         ipAddress,
+				"window_seconds":     windowSeconds,
+				"max_per_window":     maxPerWindow,
+				"suppressed_count":   suppressed,
+				"suppressed_by_user": suppressedByUser,
+			},
+			IPAddress:    ipAddress,
+			UserAgent:    userAgent,
+			Success:      false,
+			ErrorMessage: &[]string{"repeated failed logins suppressed"}[0],
+		}
+		if err := s.db.WithContext(ctx).Create(summary).Error; err != nil {
+			s.logger.Error("Failed to create audit log", "error", err)
+			return
+		}
+		if err := s.redisClient.Set(ctx, summaryKey, summary.ID.String(), window).Err(); err != nil {
+			s.logger.Error("Failed to store failed login summary", "error", err)
+		}
+		return
+	}
+
+	// The count is best effort: failures racing the summary's creation are
+	// still suppressed but may not be reflected in suppressed_count
+	summaryID, err := s.redisClient.Get(ctx, summaryKey).Result()
+	if err != nil {
+		if err != redis.Nil {
+			s.logger.Error("Failed to get failed login summary", "error", err)
+		}
+		return
+	}
+
+	update := gorm.Expr("jsonb_set(details, '{suppressed_count}', to_jsonb(?::bigint))", suppressed)
+	if userID != nil && userSuppressed > 0 {
+		update = gorm.Expr("jsonb_set(jsonb_set(details, '{suppressed_count}', to_jsonb(?::bigint)), ARRAY['suppressed_by_user', ?::text], to_jsonb(?::bigint))",
+			suppressed, userID.String(), userSuppressed)
+	}
+
+	if err := s.db.WithContext(ctx).
+		Model(&models.AuditLog{}).
+		Where("id = ?", summaryID).
+		Update("details", update).Error; err != nil {
+		s.logger.Error("Failed to update failed login summary", "error", err)
+	}
+}
@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"app/internal/models"
+)
+
+// ErrInvitationRequired is returned when registration is invite-only and no
+// invitation token was given
+var ErrInvitationRequired = errors.New("invitation required")
+
+// ErrInvalidInvitation is returned when an invitation token is unknown,
+// expired, already used or issued for a different email
+var ErrInvalidInvitation = errors.New("invalid or expired invitation")
+
+// CreateInvitation issues an invitation to register with email. It expires
+// after the configured invitation TTL.
+func (s *AuthService) CreateInvitation(ctx context.Context, email string, inviterID uuid.UUID) (*models.Invitation, error) {
+	email, err := s.NormalizeEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	invitation := &models.Invitation{
+		Email:     email,
+		InvitedBy: inviterID,
+	}
+	if s.config.InvitationTTLHours > 0 {
+		invitation.ExpiresAt = time.Now().Add(time.Duration(s.config.InvitationTTLHours) * time.Hour)
+	}
+
+	if err := s.db.WithContext(ctx).Create(invitation).Error; err != nil {
+		return nil, fmt.Errorf("failed to create invitation: %w", err)
+	}
+
+	s.createAuditLog(ctx, &inviterID, "invitation.create", "invitation", &invitation.ID, map[string]interface{}{
+		"email": email,
+	}, "", "", true, nil)
+
+	return invitation, nil
+}
+
+// redeemInvitation marks the invitation with token as used by userID. The
+// row is locked for the rest of tx, so concurrent registrations cannot both
+// redeem it.
+func (s *AuthService) redeemInvitation(tx *gorm.DB, token, email string, userID uuid.UUID) error {
+	var invitation models.Invitation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("token = ?", models.HashInvitationToken(token)).
+		First(&invitation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidInvitation
+		}
+		return fmt.Errorf("failed to find invitation: %w", err)
+	}
+
+	if !invitation.IsValid() || !strings.EqualFold(invitation.Email, email) {
+		return ErrInvalidInvitation
+	}
+
+	invitation.MarkAsUsed(userID)
+	if err := tx.Model(&invitation).
+		Updates(map[string]interface{}{
+			"is_used":    true,
+			"used_at":    invitation.UsedAt,
+			"used_by":    invitation.UsedBy,
+			"updated_at": invitation.UpdatedAt,
+		}).Error; err != nil {
+		return fmt.Errorf("failed to mark invitation used: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// claimLockoutNotification reports whether a lockout notification should be
+// sent for the account. Only the first lockout within the configured cooldown
+// claims the notification; later ones are suppressed until it expires. Redis
+// errors fail open so a lockout is never silently unannounced.
+func (s *AuthService) claimLockoutNotification(ctx context.Context, userID uuid.UUID) bool {
+	cooldown := time.Duration(s.config.LockoutNotificationCooldownSeconds) * time.Second
+	if cooldown <= 0 || s.redisClient == nil {
+		return true
+	}
+
+	key := fmt.Sprintf("%slockout_notification:%s", s.config.RedisKeyPrefix, userID)
+	claimed, err := s.redisClient.SetNX(ctx, key, time.Now().Unix(), cooldown).Result()
+	if err != nil {
+		s.logger.Error("Failed to check lockout notification cooldown", "error", err, "user_id", userID)
+		return true
+	}
+
+	return claimed
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// ErrTooManyResetAttempts is returned when an IP has submitted too many
+// invalid password reset tokens within the configured window
+var ErrTooManyResetAttempts = errors.New("too many password reset attempts")
+
+// resetAttemptsKey returns the Redis key counting invalid reset attempts from an IP
+func (s *AuthService) resetAttemptsKey(ipAddress string) string {
+	return fmt.Sprintf("%spassword_reset:attempts:%s", s.config.RedisKeyPrefix, ipAddress)
+}
+
+// resetAttemptsExceeded reports whether the IP has used up its invalid reset
+// attempts for the current window. Redis errors fail open so a Redis outage
+// does not block legitimate resets.
+func (s *AuthService) resetAttemptsExceeded(ctx context.Context, ipAddress string) bool {
+	if s.config.PasswordResetMaxAttempts <= 0 || s.redisClient == nil {
+		return false
+	}
+
+	count, err := s.redisClient.Get(ctx, s.resetAttemptsKey(ipAddress)).Int()
+	if err != nil {
+		if err != redis.Nil {
+			s.logger.Error("Failed to get password reset attempts", "error", err, "ip_address", ipAddress)
+		}
+		return false
+	}
+
+	return count >= s.config.PasswordResetMaxAttempts
+}
+
+// recordFailedResetAttempt counts an invalid reset token submitted by the IP.
+// The window starts with the first failure and is not extended by later ones.
+func (s *AuthService) recordFailedResetAttempt(ctx context.Context, ipAddress string) {
+	if s.config.PasswordResetMaxAttempts <= 0 || s.redisClient == nil {
+		return
+	}
+
+	key := s.resetAttemptsKey(ipAddress)
+	window := time.Duration(s.config.PasswordResetWindowSeconds) * time.Second
+
+	pipe := s.redisClient.TxPipeline()
+	pipe.SetNX(ctx, key, 0, window)
+	pipe.Incr(ctx, key)
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Error("Failed to record password reset attempt", "error", err, "ip_address", ipAddress)
+	}
+}
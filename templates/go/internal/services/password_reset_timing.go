@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"time"
+)
+
+// padDuration blocks until at least d has passed since start, or ctx is
+// done. The padding only hides timing differences while the real work stays
+// below d, so d should sit comfortably above the slow path's latency.
+func padDuration(ctx context.Context, start time.Time, d time.Duration) {
+	remaining := d - time.Since(start)
+	if remaining <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(remaining)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
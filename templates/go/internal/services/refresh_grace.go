@@ -0,0 +1,45 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"app/internal/models"
+)
+
+// claimRefreshGrace reports whether a rotated-out refresh token may be used
+// once more because it was rotated within the configured grace period. The
+// claim is atomic, so only one request can use the grace, and it revokes the
+// token's successors: the client presenting the old token never stored them.
+func (s *AuthService) claimRefreshGrace(ctx context.Context, refreshToken *models.RefreshToken) bool {
+	grace := time.Duration(s.config.RefreshTokenGraceSeconds) * time.Second
+	if grace <= 0 || refreshToken.RotatedAt == nil || refreshToken.GraceUsed {
+		return false
+	}
+	if time.Since(*refreshToken.RotatedAt) > grace {
+		return false
+	}
+
+	result := s.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("id = ? AND grace_used = ?", refreshToken.ID, false).
+		Update("grace_used", true)
+	if result.Error != nil {
+		s.logger.Error("Failed to claim refresh token grace", "error", result.Error, "token_id", refreshToken.ID)
+		return false
+	}
+	if result.RowsAffected == 0 {
+		return false
+	}
+	refreshToken.GraceUsed = true
+
+	if err := s.db.WithContext(ctx).
+		Model(&models.RefreshToken{}).
+		Where("family_id = ? AND id <> ? AND is_revoked = ?", refreshToken.FamilyID, refreshToken.ID, false).
+		Update("is_revoked", true).Error; err != nil {
+		s.logger.Error("Failed to revoke refresh token successors", "error", err, "family_id", refreshToken.FamilyID)
+		return false
+	}
+
+	return true
+}
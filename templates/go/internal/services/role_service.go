@@ -0,0 +1,117 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/utils"
+)
+
+// ErrRoleNotFound is returned when an operation targets a role that does not exist
+var ErrRoleNotFound = errors.New("role not found")
+
+// RoleService handles role management logic
+type RoleService struct {
+	roleRepo  interfaces.RoleRepository
+	auditRepo interfaces.AuditLogRepository
+	logger    *utils.Logger
+}
+
+// NewRoleService creates a new role service
+func NewRoleService(roleRepo interfaces.RoleRepository, auditRepo interfaces.AuditLogRepository, logger *utils.Logger) *RoleService {
+	return &RoleService{
+		roleRepo:  roleRepo,
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+// ListRoles returns a page of roles matching the filters, including user counts
+func (s *RoleService) ListRoles(ctx context.Context, filters interfaces.RoleFilters, page, pageSize int) (*models.Paginated[models.RoleResponse], error) {
+	roles, total, err := s.roleRepo.List(ctx, filters, models.Offset(page, pageSize), pageSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	roleIDs := make([]uuid.UUID, len(roles))
+	for i, role := range roles {
+		roleIDs[i] = role.ID
+	}
+
+	userCounts, err := s.roleRepo.CountUsers(ctx, roleIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count role users: %w", err)
+	}
+
+	items := make([]models.RoleResponse, len(roles))
+	for i, role := range roles {
+		items[i] = role.ToResponse()
+		items[i].UserCount = int(userCounts[role.ID])
+	}
+
+	result := models.NewPaginated(items, total, page, pageSize)
+	return &result, nil
+}
+
+// UpdateRole applies the requested changes to a role and reports which
+// permissions were added and removed. The diff is also recorded in the audit log.
+func (s *RoleService) UpdateRole(ctx context.Context, roleID uuid.UUID, req *models.RoleUpdateRequest, adminID uuid.UUID) (*models.RoleUpdateResponse, error) {
+	role, err := s.roleRepo.GetByID(ctx, roleID)
+	if err != nil {
+		return nil, ErrRoleNotFound
+	}
+
+	before := append([]string(nil), role.Permissions...)
+
+	if req.Name != nil {
+		role.Name = *req.Name
+	}
+	if req.Description != nil {
+		role.Description = *req.Description
+	}
+	if req.Permissions != nil {
+		role.Permissions = req.Permissions
+	}
+	if req.IsActive != nil {
+		role.IsActive = *req.IsActive
+	}
+
+	if err := s.roleRepo.Update(ctx, role); err != nil {
+		return nil, fmt.Errorf("failed to update role: %w", err)
+	}
+
+	diff := models.DiffPermissions(before, role.Permissions)
+
+	// Log role update
+	s.logger.Info("Role updated",
+		"role_id", roleID,
+		"admin_id", adminID,
+		"permissions_added", diff.Added,
+		"permissions_removed", diff.Removed)
+
+	// Create audit log
+	auditLog := &models.AuditLog{
+		UserID:     &adminID,
+		Action:     "role.update",
+		Resource:   "role",
+		ResourceID: &roleID,
+		Details: map[string]interface{}{
+			"permissions_added":   diff.Added,
+			"permissions_removed": diff.Removed,
+		},
+		Success: true,
+	}
+	if err := s.auditRepo.Create(ctx, auditLog); err != nil {
+		s.logger.Error("Failed to create audit log", "error", err)
+	}
+
+	return &models.RoleUpdateResponse{
+		Role:              role.ToResponse(),
+		PermissionChanges: diff,
+	}, nil
+}
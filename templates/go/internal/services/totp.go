@@ -0,0 +1,346 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"app/internal/auth"
+	"app/internal/models"
+)
+
+// ErrTOTPAlreadyEnabled is returned when enrolling a user who already has
+// two-factor authentication enabled
+var ErrTOTPAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+
+// ErrTOTPNotSetUp is returned when enabling two-factor authentication
+// before a secret was generated
+var ErrTOTPNotSetUp = errors.New("two-factor authentication has not been set up")
+
+// ErrTOTPNotEnabled is returned when an operation needs two-factor
+// authentication and the user has not enabled it
+var ErrTOTPNotEnabled = errors.New("two-factor authentication is not enabled")
+
+// ErrInvalidTOTPCode is returned when a TOTP or recovery code is wrong or
+// has expired
+var ErrInvalidTOTPCode = errors.New("invalid two-factor code")
+
+// ErrTOTPCodeReused is returned when a TOTP code that was already accepted
+// is presented again
+var ErrTOTPCodeReused = errors.New("two-factor code has already been used")
+
+// ErrInvalidMFAChallenge is returned when a login challenge is unknown,
+// expired, already completed or has had too many wrong codes
+var ErrInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+
+const (
+	// defaultMFAChallengeTTLSeconds applies when the config leaves it unset
+	defaultMFAChallengeTTLSeconds = 300
+	// mfaChallengeMaxAttempts wrong codes invalidate a challenge
+	mfaChallengeMaxAttempts = 5
+	// recoveryCodeCount recovery codes are issued at a time
+	recoveryCodeCount = 10
+)
+
+// SetupTOTP starts two-factor enrolment by generating a new secret for the
+// user. Two-factor authentication stays off until EnableTOTP confirms the
+// secret with a code.
+func (s *AuthService) SetupTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPSetupResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+
+	secret, err := s.totp.GenerateSecret(user)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.userRepo.SetTOTPSecret(ctx, userID, secret); err != nil {
+		return nil, err
+	}
+
+	s.createAuditLog(ctx, &userID, "user.totp_setup", "user", &userID, nil, "", "", true, nil)
+
+	return &models.TOTPSetupResponse{
+		Secret:    secret,
+		QRCodeURL: s.totp.QRCodeURL(user, secret),
+	}, nil
+}
+
+// EnableTOTP turns on two-factor authentication once code shows the user's
+// authenticator holds the secret from SetupTOTP. It returns the user's
+// recovery codes, which are not shown again.
+func (s *AuthService) EnableTOTP(ctx context.Context, userID uuid.UUID, code string) (*models.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if user.TOTPEnabled {
+		return nil, ErrTOTPAlreadyEnabled
+	}
+	if user.TOTPSecret == "" {
+		return nil, ErrTOTPNotSetUp
+	}
+
+	step, ok := s.totp.Match(user.TOTPSecret, strings.TrimSpace(code))
+	if !ok {
+		return nil, ErrInvalidTOTPCode
+	}
+
+	tx, err := s.userRepo.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	userRepoTx := s.userRepo.WithTransaction(tx)
+
+	// The enrolment code cannot be used again to log in
+	if _, err := userRepoTx.ConsumeTOTPStep(ctx, userID, step); err != nil {
+		return nil, err
+	}
+	if err := userRepoTx.EnableTOTP(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(ctx, tx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	s.logger.Info("Two-factor authentication enabled", "user_id", userID)
+	s.createAuditLog(ctx, &userID, "user.totp_enable", "user", &userID, nil, "", "", true, nil)
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes, used or not,
+// with a new set
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID) (*models.RecoveryCodesResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+	if !user.TOTPEnabled {
+		return nil, ErrTOTPNotEnabled
+	}
+
+	var codes []string
+	if err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		codes, err = s.replaceRecoveryCodes(ctx, tx, userID)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	s.createAuditLog(ctx, &userID, "user.recovery_codes_regenerate", "user", &userID, nil, "", "", true, nil)
+
+	return &models.RecoveryCodesResponse{RecoveryCodes: codes}, nil
+}
+
+// VerifyTOTP completes a login that was answered with an MFA challenge.
+// code is either a TOTP code or an unused recovery code. Wrong codes count
+// towards the account lockout like wrong passwords do.
+func (s *AuthService) VerifyTOTP(ctx context.Context, challengeToken, code string) (*models.AuthResponse, error) {
+	var challenge models.MFAChallenge
+	if err := s.db.WithContext(ctx).
+		Where("token = ?", models.HashMFAChallengeToken(challengeToken)).
+		First(&challenge).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, fmt.Errorf("failed to find MFA challenge: %w", err)
+	}
+	if !challenge.IsValid() || challenge.Attempts >= mfaChallengeMaxAttempts {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	user, err := s.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, ErrInvalidMFAChallenge
+	}
+	s.resetExpiredLockout(ctx, user)
+
+	// The account may have been locked or disabled since the password was checked
+	if !user.CanLogin() || !user.TOTPEnabled {
+		if user.IsLocked() && user.IsActive && user.IsVerified {
+			return nil, &AccountLockedError{LockedUntil: *user.LockedUntil}
+		}
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	// Claim the challenge and spend the code together, so that a challenge
+	// completes a single login and a code is only spent on a login it completes
+	tx, err := s.userRepo.BeginTransaction(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := tx.WithContext(ctx).
+		Model(&models.MFAChallenge{}).
+		Where("id = ? AND used_at IS NULL", challenge.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to complete MFA challenge: %w", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidMFAChallenge
+	}
+
+	factor, err := s.verifySecondFactor(ctx, tx, user, strings.TrimSpace(code))
+	if err != nil {
+		// Release the challenge before counting the attempt against it
+		tx.Rollback()
+
+		if updateErr := s.db.WithContext(ctx).
+			Model(&models.MFAChallenge{}).
+			Where("id = ?", challenge.ID).
+			UpdateColumn("attempts", gorm.Expr("attempts + 1")).Error; updateErr != nil {
+			s.logger.Error("Failed to count MFA challenge attempt", "error", updateErr, "challenge_id", challenge.ID)
+		}
+		s.countFailedAttempt(ctx, user, challenge.IPAddress, challenge.UserAgent)
+
+		s.recordFailedLogin(ctx, &user.ID, map[string]interface{}{
+			"ip_address": challenge.IPAddress,
+			"user_agent": challenge.UserAgent,
+		}, challenge.IPAddress, challenge.UserAgent, err.Error())
+
+		return nil, err
+	}
+
+	if err := tx.Commit().Error; err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	if factor == "recovery_code" {
+		s.createAuditLog(ctx, &user.ID, "user.recovery_code_use", "user", &user.ID, map[string]interface{}{
+			"ip_address": challenge.IPAddress,
+			"user_agent": challenge.UserAgent,
+		}, challenge.IPAddress, challenge.UserAgent, true, nil)
+	}
+
+	s.resetFailedAttempts(ctx, user)
+
+	return s.completeLogin(ctx, user, auth.AuthMethodTOTP, challenge.ClientID, challenge.IPAddress, challenge.UserAgent)
+}
+
+// createMFAChallenge answers a correct password from a user with two-factor
+// authentication with a challenge to complete the login with VerifyTOTP
+func (s *AuthService) createMFAChallenge(ctx context.Context, user *models.User, clientID, ipAddress, userAgent string) (*models.AuthResponse, error) {
+	ttlSeconds := s.config.MFAChallengeTTLSeconds
+	if ttlSeconds <= 0 {
+		ttlSeconds = defaultMFAChallengeTTLSeconds
+	}
+
+	challenge := &models.MFAChallenge{
+		UserID:    user.ID,
+		ClientID:  clientID,
+		IPAddress: ipAddress,
+		UserAgent: userAgent,
+		ExpiresAt: time.Now().Add(time.Duration(ttlSeconds) * time.Second),
+	}
+	if err := s.db.WithContext(ctx).Create(challenge).Error; err != nil {
+		return nil, fmt.Errorf("failed to create MFA challenge: %w", err)
+	}
+
+	return &models.AuthResponse{
+		MFARequired:    true,
+		ChallengeToken: challenge.Token,
+		ExpiresIn:      ttlSeconds,
+	}, nil
+}
+
+// verifySecondFactor checks code as a TOTP code or, failing the format, as
+// a recovery code, consuming it either way. It returns which was used.
+func (s *AuthService) verifySecondFactor(ctx context.Context, tx *gorm.DB, user *models.User, code string) (string, error) {
+	if !isTOTPCode(code) {
+		used, err := s.consumeRecoveryCode(ctx, tx, user.ID, code)
+		if err != nil {
+			return "", err
+		}
+		if !used {
+			return "", ErrInvalidTOTPCode
+		}
+		return "recovery_code", nil
+	}
+
+	step, ok := s.totp.Match(user.TOTPSecret, code)
+	if !ok {
+		return "", ErrInvalidTOTPCode
+	}
+
+	consumed, err := s.userRepo.WithTransaction(tx).ConsumeTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		return "", err
+	}
+	if !consumed {
+		return "", ErrTOTPCodeReused
+	}
+	return "totp", nil
+}
+
+// consumeRecoveryCode marks the user's unused recovery code as used within
+// tx and reports whether there was one
+func (s *AuthService) consumeRecoveryCode(ctx context.Context, tx *gorm.DB, userID uuid.UUID, code string) (bool, error) {
+	result := tx.WithContext(ctx).
+		Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, models.HashRecoveryCode(code)).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return false, fmt.Errorf("failed to consume recovery code: %w", result.Error)
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// replaceRecoveryCodes deletes the user's recovery codes and stores a new
+// set within tx, returning the codes in plain text
+func (s *AuthService) replaceRecoveryCodes(ctx context.Context, tx *gorm.DB, userID uuid.UUID) ([]string, error) {
+	if err := tx.WithContext(ctx).Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	rows := make([]models.RecoveryCode, recoveryCodeCount)
+	for i := range codes {
+		code, err := models.GenerateRecoveryCode()
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+		}
+		codes[i] = code
+		rows[i] = models.RecoveryCode{UserID: userID, CodeHash: models.HashRecoveryCode(code)}
+	}
+
+	if err := tx.WithContext(ctx).Create(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to store recovery codes: %w", err)
+	}
+
+	return codes, nil
+}
+
+// isTOTPCode reports whether code has the shape of a TOTP code rather than
+// a recovery code
+func isTOTPCode(code string) bool {
+	if len(code) != 6 {
+		return false
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
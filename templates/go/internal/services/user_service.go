@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/utils"
+)
+
+// UserService handles user management logic
+type UserService struct {
+	userRepo interfaces.UserRepository
+	logger   *utils.Logger
+}
+
+// NewUserService creates a new user service
+func NewUserService(userRepo interfaces.UserRepository, logger *utils.Logger) *UserService {
+	return &UserService{
+		userRepo: userRepo,
+		logger:   logger,
+	}
+}
+
+// ExportUsers walks all users matching the filters in chunks of batchSize,
+// calling emit for each one. Only one chunk is held in memory at a time.
+func (s *UserService) ExportUsers(ctx context.Context, filters interfaces.UserFilters, batchSize int, emit func(models.UserResponse) error) error {
+	afterID := uuid.Nil
+
+	for {
+		users, err := s.userRepo.ListAfter(ctx, filters, afterID, batchSize)
+		if err != nil {
+			return fmt.Errorf("failed to export users: %w", err)
+		}
+
+		for _, user := range users {
+			if err := emit(user.ToResponse()); err != nil {
+				return fmt.Errorf("failed to write exported user: %w", err)
+			}
+		}
+
+		if len(users) < batchSize {
+			return nil
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// ResolvePermissionSource reports which of a user's roles grants the permission
+func (s *UserService) ResolvePermissionSource(ctx context.Context, userID uuid.UUID, permission string) (*models.PermissionSource, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	source := models.ResolvePermissionSource(user, permission)
+	return &source, nil
+}
+
+// GetSecurityInfo returns a user's account security details, such as when
+// the password was last changed
+func (s *UserService) GetSecurityInfo(ctx context.Context, userID uuid.UUID) (*models.SecurityInfo, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	info := user.ToSecurityInfo()
+	return &info, nil
+}
+
+// GetRoleHistory returns the roles granted to and revoked from a user, with
+// who made each change, newest first
+func (s *UserService) GetRoleHistory(ctx context.Context, userID uuid.UUID) ([]*models.RoleAssignmentHistory, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, ErrUserNotFound
+	}
+
+	history, err := s.userRepo.GetRoleAssignmentHistory(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get role history: %w", err)
+	}
+	return history, nil
+}
+
+// SearchUsers returns users whose email, username or name contains the
+// query. When withMatches is set each result reports which field matched.
+func (s *UserService) SearchUsers(ctx context.Context, query string, filters interfaces.UserFilters, withMatches bool) ([]models.UserSearchResult, error) {
+	users, err := s.userRepo.SearchUsers(ctx, query, filters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search users: %w", err)
+	}
+
+	results := make([]models.UserSearchResult, len(users))
+	for i, user := range users {
+		results[i].UserResponse = user.ToResponse()
+		if withMatches {
+			results[i].Match = models.MatchUser(user, query)
+		}
+	}
+
+	return results, nil
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"strings"
+)
+
+// ErrInvalidEmail is returned when an email address is malformed or its
+// domain cannot receive mail
+var ErrInvalidEmail = errors.New("invalid email address")
+
+// EmailValidator normalizes email addresses and rejects ones that the
+// validate:"email" tag accepts but real mail systems do not, such as
+// display names, dotless domains or consecutive dots
+type EmailValidator struct {
+	checkMX  bool
+	lookupMX func(ctx context.Context, domain string) ([]*net.MX, error)
+}
+
+// NewEmailValidator creates an email validator. With checkMX the domain
+// must also publish at least one MX record.
+func NewEmailValidator(checkMX bool) *EmailValidator {
+	return &EmailValidator{
+		checkMX:  checkMX,
+		lookupMX: net.DefaultResolver.LookupMX,
+	}
+}
+
+// SetMXLookup replaces the resolver used for MX checks
+func (v *EmailValidator) SetMXLookup(lookup func(ctx context.Context, domain string) ([]*net.MX, error)) {
+	v.lookupMX = lookup
+}
+
+// Normalize trims and lowercases an email address and validates it,
+// returning the address to store
+func (v *EmailValidator) Normalize(ctx context.Context, address string) (string, error) {
+	normalized := strings.ToLower(strings.TrimSpace(address))
+
+	// ParseAddress also accepts "Name <addr>" and comments, so insist the
+	// parsed address is the whole input
+	parsed, err := mail.ParseAddress(normalized)
+	if err != nil || parsed.Address != normalized {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, address)
+	}
+
+	local, domain, _ := strings.Cut(normalized, "@")
+	if !isValidLocalPart(local) || !isValidEmailDomain(domain) {
+		return "", fmt.Errorf("%w: %q", ErrInvalidEmail, address)
+	}
+
+	if v.checkMX {
+		records, err := v.lookupMX(ctx, domain)
+		if err != nil || len(records) == 0 {
+			return "", fmt.Errorf("%w: %s does not accept mail", ErrInvalidEmail, domain)
+		}
+	}
+
+	return normalized, nil
+}
+
+// isValidLocalPart checks the part before the @ is an unquoted dot-atom of
+// at most 64 characters
+func isValidLocalPart(local string) bool {
+	if local == "" || len(local) > 64 || strings.HasPrefix(local, ".") || strings.HasSuffix(local, ".") {
+		return false
+	}
+	return !strings.Contains(local, "..") && !strings.ContainsAny(local, "\"\\ ")
+}
+
+// isValidEmailDomain checks the domain is a dotted hostname of at most 253
+// characters with an alphabetic top-level label
+func isValidEmailDomain(domain string) bool {
+	labels := strings.Split(domain, ".")
+	if len(domain) > 253 || len(labels) < 2 {
+		return false
+	}
+
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+				return false
+			}
+		}
+	}
+
+	tld := labels[len(labels)-1]
+	if len(tld) < 2 {
+		return false
+	}
+	for _, r := range tld {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	return true
+}
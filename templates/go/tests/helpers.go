@@ -46,9 +46,13 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		&models.User{},
 		&models.Role{},
 		&models.UserRole{},
+		&models.RoleAssignmentHistory{},
 		&models.RefreshToken{},
 		&models.PasswordReset{},
 		&models.EmailVerification{},
+		&models.Invitation{},
+		&models.MFAChallenge{},
+		&models.RecoveryCode{},
 		&models.AuditLog{},
 	)
 
@@ -101,9 +105,13 @@ func teardownTestDB(t *testing.T, db *gorm.DB) {
 	// Clean up all tables
 	tables := []string{
 		"audit_logs",
+		"mfa_challenges",
+		"recovery_codes",
+		"invitations",
 		"email_verifications",
 		"password_resets",
 		"refresh_tokens",
+		"role_assignment_histories",
 		"user_roles",
 		"roles",
 		"users",
@@ -258,9 +266,13 @@ func assertUserNotExists(t *testing.T, db *gorm.DB, userID uuid.UUID) {
 func clearDatabase(db *gorm.DB) error {
 	tables := []string{
 		"audit_logs",
+		"mfa_challenges",
+		"recovery_codes",
+		"invitations",
 		"email_verifications",
 		"password_resets",
 		"refresh_tokens",
+		"role_assignment_histories",
 		"user_roles",
 		"users",
 		"roles",
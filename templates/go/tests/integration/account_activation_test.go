@@ -0,0 +1,95 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_PendingActivationBlocksLogin(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	cfg := &config.Config{Environment: "test", RequireAccountActivation: true}
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		cfg,
+	)
+
+	admin, err := createTestUser(db, "admin@example.com", "admin", "admin")
+	require.NoError(t, err)
+
+	const password = "Tz9!mVq#Lw4k"
+	loginReq := &models.LoginRequest{Login: "pending@example.com", Password: password}
+
+	// Act - register while activation is required
+	resp, err := authService.Register(ctx, &models.UserCreateRequest{
+		Email:     "pending@example.com",
+		Username:  "pending",
+		Password:  password,
+		FirstName: "Pending",
+		LastName:  "User",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert - the account is pending and receives no tokens
+	require.NoError(t, err)
+	assert.False(t, resp.User.IsActive)
+	assert.Empty(t, resp.AccessToken)
+	assert.Empty(t, resp.RefreshToken)
+
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", resp.User.ID).Update("is_verified", true).Error)
+
+	_, err = authService.Login(ctx, loginReq, "127.0.0.1", "test-agent")
+	assert.ErrorContains(t, err, "account is inactive")
+
+	// Act - an admin activates the account
+	require.NoError(t, authService.ActivateUser(ctx, resp.User.ID, admin.ID))
+
+	// Assert - login now succeeds
+	loginResp, err := authService.Login(ctx, loginReq, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, loginResp.AccessToken)
+}
+
+func TestAuthService_RegisterActiveWhenActivationNotRequired(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act
+	resp, err := authService.Register(context.Background(), &models.UserCreateRequest{
+		Email:     "active@example.com",
+		Username:  "active",
+		Password:  "Tz9!mVq#Lw4k",
+		FirstName: "Active",
+		LastName:  "User",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, resp.User.IsActive)
+	assert.NotEmpty(t, resp.AccessToken)
+}
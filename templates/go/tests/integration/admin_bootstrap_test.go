@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func newBootstrapConfig(password string) *config.Config {
+	return &config.Config{
+		Environment:            "test",
+		BootstrapAdminEmail:    "Root@Example.com",
+		BootstrapAdminUsername: "root",
+		BootstrapAdminPassword: password,
+	}
+}
+
+func TestAuthService_BootstrapAdmin_EmptyDatabase(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		newBootstrapConfig("Tz9!mVq#Lw4k"),
+	)
+
+	// Act
+	created, err := authService.BootstrapAdmin(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	var admin models.User
+	require.NoError(t, db.Preload("Roles").Where("email = ?", "root@example.com").First(&admin).Error)
+	assert.Equal(t, "root", admin.Username)
+	assert.True(t, admin.IsActive)
+	assert.True(t, admin.IsVerified)
+	require.Len(t, admin.Roles, 1)
+	assert.Equal(t, "admin", admin.Roles[0].Name)
+
+	_, err = authService.Login(ctx, &models.LoginRequest{Login: "root", Password: "Tz9!mVq#Lw4k"}, "127.0.0.1", "test-agent")
+	assert.NoError(t, err, "the bootstrap admin can log in")
+
+	// Act - later starts find the admin and do nothing
+	created, err = authService.BootstrapAdmin(ctx)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestAuthService_BootstrapAdmin_AdminExists(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	_, err := createTestUser(db, "existing@example.com", "existing", "admin")
+	require.NoError(t, err)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		newBootstrapConfig("Tz9!mVq#Lw4k"),
+	)
+
+	// Act
+	created, err := authService.BootstrapAdmin(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Where("email = ?", "root@example.com").Count(&count).Error)
+	assert.Zero(t, count)
+}
+
+func TestAuthService_BootstrapAdmin_RejectsWeakPassword(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		newBootstrapConfig("password"),
+	)
+
+	// Act
+	created, err := authService.BootstrapAdmin(context.Background())
+
+	// Assert
+	assert.Error(t, err)
+	assert.False(t, created)
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Count(&count).Error)
+	assert.Zero(t, count)
+}
@@ -0,0 +1,159 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/postgres"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestAuthService_Login_RecordsPasswordMethod(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	passwordService := auth.NewPasswordService(4)
+	hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+
+	user, err := createTestUser(db, "login@example.com", "login", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	authService := newTestAuthService(db, redisClient, jwtService, sessionService, &config.Config{Environment: "test"})
+
+	// Act
+	resp, err := authService.Login(context.Background(), &models.LoginRequest{
+		Login:    "login@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, auth.AuthMethodPassword, claims.AuthMethod)
+
+	sessions, err := sessionService.GetUserSessions(context.Background(), user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, auth.AuthMethodPassword, sessions[0].AuthMethod)
+
+	var auditMethod string
+	require.NoError(t, db.Raw(
+		"SELECT details->>'auth_method' FROM audit_logs WHERE user_id = ? AND action = ? AND success = true",
+		user.ID, "user.login",
+	).Scan(&auditMethod).Error)
+	assert.Equal(t, auth.AuthMethodPassword, auditMethod)
+}
+
+func TestAuthService_VerifyTOTP_RecordsTOTPMethod(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	ctx := context.Background()
+
+	// Act
+	resp, err := env.authService.VerifyTOTP(ctx, env.login(t), totpCode(t, totpTestSecret, time.Now()))
+
+	// Assert
+	require.NoError(t, err)
+
+	claims, err := env.jwtService.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, auth.AuthMethodTOTP, claims.AuthMethod)
+
+	sessions, err := env.sessionService.GetUserSessions(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, auth.AuthMethodTOTP, sessions[0].AuthMethod)
+
+	var auditMethod string
+	require.NoError(t, env.db.Raw(
+		"SELECT details->>'auth_method' FROM audit_logs WHERE user_id = ? AND action = ? AND success = true",
+		env.user.ID, "user.login",
+	).Scan(&auditMethod).Error)
+	assert.Equal(t, auth.AuthMethodTOTP, auditMethod)
+}
+
+func TestAuthService_RefreshToken_KeepsLoginMethod(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	ctx := context.Background()
+
+	resp, err := env.authService.VerifyTOTP(ctx, env.login(t), totpCode(t, totpTestSecret, time.Now()))
+	require.NoError(t, err)
+
+	// Act & Assert - the method survives each rotation of the refresh token
+	refreshToken := resp.RefreshToken
+	for i := 0; i < 2; i++ {
+		refreshed, err := env.authService.RefreshToken(ctx, refreshToken, "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		require.NotEqual(t, refreshToken, refreshed.RefreshToken)
+
+		claims, err := env.jwtService.ValidateToken(refreshed.AccessToken)
+		require.NoError(t, err)
+		assert.Equal(t, auth.AuthMethodTOTP, claims.AuthMethod)
+
+		refreshToken = refreshed.RefreshToken
+	}
+
+	var stored models.RefreshToken
+	require.NoError(t, env.db.Where("token = ?", models.HashRefreshToken(refreshToken)).First(&stored).Error)
+	assert.Equal(t, auth.AuthMethodTOTP, stored.AuthMethod)
+}
+
+func TestAuthService_RefreshToken_KeepsPasswordMethod(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, false)
+	ctx := context.Background()
+
+	resp, err := env.authService.Login(ctx, &models.LoginRequest{
+		Login:    "totp@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Act
+	refreshed, err := env.authService.RefreshToken(ctx, resp.RefreshToken, "127.0.0.1", "test-agent")
+
+	// Assert
+	require.NoError(t, err)
+
+	claims, err := env.jwtService.ValidateToken(refreshed.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, auth.AuthMethodPassword, claims.AuthMethod)
+}
+
+// newTestAuthService builds an AuthService backed by the test database and Redis
+func newTestAuthService(db *gorm.DB, redisClient *redis.Client, jwtService *auth.JWTService, sessionService *auth.SessionService, cfg *config.Config) *services.AuthService {
+	return services.NewAuthService(
+		postgres.NewUserRepository(db),
+		jwtService,
+		auth.NewPasswordService(4),
+		sessionService,
+		auth.NewBlacklistService(auth.NewRedisTokenBlacklist(redisClient, "")),
+		redisClient,
+		cfg,
+		utils.NewLogger("error", "test"),
+		db,
+	)
+}
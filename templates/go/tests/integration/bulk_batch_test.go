@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+// countStatements registers a callback counting the statements run against
+// the users table
+func countStatements(t *testing.T, register func(name string, fn func(*gorm.DB)) error) *int {
+	count := 0
+	require.NoError(t, register("test:count_statements", func(tx *gorm.DB) {
+		if tx.Statement.Table == "users" {
+			count++
+		}
+	}))
+	return &count
+}
+
+func createBulkTestUsers(t *testing.T, db *gorm.DB, n int) []uuid.UUID {
+	ids := make([]uuid.UUID, n)
+	for i := range ids {
+		user, err := createTestUser(db, fmt.Sprintf("bulk%d@example.com", i), fmt.Sprintf("bulk%d", i))
+		require.NoError(t, err)
+		ids[i] = user.ID
+	}
+	return ids
+}
+
+func TestUserRepository_BulkUpdateInBatches(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ids := createBulkTestUsers(t, db, 25)
+	repo := postgres.NewUserRepository(db, postgres.WithBulkBatchSize(10))
+	updates := countStatements(t, db.Callback().Update().Before("gorm:update").Register)
+
+	// Act
+	err := repo.BulkUpdate(context.Background(), ids, map[string]interface{}{"is_active": false})
+
+	// Assert - three batches of at most ten users update every user
+	require.NoError(t, err)
+	assert.Equal(t, 3, *updates)
+
+	var inactive int64
+	require.NoError(t, db.Model(&models.User{}).Where("id IN ? AND is_active = ?", ids, false).Count(&inactive).Error)
+	assert.Equal(t, int64(len(ids)), inactive)
+}
+
+func TestUserRepository_BulkDeleteInBatches(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ids := createBulkTestUsers(t, db, 25)
+	keep, err := createTestUser(db, "keep@example.com", "keep")
+	require.NoError(t, err)
+	repo := postgres.NewUserRepository(db, postgres.WithBulkBatchSize(10))
+	deletes := countStatements(t, db.Callback().Delete().Before("gorm:delete").Register)
+
+	// Act
+	err = repo.BulkDelete(context.Background(), ids)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, *deletes)
+
+	var remaining []models.User
+	require.NoError(t, db.Find(&remaining).Error)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, keep.ID, remaining[0].ID)
+}
+
+func TestUserRepository_BulkAssignRoleInBatches(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	ids := createBulkTestUsers(t, db, 25)
+	repo := postgres.NewUserRepository(db, postgres.WithBulkBatchSize(10))
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+
+	// A duplicate spanning two batches is granted once
+	withDuplicate := append(append([]uuid.UUID{}, ids...), ids[0])
+
+	// Act
+	err := repo.BulkAssignRole(ctx, withDuplicate, moderator.ID, nil)
+
+	// Assert
+	require.NoError(t, err)
+
+	var granted int64
+	require.NoError(t, db.Model(&models.UserRole{}).Where("role_id = ?", moderator.ID).Count(&granted).Error)
+	assert.Equal(t, int64(len(ids)), granted)
+
+	for _, id := range ids {
+		hasRole, err := repo.HasRole(ctx, id, "moderator")
+		require.NoError(t, err)
+		assert.True(t, hasRole)
+	}
+}
@@ -0,0 +1,83 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_LoginScopesTokenToClientAudience(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	passwordService := auth.NewPasswordService(4)
+	hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+
+	user, err := createTestUser(db, "client@example.com", "client", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	cfg := &config.Config{
+		Environment:          "test",
+		RefreshTokenRotation: true,
+		JWTClientAudiences:   map[string]string{"web": "web-app", "admin": "admin-console"},
+	}
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	authService := newTestAuthService(db, redisClient, jwtService, auth.NewSessionService(redisClient, time.Hour), cfg)
+
+	webServer := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithAudiences("web-app"))
+	adminServer := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithAudiences("admin-console"))
+
+	// Act
+	resp, err := authService.Login(ctx, &models.LoginRequest{
+		Login:    "client@example.com",
+		Password: "Str0ng!Passw0rd",
+		ClientID: "web",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Assert - the web token is accepted by the web audience only
+	claims, err := webServer.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, jwt.ClaimStrings{"web-app"}, claims.Audience)
+
+	_, err = adminServer.ValidateToken(resp.AccessToken)
+	assert.Error(t, err)
+
+	// Act - refreshing keeps the client's audience
+	refreshed, err := authService.RefreshToken(ctx, resp.RefreshToken, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Assert
+	_, err = webServer.ValidateToken(refreshed.AccessToken)
+	assert.NoError(t, err)
+	_, err = adminServer.ValidateToken(refreshed.AccessToken)
+	assert.Error(t, err)
+
+	// Act - an unknown client is rejected
+	_, err = authService.Login(ctx, &models.LoginRequest{
+		Login:    "client@example.com",
+		Password: "Str0ng!Passw0rd",
+		ClientID: "mobile",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrUnknownClient)
+}
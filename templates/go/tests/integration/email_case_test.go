@@ -0,0 +1,87 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+func TestAuthService_RegisterRejectsEmailDifferingOnlyByCase(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	register := func(email, username string) error {
+		_, err := authService.Register(ctx, &models.UserCreateRequest{
+			Email:     email,
+			Username:  username,
+			Password:  "Tz9!mVq#Lw4k",
+			FirstName: "Case",
+			LastName:  "User",
+		}, "127.0.0.1", "test-agent")
+		return err
+	}
+	require.NoError(t, register("User@Example.com", "first"))
+
+	// Act
+	err := register("user@example.com", "second")
+
+	// Assert
+	assert.ErrorContains(t, err, "already exists")
+
+	var count int64
+	require.NoError(t, db.Model(&models.User{}).Where("LOWER(email) = ?", "user@example.com").Count(&count).Error)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestUserRepository_EmailIsCaseInsensitive(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	repo := postgres.NewUserRepository(db)
+
+	user := &models.User{Email: " Mixed@Example.COM ", Username: "mixed", PasswordHash: "x", FirstName: "Mixed", LastName: "Case"}
+	require.NoError(t, repo.Create(ctx, user))
+
+	// Assert - the stored email is normalized
+	assert.Equal(t, "mixed@example.com", user.Email)
+
+	// Act & Assert - lookups ignore case
+	found, err := repo.GetByEmail(ctx, "MIXED@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	found, err = repo.GetByEmailOrUsername(ctx, "Mixed@Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, found.ID)
+
+	// Act & Assert - a second account with the same email in another case is rejected
+	duplicate := &models.User{Email: "MIXED@EXAMPLE.COM", Username: "mixed2", PasswordHash: "x", FirstName: "Mixed", LastName: "Case"}
+	assert.Error(t, repo.Create(ctx, duplicate))
+
+	// Act & Assert - the unique index holds even for writes that skip the repository
+	raw := &models.User{Email: "Mixed@Example.com", Username: "mixed3", PasswordHash: "x", FirstName: "Mixed", LastName: "Case"}
+	assert.Error(t, db.Create(raw).Error)
+}
@@ -0,0 +1,120 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+func TestAuthService_FailedLoginAuditSampling(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	cfg := &config.Config{
+		Environment:                   "test",
+		FailedLoginAuditWindowSeconds: 3600,
+		FailedLoginAuditMaxPerWindow:  3,
+	}
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		cfg,
+	)
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	victim, err := createTestUser(db, "victim@example.com", "victim")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(victim).Update("password_hash", hash).Error)
+	legit, err := createTestUser(db, "legit@example.com", "legit")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(legit).Update("password_hash", hash).Error)
+
+	const attempts = 50
+
+	// Act - a burst of failures from one IP against unknown and known accounts
+	for i := 0; i < attempts; i++ {
+		login := "nobody@example.com"
+		if i%2 == 0 {
+			login = "victim@example.com"
+		}
+		_, err := authService.Login(ctx, &models.LoginRequest{Login: login, Password: "wrong"}, "203.0.113.7", "stuffer")
+		require.Error(t, err)
+	}
+
+	_, err = authService.Login(ctx, &models.LoginRequest{Login: "legit@example.com", Password: "Str0ng!Passw0rd"}, "203.0.113.7", "browser")
+	require.NoError(t, err)
+
+	// Assert - failures are bounded to the sample plus one summary
+	var failedRows int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action = ? AND success = false", "user.login").
+		Count(&failedRows).Error)
+	assert.Equal(t, int64(3), failedRows)
+
+	var summaries []models.AuditLog
+	require.NoError(t, db.Where("action = ?", "user.login_failed_summary").Find(&summaries).Error)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "203.0.113.7", summaries[0].IPAddress)
+	assert.EqualValues(t, attempts-3, summaries[0].Details["suppressed_count"])
+
+	// Assert - login stats still count the victim's suppressed failures
+	stats, err := postgres.NewUserRepository(db).GetLoginStats(ctx, victim.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(attempts/2), stats.FailedLogins)
+
+	// Assert - lockouts and successes are always recorded
+	var lockouts int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action = ? AND user_id = ?", "user.lockout", victim.ID).
+		Count(&lockouts).Error)
+	assert.Equal(t, int64(1), lockouts)
+
+	var successes int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action = ? AND success = true AND user_id = ?", "user.login", legit.ID).
+		Count(&successes).Error)
+	assert.Equal(t, int64(1), successes)
+}
+
+func TestAuthService_FailedLoginAuditWithoutSampling(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act
+	for i := 0; i < 10; i++ {
+		_, err := authService.Login(context.Background(), &models.LoginRequest{Login: "nobody@example.com", Password: "wrong"}, "203.0.113.8", "stuffer")
+		require.Error(t, err)
+	}
+
+	// Assert
+	var failedRows int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("action = ? AND success = false", "user.login").
+		Count(&failedRows).Error)
+	assert.Equal(t, int64(10), failedRows)
+}
@@ -0,0 +1,160 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/database"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_InviteOnlyRegistration(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	cfg := &config.Config{Environment: "test", RequireInvitation: true, InvitationTTLHours: 24}
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		cfg,
+	)
+
+	admin, err := createTestUser(db, "admin@example.com", "admin", "admin")
+	require.NoError(t, err)
+
+	invitation, err := authService.CreateInvitation(ctx, "invitee@example.com", admin.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, time.Now().Add(24*time.Hour), invitation.ExpiresAt, time.Minute)
+
+	// Only the hash of the token is stored
+	var storedToken string
+	require.NoError(t, db.Model(&models.Invitation{}).Where("id = ?", invitation.ID).Pluck("token", &storedToken).Error)
+	assert.Equal(t, models.HashInvitationToken(invitation.Token), storedToken)
+
+	expired, err := authService.CreateInvitation(ctx, "late@example.com", admin.ID)
+	require.NoError(t, err)
+	require.NoError(t, db.Model(&models.Invitation{}).Where("id = ?", expired.ID).Update("expires_at", time.Now().Add(-time.Minute)).Error)
+
+	register := func(email, username, token string) (*models.AuthResponse, error) {
+		return authService.Register(ctx, &models.UserCreateRequest{
+			Email:       email,
+			Username:    username,
+			Password:    "Tz9!mVq#Lw4k",
+			FirstName:   "Invited",
+			LastName:    "User",
+			InviteToken: token,
+		}, "127.0.0.1", "test-agent")
+	}
+
+	t.Run("missing invitation", func(t *testing.T) {
+		_, err := register("invitee@example.com", "noinvite", "")
+		assert.ErrorIs(t, err, services.ErrInvitationRequired)
+	})
+
+	t.Run("unknown token", func(t *testing.T) {
+		_, err := register("invitee@example.com", "unknown", "not-a-token")
+		assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+	})
+
+	t.Run("stored hash as token", func(t *testing.T) {
+		_, err := register("invitee@example.com", "hashed", storedToken)
+		assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+	})
+
+	t.Run("expired invitation", func(t *testing.T) {
+		_, err := register("late@example.com", "late", expired.Token)
+		assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+	})
+
+	t.Run("email mismatch", func(t *testing.T) {
+		_, err := register("someone-else@example.com", "mismatch", invitation.Token)
+		assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+
+		var count int64
+		require.NoError(t, db.Model(&models.User{}).Where("email = ?", "someone-else@example.com").Count(&count).Error)
+		assert.Zero(t, count, "a rejected invitation must not leave a user behind")
+	})
+
+	t.Run("valid invitation", func(t *testing.T) {
+		resp, err := register("Invitee@Example.com", "invitee", invitation.Token)
+		require.NoError(t, err)
+
+		var used models.Invitation
+		require.NoError(t, db.First(&used, "id = ?", invitation.ID).Error)
+		assert.True(t, used.IsUsed)
+		require.NotNil(t, used.UsedBy)
+		assert.Equal(t, resp.User.ID, *used.UsedBy)
+	})
+
+	t.Run("reused invitation", func(t *testing.T) {
+		_, err := register("invitee@example.com", "invitee2", invitation.Token)
+		assert.ErrorIs(t, err, services.ErrInvalidInvitation)
+	})
+}
+
+func TestAuthService_RegisterIgnoresInvitationWhenNotRequired(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act
+	_, err := authService.Register(context.Background(), &models.UserCreateRequest{
+		Email:     "open@example.com",
+		Username:  "open",
+		Password:  "Tz9!mVq#Lw4k",
+		FirstName: "Open",
+		LastName:  "User",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestMigrate_HashesLegacyInvitationTokens(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	admin, err := createTestUser(db, "legacy-inviter@example.com", "legacyinviter", "admin")
+	require.NoError(t, err)
+
+	// A row written before tokens were hashed holds the raw token
+	const rawToken = "legacy-plaintext-invitation-token"
+	legacyID := uuid.New()
+	require.NoError(t, db.Exec(
+		`INSERT INTO invitations (id, email, token, token_hashed, invited_by, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, false, ?, ?, NOW(), NOW())`,
+		legacyID, "legacy-invitee@example.com", rawToken, admin.ID, time.Now().Add(time.Hour)).Error)
+
+	// Act
+	require.NoError(t, database.Migrate(db))
+
+	// Assert
+	var legacy models.Invitation
+	require.NoError(t, db.First(&legacy, "id = ?", legacyID).Error)
+	assert.Equal(t, models.HashInvitationToken(rawToken), legacy.TokenHash)
+	assert.True(t, legacy.TokenHashed)
+}
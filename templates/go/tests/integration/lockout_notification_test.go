@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_LockoutNotificationCooldown(t *testing.T) {
+	tests := []struct {
+		name             string
+		cooldownSeconds  int
+		expectedNotified int64
+	}{
+		{name: "one notification within cooldown", cooldownSeconds: 3600, expectedNotified: 1},
+		{name: "cooldown disabled", cooldownSeconds: 0, expectedNotified: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			passwordService := auth.NewPasswordService(4)
+			hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+			require.NoError(t, err)
+
+			user, err := createTestUser(db, "locked@example.com", "locked", "user")
+			require.NoError(t, err)
+			require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+			authService := newTestAuthService(db, redisClient,
+				auth.NewJWTService("test-secret", "test-issuer", 1),
+				auth.NewSessionService(redisClient, time.Hour),
+				&config.Config{Environment: "test", LockoutNotificationCooldownSeconds: tt.cooldownSeconds},
+			)
+
+			// Act - lock the account three times, unlocking in between
+			for i := 0; i < 3; i++ {
+				require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+					"failed_login_count": 4,
+					"locked_until":       nil,
+				}).Error)
+
+				_, err := authService.Login(context.Background(), &models.LoginRequest{
+					Login:    "locked@example.com",
+					Password: "wrong-password",
+				}, "127.0.0.1", "test-agent")
+				require.Error(t, err)
+			}
+
+			// Assert
+			var lockouts, notified int64
+			require.NoError(t, db.Model(&models.AuditLog{}).
+				Where("user_id = ? AND action = ?", user.ID, "user.lockout").
+				Count(&lockouts).Error)
+			require.NoError(t, db.Model(&models.AuditLog{}).
+				Where("user_id = ? AND action = ? AND details->>'notified' = 'true'", user.ID, "user.lockout").
+				Count(&notified).Error)
+
+			assert.Equal(t, int64(3), lockouts)
+			assert.Equal(t, tt.expectedNotified, notified)
+		})
+	}
+}
@@ -0,0 +1,185 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func setupLockoutTest(t *testing.T, cfg *config.Config) (*gorm.DB, *models.User, func(password string) error) {
+	db := setupTestDB(t)
+	t.Cleanup(func() { teardownTestDB(t, db) })
+	redisClient := setupTestRedis(t)
+	t.Cleanup(func() { teardownTestRedis(t, redisClient) })
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "lockout@example.com", "lockout", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		cfg,
+	)
+	login := func(password string) error {
+		_, err := authService.Login(context.Background(), &models.LoginRequest{
+			Login:    "lockout@example.com",
+			Password: password,
+		}, "127.0.0.1", "test-agent")
+		return err
+	}
+	return db, user, login
+}
+
+func TestAuthService_Login_ConfigurableLockout(t *testing.T) {
+	// Arrange
+	db, user, login := setupLockoutTest(t, &config.Config{
+		Environment:           "test",
+		MaxLoginAttempts:      3,
+		AccountLockoutMinutes: 5,
+	})
+
+	// Act - two failures stay below the threshold
+	for i := 0; i < 2; i++ {
+		require.Error(t, login("wrong-password"))
+	}
+
+	// Assert
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.Equal(t, 2, stored.FailedLoginCount)
+	assert.False(t, stored.IsLocked())
+
+	// Act - the third failure locks the account
+	require.Error(t, login("wrong-password"))
+
+	// Assert - locked for five minutes, even with the right password
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	require.True(t, stored.IsLocked())
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), *stored.LockedUntil, 5*time.Second)
+
+	err := login("Str0ng!Passw0rd")
+	assert.ErrorIs(t, err, services.ErrAccountLocked)
+
+	var lockouts int64
+	require.NoError(t, db.Model(&models.AuditLog{}).
+		Where("user_id = ? AND action = ? AND details->>'locked_minutes' = '5'", user.ID, "user.lockout").
+		Count(&lockouts).Error)
+	assert.Equal(t, int64(1), lockouts)
+}
+
+func TestAuthService_Login_DefaultLockoutThreshold(t *testing.T) {
+	// Arrange - a config without a lockout policy uses the defaults
+	db, user, login := setupLockoutTest(t, &config.Config{Environment: "test"})
+
+	// Act
+	for i := 0; i < 4; i++ {
+		require.Error(t, login("wrong-password"))
+	}
+
+	// Assert
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.False(t, stored.IsLocked())
+
+	require.Error(t, login("wrong-password"))
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	require.True(t, stored.IsLocked())
+	assert.WithinDuration(t, time.Now().Add(30*time.Minute), *stored.LockedUntil, 5*time.Second)
+}
+
+func TestAuthService_Login_SuccessResetsFailedCount(t *testing.T) {
+	// Arrange
+	db, user, login := setupLockoutTest(t, &config.Config{
+		Environment:           "test",
+		MaxLoginAttempts:      3,
+		AccountLockoutMinutes: 5,
+	})
+	for i := 0; i < 2; i++ {
+		require.Error(t, login("wrong-password"))
+	}
+
+	// Act
+	require.NoError(t, login("Str0ng!Passw0rd"))
+
+	// Assert - the earlier failures no longer count towards a lockout
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.Zero(t, stored.FailedLoginCount)
+
+	for i := 0; i < 2; i++ {
+		require.Error(t, login("wrong-password"))
+	}
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.False(t, stored.IsLocked())
+}
+
+func TestAuthService_Login_ExpiredLockoutGivesFreshAttempts(t *testing.T) {
+	// Arrange - the account was locked at the threshold and the lock has run out
+	db, user, login := setupLockoutTest(t, &config.Config{
+		Environment:           "test",
+		MaxLoginAttempts:      3,
+		AccountLockoutMinutes: 5,
+	})
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{
+		"failed_login_count": 3,
+		"locked_until":       time.Now().Add(-time.Minute),
+	}).Error)
+
+	// Act - a single failure after expiry
+	require.Error(t, login("wrong-password"))
+
+	// Assert - it counts as the first attempt of a new budget
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.Equal(t, 1, stored.FailedLoginCount)
+	assert.False(t, stored.IsLocked())
+	assert.Nil(t, stored.LockedUntil)
+
+	// Act & Assert - the full budget is available before the next lock
+	require.Error(t, login("wrong-password"))
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.False(t, stored.IsLocked())
+
+	require.Error(t, login("wrong-password"))
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.True(t, stored.IsLocked())
+}
+
+func TestAuthService_Login_SucceedsAfterLockoutExpires(t *testing.T) {
+	// Arrange
+	db, user, login := setupLockoutTest(t, &config.Config{
+		Environment:           "test",
+		MaxLoginAttempts:      3,
+		AccountLockoutMinutes: 5,
+	})
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{
+		"failed_login_count": 3,
+		"locked_until":       time.Now().Add(-time.Minute),
+	}).Error)
+
+	// Act
+	err := login("Str0ng!Passw0rd")
+
+	// Assert
+	require.NoError(t, err)
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.Zero(t, stored.FailedLoginCount)
+	assert.Nil(t, stored.LockedUntil)
+}
@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestAuthService_Login_LockedAccountReportsRemainingTime(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	user, err := createTestUser(db, "locked@example.com", "locked", "user")
+	require.NoError(t, err)
+	lockedUntil := time.Now().Add(10 * time.Minute)
+	require.NoError(t, db.Model(user).Update("locked_until", lockedUntil).Error)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act
+	_, err = authService.Login(context.Background(), &models.LoginRequest{
+		Login:    "locked@example.com",
+		Password: "Tz9!mVq#Lw4k",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrAccountLocked)
+
+	var lockedErr *services.AccountLockedError
+	require.ErrorAs(t, err, &lockedErr)
+	assert.InDelta(t, 600, lockedErr.RemainingSeconds(), 2)
+}
+
+func TestAuthHandler_Login_LockedAccount(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	user, err := createTestUser(db, "locked@example.com", "locked", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("locked_until", time.Now().Add(5*time.Minute)).Error)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+	authHandler := handlers.NewAuthHandler(authService, utils.NewLogger("error", "test"))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/login", authHandler.Login)
+
+	body, err := json.Marshal(models.LoginRequest{Login: "locked@example.com", Password: "Tz9!mVq#Lw4k"})
+	require.NoError(t, err)
+
+	// Act
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	var resp struct {
+		Code              string `json:"code"`
+		RetryAfterSeconds int    `json:"retry_after_seconds"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, "ACCOUNT_LOCKED", resp.Code)
+	assert.InDelta(t, 300, resp.RetryAfterSeconds, 2)
+
+	retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+	require.NoError(t, err)
+	assert.Equal(t, resp.RetryAfterSeconds, retryAfter)
+}
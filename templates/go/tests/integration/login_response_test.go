@@ -0,0 +1,82 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_LoginResponseRoleInclusion(t *testing.T) {
+	tests := []struct {
+		name         string
+		includeRoles bool
+	}{
+		{name: "full response", includeRoles: true},
+		{name: "slim response", includeRoles: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			passwordService := auth.NewPasswordService(4)
+			hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+			require.NoError(t, err)
+
+			user, err := createTestUser(db, "shape@example.com", "shape", "user")
+			require.NoError(t, err)
+			require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+			authService := newTestAuthService(db, redisClient,
+				auth.NewJWTService("test-secret", "test-issuer", 1),
+				auth.NewSessionService(redisClient, time.Hour),
+				&config.Config{Environment: "test", LoginResponseIncludeRoles: tt.includeRoles, RefreshTokenRotation: true},
+			)
+
+			// Act
+			loginResp, err := authService.Login(context.Background(), &models.LoginRequest{
+				Login:    "shape@example.com",
+				Password: "Str0ng!Passw0rd",
+			}, "127.0.0.1", "test-agent")
+			require.NoError(t, err)
+
+			refreshResp, err := authService.RefreshToken(context.Background(), loginResp.RefreshToken, "127.0.0.1", "test-agent")
+			require.NoError(t, err)
+
+			// Assert
+			for _, resp := range []*models.AuthResponse{loginResp, refreshResp} {
+				body, err := json.Marshal(resp)
+				require.NoError(t, err)
+
+				var decoded struct {
+					User map[string]json.RawMessage `json:"user"`
+				}
+				require.NoError(t, json.Unmarshal(body, &decoded))
+				assert.Contains(t, decoded.User, "email")
+
+				if tt.includeRoles {
+					require.Len(t, resp.User.Roles, 1)
+					assert.Equal(t, "user", resp.User.Roles[0].Name)
+					assert.NotEmpty(t, resp.User.Roles[0].Permissions)
+				} else {
+					assert.NotContains(t, decoded.User, "roles")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_GetLoginStats(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	user, err := createTestUser(db, "stats@example.com", "stats", "user")
+	require.NoError(t, err)
+	other, err := createTestUser(db, "other@example.com", "other", "user")
+	require.NoError(t, err)
+
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	lastFailure := base.Add(30 * time.Minute)
+	logs := []models.AuditLog{
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: true, CreatedAt: base},
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: false, CreatedAt: base.Add(10 * time.Minute)},
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: true, CreatedAt: base.Add(20 * time.Minute)},
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: false, CreatedAt: lastFailure},
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: true, CreatedAt: base.Add(40 * time.Minute)},
+		// Other actions and other users' logins are not counted
+		{UserID: &user.ID, Action: "user.logout", Resource: "user", Success: true, CreatedAt: base.Add(50 * time.Minute)},
+		{UserID: &other.ID, Action: "user.login", Resource: "user", Success: false, CreatedAt: base.Add(55 * time.Minute)},
+	}
+	for i := range logs {
+		require.NoError(t, db.Create(&logs[i]).Error)
+	}
+
+	repo := postgres.NewUserRepository(db)
+
+	// Act
+	stats, err := repo.GetLoginStats(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, stats.UserID)
+	assert.Equal(t, int64(5), stats.TotalLogins)
+	assert.Equal(t, int64(3), stats.SuccessfulLogins)
+	assert.Equal(t, int64(2), stats.FailedLogins)
+	require.NotNil(t, stats.LastFailedLogin)
+	assert.WithinDuration(t, lastFailure, *stats.LastFailedLogin, time.Second)
+}
+
+func TestUserRepository_GetLoginStatsCountsSuppressedFailures(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	user, err := createTestUser(db, "sampled@example.com", "sampled", "user")
+	require.NoError(t, err)
+	other, err := createTestUser(db, "bystander@example.com", "bystander", "user")
+	require.NoError(t, err)
+
+	base := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	lastSummary := base.Add(30 * time.Minute)
+	logs := []models.AuditLog{
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: true, CreatedAt: base},
+		{UserID: &user.ID, Action: "user.login", Resource: "user", Success: false, CreatedAt: base.Add(10 * time.Minute)},
+		// Sampling summaries carry no user; their per-user tallies are counted
+		{Action: "user.login_failed_summary", Resource: "user", CreatedAt: base.Add(20 * time.Minute),
+			Details: map[string]interface{}{
+				"suppressed_count":   7,
+				"suppressed_by_user": map[string]interface{}{user.ID.String(): 4, other.ID.String(): 3},
+			}},
+		{Action: "user.login_failed_summary", Resource: "user", CreatedAt: lastSummary,
+			Details: map[string]interface{}{
+				"suppressed_count":   2,
+				"suppressed_by_user": map[string]interface{}{user.ID.String(): 2},
+			}},
+		{Action: "user.login_failed_summary", Resource: "user", CreatedAt: base.Add(40 * time.Minute),
+			Details: map[string]interface{}{
+				"suppressed_count":   5,
+				"suppressed_by_user": map[string]interface{}{other.ID.String(): 5},
+			}},
+	}
+	for i := range logs {
+		require.NoError(t, db.Create(&logs[i]).Error)
+	}
+
+	repo := postgres.NewUserRepository(db)
+
+	// Act
+	stats, err := repo.GetLoginStats(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), stats.TotalLogins)
+	assert.Equal(t, int64(1), stats.SuccessfulLogins)
+	assert.Equal(t, int64(7), stats.FailedLogins)
+	require.NotNil(t, stats.LastFailedLogin)
+	assert.WithinDuration(t, lastSummary, *stats.LastFailedLogin, time.Second)
+}
+
+func TestUserRepository_GetLoginStatsWithoutAttempts(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	user, err := createTestUser(db, "fresh@example.com", "fresh", "user")
+	require.NoError(t, err)
+
+	repo := postgres.NewUserRepository(db)
+
+	// Act
+	stats, err := repo.GetLoginStats(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Zero(t, stats.TotalLogins)
+	assert.Zero(t, stats.SuccessfulLogins)
+	assert.Zero(t, stats.FailedLogins)
+	assert.Nil(t, stats.LastFailedLogin)
+}
@@ -0,0 +1,138 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_ChangePassword_Concurrent(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	const oldPassword = "Str0ng!Passw0rd"
+	newPasswords := []string{"Tz9!mVq#Lw4k", "Qp7$rNx@Hc2j"}
+
+	passwordService := auth.NewPasswordService(4)
+	hash, err := passwordService.HashPassword(oldPassword)
+	require.NoError(t, err)
+	user, err := createTestUser(db, "concurrent@example.com", "concurrent", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		sessionService,
+		&config.Config{Environment: "test"},
+	)
+
+	for i := 0; i < 2; i++ {
+		_, err := authService.Login(ctx, &models.LoginRequest{Login: "concurrent@example.com", Password: oldPassword}, "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+	}
+
+	// Act
+	errs := make([]error, len(newPasswords))
+	var wg sync.WaitGroup
+	start := make(chan struct{})
+	for i, newPassword := range newPasswords {
+		wg.Add(1)
+		go func(i int, newPassword string) {
+			defer wg.Done()
+			<-start
+			errs[i] = authService.ChangePassword(ctx, user.ID, &models.ChangePasswordRequest{
+				CurrentPassword: oldPassword,
+				NewPassword:     newPassword,
+			})
+		}(i, newPassword)
+	}
+	close(start)
+	wg.Wait()
+
+	// Assert - exactly one change wins and the other is told it lost
+	winner := -1
+	for i, err := range errs {
+		if err == nil {
+			require.Equal(t, -1, winner, "both concurrent changes succeeded")
+			winner = i
+			continue
+		}
+		if !assert.ErrorIs(t, err, services.ErrPasswordChangeSuperseded) {
+			// The loser may also start after the winner committed, in which
+			// case its current password no longer matches
+			assert.ErrorContains(t, err, "current password is incorrect")
+		}
+	}
+	require.NotEqual(t, -1, winner, "neither change succeeded")
+
+	var stored models.User
+	require.NoError(t, db.First(&stored, "id = ?", user.ID).Error)
+	assert.NoError(t, passwordService.VerifyPassword(stored.PasswordHash, newPasswords[winner]))
+	assert.Error(t, passwordService.VerifyPassword(stored.PasswordHash, newPasswords[1-winner]))
+
+	var active int64
+	require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ? AND is_revoked = ?", user.ID, false).Count(&active).Error)
+	assert.Zero(t, active, "every refresh token must be revoked")
+
+	count, err := sessionService.GetActiveSessionCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count, "the user's sessions must be deleted")
+}
+
+func TestAuthService_ChangePassword_Sequential(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	passwordService := auth.NewPasswordService(4)
+	hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "sequential@example.com", "sequential", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	passwords := []string{"Str0ng!Passw0rd", "Tz9!mVq#Lw4k", "Qp7$rNx@Hc2j"}
+	for i := 1; i < len(passwords); i++ {
+		_, err := authService.Login(ctx, &models.LoginRequest{Login: "sequential@example.com", Password: passwords[i-1]}, "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+
+		// Act
+		err = authService.ChangePassword(ctx, user.ID, &models.ChangePasswordRequest{
+			CurrentPassword: passwords[i-1],
+			NewPassword:     passwords[i],
+		})
+
+		// Assert - each change applies and revokes the tokens issued before it
+		require.NoError(t, err)
+
+		var active int64
+		require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ? AND is_revoked = ?", user.ID, false).Count(&active).Error)
+		assert.Zero(t, active, "change %d must revoke every refresh token", i)
+	}
+}
@@ -0,0 +1,144 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/database"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_ResetPasswordThrottlesInvalidAttempts(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	cfg := &config.Config{
+		Environment:                "test",
+		PasswordResetMaxAttempts:   3,
+		PasswordResetWindowSeconds: 60,
+	}
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		cfg,
+	)
+
+	user, err := createTestUser(db, "reset@example.com", "reset", "user")
+	require.NoError(t, err)
+	reset := &models.PasswordReset{Email: user.Email, UserID: user.ID}
+	require.NoError(t, db.Create(reset).Error)
+
+	const attackerIP = "203.0.113.7"
+	newPassword := "Tz9!mVq#Lw4k"
+
+	// Act - guess tokens until the limit is reached
+	for i := 0; i < cfg.PasswordResetMaxAttempts; i++ {
+		err := authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: "guess", NewPassword: newPassword}, attackerIP)
+		assert.ErrorContains(t, err, "invalid or expired reset token")
+	}
+
+	// Assert - further attempts are throttled, even with the right token
+	err = authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: "guess", NewPassword: newPassword}, attackerIP)
+	assert.ErrorIs(t, err, services.ErrTooManyResetAttempts)
+
+	err = authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: reset.Token, NewPassword: newPassword}, attackerIP)
+	assert.ErrorIs(t, err, services.ErrTooManyResetAttempts)
+
+	// Assert - other IPs are unaffected
+	err = authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: reset.Token, NewPassword: newPassword}, "198.51.100.2")
+	assert.NoError(t, err)
+}
+
+func TestAuthService_ResetPasswordUnlimitedWhenDisabled(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act & Assert
+	for i := 0; i < 10; i++ {
+		err := authService.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: "guess", NewPassword: "Tz9!mVq#Lw4k"}, "203.0.113.7")
+		assert.ErrorContains(t, err, "invalid or expired reset token")
+	}
+}
+
+func TestAuthService_ResetTokenStoredAsHash(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	user, err := createTestUser(db, "hashed-reset@example.com", "hashedreset", "user")
+	require.NoError(t, err)
+	reset := &models.PasswordReset{Email: user.Email, UserID: user.ID}
+	require.NoError(t, db.Create(reset).Error)
+
+	// Assert - the column holds the hash, never the raw token
+	var stored string
+	require.NoError(t, db.Model(&models.PasswordReset{}).Where("id = ?", reset.ID).Pluck("token", &stored).Error)
+	assert.Equal(t, models.HashPasswordResetToken(reset.Token), stored)
+	assert.NotEqual(t, reset.Token, stored)
+
+	// Act & Assert - the stored hash is not accepted as a token
+	err = authService.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: stored, NewPassword: "Tz9!mVq#Lw4k"}, "127.0.0.1")
+	assert.ErrorContains(t, err, "invalid or expired reset token")
+
+	// Act & Assert - the raw token is
+	err = authService.ResetPassword(context.Background(), &models.ResetPasswordRequest{Token: reset.Token, NewPassword: "Tz9!mVq#Lw4k"}, "127.0.0.1")
+	assert.NoError(t, err)
+}
+
+func TestMigrate_HashesLegacyPasswordResetTokens(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	user, err := createTestUser(db, "legacy-reset@example.com", "legacyreset", "user")
+	require.NoError(t, err)
+
+	// A row written before tokens were hashed holds the raw token
+	const rawToken = "legacy-plaintext-reset-token"
+	legacyID := uuid.New()
+	require.NoError(t, db.Exec(
+		`INSERT INTO password_resets (id, email, token, token_hashed, user_id, expires_at, created_at, updated_at)
+		VALUES (?, ?, ?, false, ?, ?, NOW(), NOW())`,
+		legacyID, user.Email, rawToken, user.ID, time.Now().Add(time.Hour)).Error)
+
+	// Act
+	require.NoError(t, database.Migrate(db))
+
+	// Assert
+	var legacy models.PasswordReset
+	require.NoError(t, db.First(&legacy, "id = ?", legacyID).Error)
+	assert.Equal(t, models.HashPasswordResetToken(rawToken), legacy.TokenHash)
+	assert.True(t, legacy.TokenHashed)
+}
@@ -0,0 +1,62 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_ForgotPasswordTiming(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	const minDuration = 150 * time.Millisecond
+	const samples = 15
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test", PasswordResetMinDurationMs: int(minDuration / time.Millisecond)},
+	)
+
+	user, err := createTestUser(db, "timing@example.com", "timing", "user")
+	require.NoError(t, err)
+
+	measure := func(email string) []time.Duration {
+		durations := make([]time.Duration, samples)
+		for i := range durations {
+			start := time.Now()
+			require.NoError(t, authService.ForgotPassword(ctx, &models.ForgotPasswordRequest{Email: email}, "127.0.0.1"))
+			durations[i] = time.Since(start)
+		}
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		return durations
+	}
+
+	// Act
+	known := measure(user.Email)
+	unknown := measure("nobody@example.com")
+
+	// Assert - both distributions sit at the floor and their medians agree
+	for name, durations := range map[string][]time.Duration{"known": known, "unknown": unknown} {
+		assert.GreaterOrEqual(t, durations[0], minDuration, "fastest %s request", name)
+	}
+	knownMedian, unknownMedian := known[samples/2], unknown[samples/2]
+	assert.InDelta(t, float64(knownMedian), float64(unknownMedian), float64(20*time.Millisecond),
+		"median known %s vs unknown %s", knownMedian, unknownMedian)
+}
@@ -0,0 +1,131 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimiter_ConcurrentRequests(t *testing.T) {
+	const limit = 25
+	const workers = 50
+	const requestsPerWorker = 4
+
+	tests := []struct {
+		name    string
+		sliding bool
+	}{
+		{name: "fixed window", sliding: false},
+		{name: "sliding window", sliding: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			gin.SetMode(gin.TestMode)
+			rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"))
+			router := gin.New()
+			router.Use(rateLimiter.RateLimit(middleware.RateLimitConfig{
+				Requests: limit,
+				Window:   time.Hour,
+				KeyFunc:  middleware.IPKeyFunc("concurrent"),
+				Sliding:  tt.sliding,
+			}))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			// Act
+			var allowed int64
+			var wg sync.WaitGroup
+			start := make(chan struct{})
+			for i := 0; i < workers; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					<-start
+					for j := 0; j < requestsPerWorker; j++ {
+						w := httptest.NewRecorder()
+						router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+						if w.Code == http.StatusOK {
+							atomic.AddInt64(&allowed, 1)
+						}
+					}
+				}()
+			}
+			close(start)
+			wg.Wait()
+
+			// Assert
+			assert.Equal(t, int64(limit), allowed)
+
+			keys, err := redisClient.Keys(context.Background(), "rate_limit:concurrent:*").Result()
+			require.NoError(t, err)
+			require.Len(t, keys, 1)
+
+			ttl, err := redisClient.PTTL(context.Background(), keys[0]).Result()
+			require.NoError(t, err)
+			assert.Greater(t, ttl, time.Duration(0), "the window key must expire")
+			assert.LessOrEqual(t, ttl, time.Hour)
+
+			if tt.sliding {
+				recorded, err := redisClient.ZCard(context.Background(), keys[0]).Result()
+				require.NoError(t, err)
+				assert.Equal(t, int64(limit), recorded, "rejected requests must not be recorded")
+			}
+		})
+	}
+}
+
+func TestRateLimiter_ExpiryNotExtendedMidWindow(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"))
+	router := gin.New()
+	router.Use(rateLimiter.RateLimit(middleware.RateLimitConfig{
+		Requests: 100,
+		Window:   time.Hour,
+		KeyFunc:  middleware.IPKeyFunc("expiry"),
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	serve := func() {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+
+	ctx := context.Background()
+	serve()
+	keys, err := redisClient.Keys(ctx, "rate_limit:expiry:*").Result()
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	first, err := redisClient.PTTL(ctx, keys[0]).Result()
+	require.NoError(t, err)
+
+	// Act
+	time.Sleep(50 * time.Millisecond)
+	serve()
+
+	// Assert
+	second, err := redisClient.PTTL(ctx, keys[0]).Result()
+	require.NoError(t, err)
+	assert.Less(t, second, first, "later requests must not reset the window's expiry")
+}
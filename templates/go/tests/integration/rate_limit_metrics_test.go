@@ -0,0 +1,54 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimiter_CountsBlockedRequests(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	metrics := middleware.NewRateLimitMetrics(prometheus.NewRegistry())
+	limiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"),
+		middleware.WithRateLimitMetrics(metrics),
+	)
+
+	router := gin.New()
+	router.Use(limiter.RateLimit(middleware.RateLimitConfig{
+		Requests: 1,
+		Window:   time.Minute,
+		KeyFunc:  middleware.IPKeyFunc("metrics"),
+		Category: "login",
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// Act
+	codes := make([]int, 3)
+	for i := range codes {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		codes[i] = w.Code
+	}
+
+	// Assert - only the two rejected requests are counted
+	assert.Equal(t, []int{http.StatusOK, http.StatusTooManyRequests, http.StatusTooManyRequests}, codes)
+	assert.Equal(t, float64(2), testutil.ToFloat64(metrics.Blocked("login", time.Minute.String())))
+	assert.Equal(t, float64(0), testutil.ToFloat64(metrics.Blocked("custom", time.Minute.String())))
+}
@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimiter_CustomResponseFormatter(t *testing.T) {
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	custom := func(c *gin.Context, info middleware.RateLimitInfo) interface{} {
+		return gin.H{"slow_down": true, "limit": info.Limit}
+	}
+
+	tests := []struct {
+		name     string
+		limiter  *middleware.RateLimiter
+		format   middleware.ResponseFormatter
+		expected map[string]interface{}
+	}{
+		{
+			name:     "limiter-wide formatter",
+			limiter:  middleware.NewRateLimiter(redisClient, &config.Config{}, logger, middleware.WithResponseFormatter(custom)),
+			expected: map[string]interface{}{"slow_down": true, "limit": float64(1)},
+		},
+		{
+			name:     "per-limit formatter overrides limiter",
+			limiter:  middleware.NewRateLimiter(redisClient, &config.Config{}, logger, middleware.WithResponseFormatter(custom)),
+			format:   middleware.MinimalResponseFormatter,
+			expected: map[string]interface{}{"code": "RATE_LIMIT_EXCEEDED"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(tt.limiter.RateLimit(middleware.RateLimitConfig{
+				Requests:       1,
+				Window:         time.Minute,
+				KeyFunc:        middleware.IPKeyFunc("formatter:" + tt.name),
+				FormatResponse: tt.format,
+			}))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			// Assert - custom body, standard headers
+			assert.Equal(t, http.StatusTooManyRequests, w.Code)
+			assert.Equal(t, "1", w.Header().Get("X-RateLimit-Limit"))
+			assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+			assert.NotEmpty(t, w.Header().Get("X-RateLimit-Reset"))
+			assert.NotEmpty(t, w.Header().Get("Retry-After"))
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expected, body)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimiter_RetryAfter(t *testing.T) {
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"))
+
+	tests := []struct {
+		name     string
+		limit    gin.HandlerFunc
+		requests int
+		window   time.Duration
+	}{
+		{
+			name: "RateLimit",
+			limit: rateLimiter.RateLimit(middleware.RateLimitConfig{
+				Requests: 1,
+				Window:   time.Minute,
+				KeyFunc:  middleware.IPKeyFunc("retry-after"),
+			}),
+			requests: 1,
+			window:   time.Minute,
+		},
+		{name: "AuthRateLimit", limit: rateLimiter.AuthRateLimit(), requests: 5, window: time.Minute},
+		{name: "StrictRateLimit", limit: rateLimiter.StrictRateLimit(), requests: 10, window: time.Hour},
+		{name: "ProgressiveRateLimit", limit: rateLimiter.ProgressiveRateLimit(), requests: 10, window: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := gin.New()
+			router.Use(tt.limit)
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			for i := 0; i < tt.requests; i++ {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+				require.Equal(t, http.StatusOK, w.Code)
+			}
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			expected := int(math.Ceil(time.Until(time.Now().Truncate(tt.window).Add(tt.window)).Seconds()))
+
+			// Assert
+			require.Equal(t, http.StatusTooManyRequests, w.Code)
+
+			retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+			require.NoError(t, err, "Retry-After must be whole seconds")
+			assert.GreaterOrEqual(t, retryAfter, 1)
+			assert.InDelta(t, expected, retryAfter, 1)
+
+			var body struct {
+				RetryAfterSeconds int `json:"retry_after_seconds"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, retryAfter, body.RetryAfterSeconds)
+		})
+	}
+}
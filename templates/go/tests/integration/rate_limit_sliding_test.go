@@ -0,0 +1,87 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimiter_WindowBoundaryBurst(t *testing.T) {
+	const limit = 5
+	const window = 2 * time.Second
+
+	tests := []struct {
+		name            string
+		sliding         bool
+		expectedAllowed int
+	}{
+		{name: "fixed window allows a double burst", sliding: false, expectedAllowed: 2 * limit},
+		{name: "sliding window holds the limit", sliding: true, expectedAllowed: limit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			gin.SetMode(gin.TestMode)
+			rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"))
+			router := gin.New()
+			router.Use(rateLimiter.RateLimit(middleware.RateLimitConfig{
+				Requests: limit,
+				Window:   window,
+				KeyFunc:  middleware.IPKeyFunc("boundary"),
+				Sliding:  tt.sliding,
+			}))
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var allowedAt []time.Time
+			burst := func() {
+				for i := 0; i < limit; i++ {
+					w := httptest.NewRecorder()
+					router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+					if w.Code == http.StatusOK {
+						allowedAt = append(allowedAt, time.Now())
+					}
+				}
+			}
+
+			// Act - one burst just before a fixed window boundary, one just after
+			boundary := time.Now().Truncate(window).Add(window)
+			if time.Until(boundary) < 500*time.Millisecond {
+				boundary = boundary.Add(window)
+			}
+			time.Sleep(time.Until(boundary.Add(-300 * time.Millisecond)))
+			burst()
+			time.Sleep(time.Until(boundary.Add(100 * time.Millisecond)))
+			burst()
+
+			// Assert
+			assert.Len(t, allowedAt, tt.expectedAllowed)
+
+			if tt.sliding {
+				for i, start := range allowedAt {
+					inWindow := 0
+					for _, at := range allowedAt[i:] {
+						if at.Sub(start) < window {
+							inWindow++
+						}
+					}
+					assert.LessOrEqual(t, inWindow, limit, "no rolling window may exceed the limit")
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func newTokenBucketRouter(t *testing.T, rate float64, burst int) *gin.Engine {
+	redisClient := setupTestRedis(t)
+	t.Cleanup(func() { teardownTestRedis(t, redisClient) })
+
+	gin.SetMode(gin.TestMode)
+	rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{}, utils.NewLogger("error", "test"))
+	router := gin.New()
+	router.Use(rateLimiter.TokenBucketRateLimit(rate, burst))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+func serveTokenBucket(router *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	return w
+}
+
+func TestRateLimiter_TokenBucket_BurstAfterIdle(t *testing.T) {
+	// Arrange
+	const rate, burst = 2.0, 5
+	router := newTokenBucketRouter(t, rate, burst)
+
+	drain := func() {
+		for i := 0; i < burst; i++ {
+			w := serveTokenBucket(router)
+			require.Equal(t, http.StatusOK, w.Code, "request %d of the burst", i+1)
+			assert.Equal(t, strconv.Itoa(burst-i-1), w.Header().Get("X-RateLimit-Remaining"))
+		}
+		w := serveTokenBucket(router)
+		require.Equal(t, http.StatusTooManyRequests, w.Code, "the bucket is empty after a full burst")
+		assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	}
+
+	// Act & Assert - a fresh bucket allows a full burst
+	drain()
+
+	// Act & Assert - after idling long enough to refill, it does again
+	time.Sleep(time.Duration(float64(burst)/rate*float64(time.Second)) + 100*time.Millisecond)
+	drain()
+}
+
+func TestRateLimiter_TokenBucket_SteadyStateRate(t *testing.T) {
+	// Arrange
+	const rate, burst = 10.0, 1
+	const duration = 2 * time.Second
+	router := newTokenBucketRouter(t, rate, burst)
+
+	require.Equal(t, http.StatusOK, serveTokenBucket(router).Code)
+	require.Equal(t, http.StatusTooManyRequests, serveTokenBucket(router).Code, "the single token is spent")
+
+	// Act - hammer the empty bucket so only refilled tokens get through
+	allowed := 0
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		if serveTokenBucket(router).Code == http.StatusOK {
+			allowed++
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Assert
+	assert.InDelta(t, rate*duration.Seconds(), allowed, 2)
+}
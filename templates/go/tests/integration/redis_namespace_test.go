@@ -0,0 +1,111 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestSessionService_KeyPrefix(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	userID := uuid.New()
+	first := auth.NewSessionService(redisClient, time.Hour, auth.WithKeyPrefix("app1:"))
+	second := auth.NewSessionService(redisClient, time.Hour, auth.WithKeyPrefix("app2:"))
+
+	// Act
+	sessionID, err := first.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+
+	// Assert
+	keys, err := redisClient.Keys(ctx, "app1:session:*").Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app1:session:" + sessionID}, keys)
+
+	_, err = second.GetSession(ctx, sessionID)
+	assert.Error(t, err)
+
+	count, err := second.GetActiveSessionCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestRateLimiter_KeyPrefix(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	newRouter := func(prefix string) *gin.Engine {
+		rateLimiter := middleware.NewRateLimiter(redisClient, &config.Config{RedisKeyPrefix: prefix}, logger)
+		router := gin.New()
+		router.Use(rateLimiter.RateLimit(middleware.RateLimitConfig{
+			Requests: 1,
+			Window:   time.Minute,
+			KeyFunc:  middleware.IPKeyFunc("test"),
+		}))
+		router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+		return router
+	}
+	first, second := newRouter("app1:"), newRouter("app2:")
+
+	serve := func(router *gin.Engine) int {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+
+	// Act & Assert
+	assert.Equal(t, http.StatusOK, serve(first))
+	assert.Equal(t, http.StatusTooManyRequests, serve(first))
+	assert.Equal(t, http.StatusOK, serve(second), "a different prefix must have its own counter")
+
+	keys, err := redisClient.Keys(context.Background(), "app1:rate_limit:test:*").Result()
+	require.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func TestRedisTokenBlacklist_KeyPrefix(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	first := auth.NewRedisTokenBlacklist(redisClient, "app1:")
+	second := auth.NewRedisTokenBlacklist(redisClient, "app2:")
+
+	// Act
+	err := first.BlacklistToken("token-id", time.Now().Add(time.Hour))
+	require.NoError(t, err)
+
+	// Assert
+	blacklisted, err := first.IsTokenBlacklisted("token-id")
+	require.NoError(t, err)
+	assert.True(t, blacklisted)
+
+	blacklisted, err = second.IsTokenBlacklisted("token-id")
+	require.NoError(t, err)
+	assert.False(t, blacklisted)
+
+	exists, err := redisClient.Exists(context.Background(), "app1:blacklist:token-id").Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), exists)
+}
@@ -0,0 +1,87 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_RefreshToken_GracePeriod(t *testing.T) {
+	tests := []struct {
+		name         string
+		graceSeconds int
+		rotatedAgo   time.Duration
+		expectGrace  bool
+	}{
+		{name: "within the grace period", graceSeconds: 30, rotatedAgo: 0, expectGrace: true},
+		{name: "after the grace period", graceSeconds: 30, rotatedAgo: time.Minute, expectGrace: false},
+		{name: "grace disabled", graceSeconds: 0, rotatedAgo: 0, expectGrace: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+			require.NoError(t, err)
+			user, err := createTestUser(db, "grace@example.com", "grace", "user")
+			require.NoError(t, err)
+			require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+			ctx := context.Background()
+			authService := newTestAuthService(db, redisClient,
+				auth.NewJWTService("test-secret", "test-issuer", 1),
+				auth.NewSessionService(redisClient, time.Hour),
+				&config.Config{Environment: "test", RefreshTokenRotation: true, RefreshTokenGraceSeconds: tt.graceSeconds},
+			)
+
+			resp, err := authService.Login(ctx, &models.LoginRequest{
+				Login:    "grace@example.com",
+				Password: "Str0ng!Passw0rd",
+			}, "127.0.0.1", "test-agent")
+			require.NoError(t, err)
+			original := resp.RefreshToken
+
+			lost, err := authService.RefreshToken(ctx, original, "127.0.0.1", "test-agent")
+			require.NoError(t, err)
+			require.NoError(t, db.Model(&models.RefreshToken{}).
+				Where("token = ?", models.HashRefreshToken(original)).
+				Update("rotated_at", time.Now().Add(-tt.rotatedAgo)).Error)
+
+			// Act - the client never stored the successor and retries
+			retried, err := authService.RefreshToken(ctx, original, "127.0.0.1", "test-agent")
+
+			// Assert
+			if !tt.expectGrace {
+				assert.ErrorIs(t, err, services.ErrRefreshTokenReuse)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEqual(t, lost.RefreshToken, retried.RefreshToken)
+
+			_, err = authService.RefreshToken(ctx, lost.RefreshToken, "127.0.0.1", "test-agent")
+			assert.Error(t, err, "the successor the client never received is revoked")
+
+			_, err = authService.RefreshToken(ctx, original, "127.0.0.1", "test-agent")
+			assert.ErrorIs(t, err, services.ErrRefreshTokenReuse, "the grace can only be used once")
+
+			_, err = authService.RefreshToken(ctx, retried.RefreshToken, "127.0.0.1", "test-agent")
+			assert.Error(t, err, "a second reuse revokes the whole family")
+		})
+	}
+}
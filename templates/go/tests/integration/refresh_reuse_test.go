@@ -0,0 +1,118 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_RefreshToken_ReuseRevokesFamily(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "reuse@example.com", "reuse", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		sessionService,
+		&config.Config{Environment: "test", RefreshTokenRotation: true},
+	)
+
+	login := func() *models.AuthResponse {
+		resp, err := authService.Login(ctx, &models.LoginRequest{
+			Login:    "reuse@example.com",
+			Password: "Str0ng!Passw0rd",
+		}, "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		return resp
+	}
+
+	// The attacker steals the first refresh token, then the legitimate
+	// client rotates it twice
+	stolen := login().RefreshToken
+	otherDevice := login().RefreshToken
+
+	rotated, err := authService.RefreshToken(ctx, stolen, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	latest, err := authService.RefreshToken(ctx, rotated.RefreshToken, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Act
+	_, err = authService.RefreshToken(ctx, stolen, "10.6.6.6", "attacker-agent")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrRefreshTokenReuse)
+
+	_, err = authService.RefreshToken(ctx, latest.RefreshToken, "127.0.0.1", "test-agent")
+	assert.Error(t, err, "every token in the family must be revoked")
+
+	var familyTokens []models.RefreshToken
+	require.NoError(t, db.Where("user_id = ? AND token <> ?", user.ID, models.HashRefreshToken(otherDevice)).Find(&familyTokens).Error)
+	require.Len(t, familyTokens, 3)
+	for _, token := range familyTokens {
+		assert.True(t, token.IsRevoked)
+		assert.Equal(t, familyTokens[0].FamilyID, token.FamilyID)
+	}
+
+	count, err := sessionService.GetActiveSessionCount(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count, "the user's sessions must be deleted")
+
+	_, err = authService.RefreshToken(ctx, otherDevice, "127.0.0.1", "test-agent")
+	assert.NoError(t, err, "tokens from other logins are left alone")
+}
+
+func TestAuthService_RefreshToken_ReuseOfLegacyToken(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	user, err := createTestUser(db, "legacy@example.com", "legacy", "user")
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test", RefreshTokenRotation: true},
+	)
+
+	legacy := &models.RefreshToken{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour), IsRevoked: true}
+	other := &models.RefreshToken{UserID: user.ID, ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, db.Create(legacy).Error)
+	require.NoError(t, db.Create(other).Error)
+	// Tokens issued before families existed have no family ID
+	require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Update("family_id", nil).Error)
+
+	// Act
+	_, err = authService.RefreshToken(ctx, legacy.Token, "127.0.0.1", "test-agent")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrRefreshTokenReuse)
+
+	var remaining int64
+	require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ? AND is_revoked = ?", user.ID, false).Count(&remaining).Error)
+	assert.Zero(t, remaining, "without a family every token of the user is revoked")
+}
@@ -0,0 +1,77 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_RefreshToken_Rotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		rotation bool
+	}{
+		{name: "rotation enabled", rotation: true},
+		{name: "rotation disabled", rotation: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+			require.NoError(t, err)
+			user, err := createTestUser(db, "refresh@example.com", "refresh", "user")
+			require.NoError(t, err)
+			require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+			ctx := context.Background()
+			authService := newTestAuthService(db, redisClient,
+				auth.NewJWTService("test-secret", "test-issuer", 1),
+				auth.NewSessionService(redisClient, time.Hour),
+				&config.Config{Environment: "test", RefreshTokenRotation: tt.rotation},
+			)
+
+			loginResp, err := authService.Login(ctx, &models.LoginRequest{
+				Login:    "refresh@example.com",
+				Password: "Str0ng!Passw0rd",
+			}, "127.0.0.1", "test-agent")
+			require.NoError(t, err)
+			original := loginResp.RefreshToken
+
+			// Act
+			refreshResp, err := authService.RefreshToken(ctx, original, "127.0.0.1", "test-agent")
+
+			// Assert
+			require.NoError(t, err)
+			assert.NotEmpty(t, refreshResp.AccessToken)
+
+			_, reuseErr := authService.RefreshToken(ctx, original, "127.0.0.1", "test-agent")
+			if tt.rotation {
+				assert.NotEqual(t, original, refreshResp.RefreshToken)
+				assert.ErrorIs(t, reuseErr, services.ErrRefreshTokenReuse, "the rotated-out token must be revoked")
+
+				_, err = authService.RefreshToken(ctx, refreshResp.RefreshToken, "127.0.0.1", "test-agent")
+				assert.Error(t, err, "reuse revokes the rest of the token family")
+			} else {
+				assert.Equal(t, original, refreshResp.RefreshToken)
+				assert.NoError(t, reuseErr, "the same token stays valid until it expires")
+			}
+		})
+	}
+}
@@ -0,0 +1,107 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/database"
+	"app/internal/models"
+)
+
+func TestAuthService_RefreshTokenStoredAsHash(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "hashed@example.com", "hashed", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test", RefreshTokenRotation: true},
+	)
+
+	// Act
+	resp, err := authService.Login(ctx, &models.LoginRequest{
+		Login:    "hashed@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Assert - the column holds the hash, never the raw token
+	var stored []string
+	require.NoError(t, db.Model(&models.RefreshToken{}).Where("user_id = ?", user.ID).Pluck("token", &stored).Error)
+	require.Len(t, stored, 1)
+	assert.Equal(t, models.HashRefreshToken(resp.RefreshToken), stored[0])
+	assert.NotEqual(t, resp.RefreshToken, stored[0])
+
+	// Act & Assert - refreshing with the raw token still works
+	refreshed, err := authService.RefreshToken(ctx, resp.RefreshToken, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.RefreshToken)
+
+	var successor models.RefreshToken
+	require.NoError(t, db.Where("token = ?", models.HashRefreshToken(refreshed.RefreshToken)).First(&successor).Error)
+	assert.False(t, successor.IsRevoked)
+	assert.Empty(t, successor.Token, "the raw token is not loaded back from the database")
+
+	// Act & Assert - presenting the stored hash is not accepted as a token
+	_, err = authService.RefreshToken(ctx, models.HashRefreshToken(refreshed.RefreshToken), "127.0.0.1", "test-agent")
+	assert.ErrorContains(t, err, "invalid refresh token")
+}
+
+func TestMigrate_HashesLegacyRefreshTokens(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	user, err := createTestUser(db, "legacy@example.com", "legacy", "user")
+	require.NoError(t, err)
+
+	// A row written before tokens were hashed holds the raw token
+	const rawToken = "legacy-plaintext-refresh-token"
+	legacyID := uuid.New()
+	require.NoError(t, db.Exec(
+		`INSERT INTO refresh_tokens (id, token, token_hashed, user_id, expires_at, family_id, created_at, updated_at)
+		VALUES (?, ?, false, ?, ?, ?, NOW(), NOW())`,
+		legacyID, rawToken, user.ID, time.Now().Add(time.Hour), legacyID).Error)
+
+	// Act - migrating twice must not hash the hash
+	require.NoError(t, database.Migrate(db))
+	require.NoError(t, database.Migrate(db))
+
+	// Assert
+	var legacy models.RefreshToken
+	require.NoError(t, db.First(&legacy, "id = ?", legacyID).Error)
+	assert.Equal(t, models.HashRefreshToken(rawToken), legacy.TokenHash)
+	assert.True(t, legacy.TokenHashed)
+
+	// Act & Assert - the raw token is still accepted after the migration
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test", RefreshTokenRotation: true},
+	)
+	refreshed, err := authService.RefreshToken(context.Background(), rawToken, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.NotEmpty(t, refreshed.AccessToken)
+}
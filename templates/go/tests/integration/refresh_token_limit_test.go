@@ -0,0 +1,75 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+func TestAuthService_RefreshTokenLimit(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "limit@example.com", "limit", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	ctx := context.Background()
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test", RefreshTokenRotation: true, MaxRefreshTokensPerUser: 2},
+	)
+
+	login := func() string {
+		resp, err := authService.Login(ctx, &models.LoginRequest{
+			Login:    "limit@example.com",
+			Password: "Str0ng!Passw0rd",
+		}, "127.0.0.1", "test-agent")
+		require.NoError(t, err)
+		return resp.RefreshToken
+	}
+
+	oldest := login()
+	middle := login()
+
+	// Act
+	newest := login()
+
+	// Assert - only the oldest token is revoked
+	isRevoked := func(token string) bool {
+		var refreshToken models.RefreshToken
+		require.NoError(t, db.Where("token = ?", models.HashRefreshToken(token)).First(&refreshToken).Error)
+		return refreshToken.IsRevoked
+	}
+	assert.True(t, isRevoked(oldest))
+	assert.False(t, isRevoked(middle))
+	assert.False(t, isRevoked(newest))
+
+	// An evicted token is refused without being treated as stolen
+	_, err = authService.RefreshToken(ctx, oldest, "127.0.0.1", "test-agent")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, services.ErrRefreshTokenReuse)
+
+	// Rotating a token at the limit does not evict the user's other token
+	rotated, err := authService.RefreshToken(ctx, middle, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	assert.False(t, isRevoked(rotated.RefreshToken))
+	assert.False(t, isRevoked(newest))
+}
@@ -0,0 +1,50 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_RegisterBindsTokenToClientIP(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithIPBinding(32, 128))
+	authService := newTestAuthService(db, redisClient,
+		jwtService,
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Act
+	resp, err := authService.Register(context.Background(), &models.UserCreateRequest{
+		Email:     "bound@example.com",
+		Username:  "bound",
+		Password:  "Tz9!mVq#Lw4k",
+		FirstName: "Bound",
+		LastName:  "User",
+	}, "203.0.113.7", "test-agent")
+
+	// Assert
+	require.NoError(t, err)
+
+	claims, err := jwtService.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.7", claims.IP)
+	assert.NoError(t, jwtService.CheckIPBinding(claims, "203.0.113.7"))
+	assert.ErrorIs(t, jwtService.CheckIPBinding(claims, "198.51.100.7"), auth.ErrTokenIPMismatch)
+}
@@ -0,0 +1,65 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_RegisterReturnsPersistedTimestamps(t *testing.T) {
+	tests := []struct {
+		name              string
+		requireActivation bool
+	}{
+		{name: "active account", requireActivation: false},
+		{name: "pending activation", requireActivation: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			authService := newTestAuthService(db, redisClient,
+				auth.NewJWTService("test-secret", "test-issuer", 1),
+				auth.NewSessionService(redisClient, time.Hour),
+				&config.Config{Environment: "test", RequireAccountActivation: tt.requireActivation},
+			)
+			before := time.Now().Add(-time.Minute)
+
+			// Act
+			resp, err := authService.Register(context.Background(), &models.UserCreateRequest{
+				Email:     "stamped@example.com",
+				Username:  "stamped",
+				Password:  "Tz9!mVq#Lw4k",
+				FirstName: "Stamped",
+				LastName:  "User",
+			}, "127.0.0.1", "test-agent")
+
+			// Assert
+			require.NoError(t, err)
+			assert.False(t, resp.User.CreatedAt.IsZero())
+			assert.False(t, resp.User.UpdatedAt.IsZero())
+			assert.True(t, resp.User.CreatedAt.After(before))
+			assert.False(t, resp.User.UpdatedAt.Before(resp.User.CreatedAt))
+
+			var stored models.User
+			require.NoError(t, db.Where("id = ?", resp.User.ID).First(&stored).Error)
+			assert.True(t, stored.CreatedAt.Equal(resp.User.CreatedAt), "created_at should match the stored value")
+			assert.True(t, stored.UpdatedAt.Equal(resp.User.UpdatedAt), "updated_at should match the stored value")
+		})
+	}
+}
@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+)
+
+func TestAuthService_RegisterRollsBackOnFailure(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	// Fail the last write of the registration, after the user and role rows
+	require.NoError(t, db.Callback().Create().Before("gorm:create").Register("test:fail_refresh_token", func(tx *gorm.DB) {
+		if tx.Statement.Table == "refresh_tokens" {
+			tx.AddError(errors.New("forced failure"))
+		}
+	}))
+
+	// Act
+	_, err := authService.Register(context.Background(), &models.UserCreateRequest{
+		Email:     "rollback@example.com",
+		Username:  "rollback",
+		Password:  "Tz9!mVq#Lw4k",
+		FirstName: "Roll",
+		LastName:  "Back",
+	}, "127.0.0.1", "test-agent")
+
+	// Assert - nothing written during the registration survives
+	require.ErrorContains(t, err, "forced failure")
+
+	var users int64
+	require.NoError(t, db.Model(&models.User{}).Where("email = ?", "rollback@example.com").Count(&users).Error)
+	assert.Equal(t, int64(0), users)
+
+	var userRoles int64
+	require.NoError(t, db.Model(&models.UserRole{}).Count(&userRoles).Error)
+	assert.Equal(t, int64(0), userRoles)
+
+	var refreshTokens int64
+	require.NoError(t, db.Model(&models.RefreshToken{}).Count(&refreshTokens).Error)
+	assert.Equal(t, int64(0), refreshTokens)
+}
@@ -0,0 +1,58 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestRateLimitError_CarriesRequestID(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	logger := utils.NewLogger("error", "test")
+	securityMiddleware := middleware.NewSecurityMiddleware(cfg, logger)
+	rateLimiter := middleware.NewRateLimiter(redisClient, cfg, logger)
+
+	router := gin.New()
+	router.Use(securityMiddleware.RequestID())
+	router.Use(rateLimiter.RateLimit(middleware.RateLimitConfig{
+		Requests: 1,
+		Window:   time.Minute,
+		KeyFunc:  middleware.IPKeyFunc("request-id-test"),
+	}))
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "rate-limited-req")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "RATE_LIMIT_EXCEEDED", body["code"])
+	assert.Equal(t, "rate-limited-req", body["request_id"])
+}
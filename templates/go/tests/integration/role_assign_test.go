@@ -0,0 +1,76 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_AssignRoleIsIdempotent(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+
+	holder, err := createTestUser(db, "holder@example.com", "holder", "user", "moderator")
+	require.NoError(t, err)
+	other, err := createTestUser(db, "other@example.com", "other", "user")
+	require.NoError(t, err)
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+
+	type snapshot struct {
+		assignments  int64
+		history      int64
+		tokenVersion int
+	}
+	take := func(userID uuid.UUID) snapshot {
+		var s snapshot
+		require.NoError(t, db.Model(&models.UserRole{}).Where("user_id = ? AND role_id = ?", userID, moderator.ID).Count(&s.assignments).Error)
+		require.NoError(t, db.Model(&models.RoleAssignmentHistory{}).Where("user_id = ? AND role_id = ?", userID, moderator.ID).Count(&s.history).Error)
+		version, err := userRepo.GetTokenVersion(ctx, userID)
+		require.NoError(t, err)
+		s.tokenVersion = version
+		return s
+	}
+
+	t.Run("repeat assignment", func(t *testing.T) {
+		before := take(holder.ID)
+
+		// Act
+		err := userRepo.AssignRole(ctx, holder.ID, moderator.ID, nil, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, before, take(holder.ID), "no duplicate row, history entry or token version bump")
+	})
+
+	t.Run("bulk assignment skips holders", func(t *testing.T) {
+		holderBefore := take(holder.ID)
+		otherBefore := take(other.ID)
+
+		// Act
+		err := userRepo.BulkAssignRole(ctx, []uuid.UUID{holder.ID, other.ID, other.ID}, moderator.ID, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, holderBefore, take(holder.ID))
+
+		otherAfter := take(other.ID)
+		assert.Equal(t, int64(1), otherAfter.assignments)
+		assert.Equal(t, otherBefore.history+1, otherAfter.history)
+		assert.Equal(t, otherBefore.tokenVersion+1, otherAfter.tokenVersion)
+	})
+}
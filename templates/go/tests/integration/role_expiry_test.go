@@ -0,0 +1,95 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_ExpiredRoleGrantsNothing(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+
+	user, err := createTestUser(db, "expired@example.com", "expired", "user")
+	require.NoError(t, err)
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, userRepo.AssignRole(ctx, user.ID, moderator.ID, nil, &past))
+
+	// Act & Assert
+	hasRole, err := userRepo.HasRole(ctx, user.ID, "moderator")
+	require.NoError(t, err)
+	assert.False(t, hasRole)
+
+	hasPermission, err := userRepo.HasPermission(ctx, user.ID, "content:moderate")
+	require.NoError(t, err)
+	assert.False(t, hasPermission)
+
+	moderators, err := userRepo.GetUsersWithRole(ctx, "moderator")
+	require.NoError(t, err)
+	for _, moderatorUser := range moderators {
+		assert.NotEqual(t, user.ID, moderatorUser.ID, "expired holders must not be listed")
+	}
+
+	roles, err := userRepo.GetUserRoles(ctx, user.ID)
+	require.NoError(t, err)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "user", roles[0].Name)
+
+	loaded, err := userRepo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, loaded.HasRole("moderator"), "preloaded roles feed login and token claims")
+	assert.True(t, loaded.HasRole("user"))
+}
+
+func TestUserRepository_AssignRoleWithExpiry(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+
+	user, err := createTestUser(db, "temporary@example.com", "temporary", "user")
+	require.NoError(t, err)
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+
+	past := time.Now().Add(-time.Hour)
+	require.NoError(t, userRepo.AssignRole(ctx, user.ID, moderator.ID, nil, &past))
+
+	// Act - granting the role again replaces the expired assignment
+	future := time.Now().Add(time.Hour)
+	err = userRepo.AssignRole(ctx, user.ID, moderator.ID, nil, &future)
+
+	// Assert
+	require.NoError(t, err)
+
+	hasRole, err := userRepo.HasRole(ctx, user.ID, "moderator")
+	require.NoError(t, err)
+	assert.True(t, hasRole)
+
+	var assignments []models.UserRole
+	require.NoError(t, db.Where("user_id = ? AND role_id = ?", user.ID, moderator.ID).Find(&assignments).Error)
+	require.Len(t, assignments, 1)
+	require.NotNil(t, assignments[0].ExpiresAt)
+	assert.WithinDuration(t, future, *assignments[0].ExpiresAt, time.Second)
+	assert.True(t, assignments[0].IsActive())
+}
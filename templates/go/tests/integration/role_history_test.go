@@ -0,0 +1,89 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/postgres"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestRoleHistory_RecordsAssignAndRevokeWithActor(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+	userService := services.NewUserService(userRepo, utils.NewLogger("error", "test"))
+
+	admin, err := createTestUser(db, "granter@example.com", "granter", "admin")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "grantee@example.com", "grantee", "user")
+	require.NoError(t, err)
+
+	var adminRole models.Role
+	require.NoError(t, db.Where("name = ?", "admin").First(&adminRole).Error)
+
+	// Act
+	require.NoError(t, userRepo.AssignRole(ctx, user.ID, adminRole.ID, &admin.ID, nil))
+
+	var granted models.UserRole
+	require.NoError(t, db.Where("user_id = ? AND role_id = ?", user.ID, adminRole.ID).First(&granted).Error)
+
+	require.NoError(t, userRepo.RevokeRole(ctx, user.ID, adminRole.ID, &admin.ID))
+
+	// Assert
+	history, err := userService.GetRoleHistory(ctx, user.ID)
+	require.NoError(t, err)
+
+	var adminChanges []*models.RoleAssignmentHistory
+	for _, entry := range history {
+		if entry.RoleID == adminRole.ID {
+			adminChanges = append(adminChanges, entry)
+		}
+	}
+	require.Len(t, adminChanges, 2)
+
+	actions := []string{adminChanges[0].Action, adminChanges[1].Action}
+	assert.ElementsMatch(t, []string{models.RoleAssignmentGranted, models.RoleAssignmentRevoked}, actions)
+	for _, entry := range adminChanges {
+		require.NotNil(t, entry.ActorID)
+		assert.Equal(t, admin.ID, *entry.ActorID)
+		assert.Equal(t, "admin", entry.RoleName)
+	}
+	assert.Equal(t, admin.ID, granted.GrantedBy)
+}
+
+func TestRoleHistory_RevokeWithoutAssignmentRecordsNothing(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+
+	user, err := createTestUser(db, "norole@example.com", "norole", "user")
+	require.NoError(t, err)
+
+	var adminRole models.Role
+	require.NoError(t, db.Where("name = ?", "admin").First(&adminRole).Error)
+
+	// Act
+	require.NoError(t, userRepo.RevokeRole(ctx, user.ID, adminRole.ID, nil))
+
+	// Assert
+	history, err := userRepo.GetRoleAssignmentHistory(ctx, user.ID)
+	require.NoError(t, err)
+	for _, entry := range history {
+		assert.NotEqual(t, adminRole.ID, entry.RoleID)
+	}
+}
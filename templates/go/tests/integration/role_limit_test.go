@@ -0,0 +1,71 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_MaxRolesPerUser(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db, postgres.WithMaxRolesPerUser(2))
+
+	roleIDs := map[string]uuid.UUID{}
+	for _, name := range []string{"admin", "moderator"} {
+		var role models.Role
+		require.NoError(t, db.Where("name = ?", name).First(&role).Error)
+		roleIDs[name] = role.ID
+	}
+
+	full, err := createTestUser(db, "full@example.com", "full", "user", "moderator")
+	require.NoError(t, err)
+	single, err := createTestUser(db, "single@example.com", "single", "user")
+	require.NoError(t, err)
+
+	countRoles := func(userID uuid.UUID) int64 {
+		var count int64
+		require.NoError(t, db.Model(&models.UserRole{}).Where("user_id = ?", userID).Count(&count).Error)
+		return count
+	}
+
+	t.Run("assignment over the cap is rejected", func(t *testing.T) {
+		// Act
+		err := userRepo.AssignRole(ctx, full.ID, roleIDs["admin"], nil, nil)
+
+		// Assert
+		assert.ErrorIs(t, err, interfaces.ErrTooManyRoles)
+		assert.Equal(t, int64(2), countRoles(full.ID))
+	})
+
+	t.Run("bulk assignment is rejected as a whole", func(t *testing.T) {
+		// Act
+		err := userRepo.BulkAssignRole(ctx, []uuid.UUID{single.ID, full.ID}, roleIDs["admin"], nil)
+
+		// Assert
+		assert.ErrorIs(t, err, interfaces.ErrTooManyRoles)
+		assert.Equal(t, int64(1), countRoles(single.ID), "no user gets the role when any would exceed the cap")
+	})
+
+	t.Run("assignment up to the cap succeeds", func(t *testing.T) {
+		// Act
+		err := userRepo.AssignRole(ctx, single.ID, roleIDs["moderator"], nil, nil)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), countRoles(single.ID))
+	})
+}
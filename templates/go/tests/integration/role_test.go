@@ -0,0 +1,82 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/repository/interfaces"
+	"app/internal/repository/postgres"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestRoleRepository_List_FiltersByName(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	_, err := createTestRole(db, "support", "Support staff", []string{"user:read"})
+	require.NoError(t, err)
+
+	repo := postgres.NewRoleRepository(db)
+
+	// Act
+	roles, total, err := repo.List(context.Background(), interfaces.RoleFilters{Name: "MOD"}, 0, 10)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+	require.Len(t, roles, 1)
+	assert.Equal(t, "moderator", roles[0].Name)
+}
+
+func TestRoleRepository_List_Paginates(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	_, err := createTestRole(db, "editor", "Content editor", []string{"content:update"})
+	require.NoError(t, err)
+	_, err = createTestRole(db, "support", "Support staff", []string{"user:read"})
+	require.NoError(t, err)
+
+	repo := postgres.NewRoleRepository(db)
+
+	// Act - roles sort by name: admin, editor, moderator, support, user
+	roles, total, err := repo.List(context.Background(), interfaces.RoleFilters{}, 2, 2)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), total)
+	require.Len(t, roles, 2)
+	assert.Equal(t, "moderator", roles[0].Name)
+	assert.Equal(t, "support", roles[1].Name)
+}
+
+func TestRoleService_ListRoles_IncludesUserCounts(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	_, err := createTestUser(db, "first@example.com", "first", "user")
+	require.NoError(t, err)
+	_, err = createTestUser(db, "second@example.com", "second", "user")
+	require.NoError(t, err)
+
+	roleService := services.NewRoleService(postgres.NewRoleRepository(db), postgres.NewAuditLogRepository(db), utils.NewLogger("error", "test"))
+
+	// Act
+	page, err := roleService.ListRoles(context.Background(), interfaces.RoleFilters{Name: "user"}, 1, 10)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, 2, page.Items[0].UserCount)
+	assert.Equal(t, 1, page.TotalPages)
+}
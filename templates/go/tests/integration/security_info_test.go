@@ -0,0 +1,64 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/postgres"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestUserService_SecurityInfoReflectsPasswordChange(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	passwordService := auth.NewPasswordService(4)
+	hash, err := passwordService.HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+
+	user, err := createTestUser(db, "security@example.com", "security", "user")
+	require.NoError(t, err)
+	previousChange := time.Now().Add(-30 * 24 * time.Hour)
+	require.NoError(t, db.Model(user).Updates(map[string]interface{}{
+		"password_hash":       hash,
+		"password_changed_at": previousChange,
+	}).Error)
+
+	userService := services.NewUserService(postgres.NewUserRepository(db), utils.NewLogger("error", "test"))
+	authService := newTestAuthService(db, redisClient,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewSessionService(redisClient, time.Hour),
+		&config.Config{Environment: "test"},
+	)
+
+	before, err := userService.GetSecurityInfo(ctx, user.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, previousChange, before.PasswordChangedAt, time.Second)
+
+	// Act
+	changedAfter := time.Now().Add(-time.Second)
+	require.NoError(t, authService.ChangePassword(ctx, user.ID, &models.ChangePasswordRequest{
+		CurrentPassword: "Str0ng!Passw0rd",
+		NewPassword:     "N3w!Passw0rd#2",
+	}))
+
+	// Assert
+	after, err := userService.GetSecurityInfo(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, after.PasswordChangedAt.After(changedAfter))
+}
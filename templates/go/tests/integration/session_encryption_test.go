@@ -0,0 +1,141 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+)
+
+func newTestSessionCipher(t *testing.T, seed byte) auth.SessionOption {
+	aead, err := auth.NewSessionCipher(bytes.Repeat([]byte{seed}, 32))
+	require.NoError(t, err)
+	return auth.WithEncryption(aead)
+}
+
+func TestSessionService_EncryptsSessionsAtRest(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, newTestSessionCipher(t, 1))
+	sessionData := &auth.SessionData{
+		UserID:      uuid.New(),
+		Email:       "secret@example.com",
+		Roles:       []string{"admin"},
+		Permissions: []string{"system:admin"},
+	}
+
+	// Act
+	sessionID, err := sessionService.CreateSession(ctx, sessionData)
+	require.NoError(t, err)
+
+	// Assert - Redis only holds ciphertext
+	stored, err := redisClient.Get(ctx, "session:"+sessionID).Bytes()
+	require.NoError(t, err)
+	assert.False(t, json.Valid(stored), "stored session must not be JSON")
+	assert.NotContains(t, string(stored), "secret@example.com")
+	assert.NotContains(t, string(stored), "system:admin")
+
+	// Assert - reads and updates round-trip transparently
+	loaded, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, sessionData.Email, loaded.Email)
+	assert.Equal(t, sessionData.Roles, loaded.Roles)
+	assert.Equal(t, sessionData.Permissions, loaded.Permissions)
+
+	loaded.Metadata = map[string]interface{}{"theme": "dark"}
+	require.NoError(t, sessionService.UpdateSession(ctx, sessionID, loaded))
+
+	updated, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "dark", updated.Metadata["theme"])
+
+	sessions, err := sessionService.GetUserSessions(ctx, sessionData.UserID)
+	require.NoError(t, err)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, sessionID, sessions[0].SessionID)
+}
+
+func TestSessionService_EncryptedSessionRequiresMatchingKey(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, newTestSessionCipher(t, 1))
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New()})
+	require.NoError(t, err)
+	otherID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New()})
+	require.NoError(t, err)
+
+	// Act & Assert - another key cannot read the session
+	_, err = auth.NewSessionService(redisClient, time.Hour, newTestSessionCipher(t, 2)).GetSession(ctx, sessionID)
+	assert.ErrorIs(t, err, auth.ErrSessionDecrypt)
+
+	// Act & Assert - a payload copied under another session ID is rejected
+	payload, err := redisClient.Get(ctx, "session:"+sessionID).Bytes()
+	require.NoError(t, err)
+	require.NoError(t, redisClient.Set(ctx, "session:"+otherID, payload, time.Hour).Err())
+	_, err = sessionService.GetSession(ctx, otherID)
+	assert.ErrorIs(t, err, auth.ErrSessionDecrypt)
+}
+
+func TestSessionService_ReadsPlaintextSessionsDuringMigration(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionID, err := auth.NewSessionService(redisClient, time.Hour).CreateSession(ctx, &auth.SessionData{UserID: uuid.New(), Email: "legacy@example.com"})
+	require.NoError(t, err)
+
+	sessionService := auth.NewSessionService(redisClient, time.Hour,
+		newTestSessionCipher(t, 1),
+		auth.WithPlaintextMigration(time.Now().Add(time.Hour)),
+	)
+
+	// Act
+	loaded, err := sessionService.GetSession(ctx, sessionID)
+
+	// Assert - the legacy session is readable and encrypted on its next write
+	require.NoError(t, err)
+	assert.Equal(t, "legacy@example.com", loaded.Email)
+
+	require.NoError(t, sessionService.UpdateSession(ctx, sessionID, loaded))
+	stored, err := redisClient.Get(ctx, "session:"+sessionID).Bytes()
+	require.NoError(t, err)
+	assert.NotContains(t, string(stored), "legacy@example.com")
+}
+
+func TestSessionService_RejectsPlaintextSessionsOutsideMigration(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionID, err := auth.NewSessionService(redisClient, time.Hour).CreateSession(ctx, &auth.SessionData{UserID: uuid.New(), Email: "legacy@example.com"})
+	require.NoError(t, err)
+
+	// Act & Assert - without a migration window plaintext is rejected
+	_, err = auth.NewSessionService(redisClient, time.Hour, newTestSessionCipher(t, 1)).GetSession(ctx, sessionID)
+	assert.ErrorIs(t, err, auth.ErrSessionDecrypt)
+
+	// Act & Assert - so it is once the migration window has ended
+	_, err = auth.NewSessionService(redisClient, time.Hour,
+		newTestSessionCipher(t, 1),
+		auth.WithPlaintextMigration(time.Now().Add(-time.Minute)),
+	).GetSession(ctx, sessionID)
+	assert.ErrorIs(t, err, auth.ErrSessionDecrypt)
+}
@@ -0,0 +1,216 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+)
+
+func TestSessionService_UserIndex(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID, otherID := uuid.New(), uuid.New()
+
+	var sessionIDs []string
+	for i := 0; i < 3; i++ {
+		sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+		require.NoError(t, err)
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	otherSession, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: otherID})
+	require.NoError(t, err)
+
+	indexKey := "user_sessions:" + userID.String()
+
+	// Act & Assert
+	members, err := redisClient.SMembers(ctx, indexKey).Result()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, sessionIDs, members)
+
+	require.NoError(t, sessionService.DeleteSession(ctx, sessionIDs[0]))
+	isMember, err := redisClient.SIsMember(ctx, indexKey, sessionIDs[0]).Result()
+	require.NoError(t, err)
+	assert.False(t, isMember, "deleting a session must drop it from the index")
+
+	sessions, err := sessionService.GetUserSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 2)
+
+	require.NoError(t, sessionService.DeleteUserSessions(ctx, userID))
+	count, err := sessionService.GetActiveSessionCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	exists, err := redisClient.Exists(ctx, indexKey).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), exists)
+
+	valid, err := sessionService.IsSessionValid(ctx, otherSession)
+	require.NoError(t, err)
+	assert.True(t, valid, "other users' sessions must be untouched")
+}
+
+func TestSessionService_UserIndex_PrunesExpiredSessions(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID := uuid.New()
+
+	live, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+	expired, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+
+	// Simulate the session key expiring while its index entry remains
+	require.NoError(t, redisClient.Del(ctx, "session:"+expired).Err())
+
+	// Act
+	sessions, err := sessionService.GetUserSessions(ctx, userID)
+	require.NoError(t, err)
+
+	// Assert
+	require.Len(t, sessions, 1)
+	assert.Equal(t, live, sessions[0].SessionID)
+
+	members, err := redisClient.SMembers(ctx, "user_sessions:"+userID.String()).Result()
+	require.NoError(t, err)
+	assert.Equal(t, []string{live}, members, "stale members are pruned on read")
+
+	count, err := sessionService.GetActiveSessionCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestSessionService_GetUserSessions_OrderedByLastActivity(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID := uuid.New()
+
+	var sessionIDs []string
+	for i := 0; i < 3; i++ {
+		sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+		require.NoError(t, err)
+		sessionIDs = append(sessionIDs, sessionID)
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Touch the oldest session so it becomes the most recently active
+	time.Sleep(5 * time.Millisecond)
+	session, err := sessionService.GetSession(ctx, sessionIDs[0])
+	require.NoError(t, err)
+	require.NoError(t, sessionService.UpdateSession(ctx, sessionIDs[0], session))
+
+	expected := []string{sessionIDs[0], sessionIDs[2], sessionIDs[1]}
+
+	// Act & Assert - the order holds however Redis returns the index
+	for i := 0; i < 10; i++ {
+		sessions, err := sessionService.GetUserSessions(ctx, userID)
+		require.NoError(t, err)
+
+		got := make([]string, len(sessions))
+		for j, s := range sessions {
+			got[j] = s.SessionID
+		}
+		require.Equal(t, expected, got, "run %d", i+1)
+	}
+}
+
+// scanActiveSessionCount is the keyspace scan GetActiveSessionCount used
+// before sessions were indexed per user, kept for comparison
+func scanActiveSessionCount(ctx context.Context, client *redis.Client, userID uuid.UUID) (int, error) {
+	var cursor uint64
+	var count int
+
+	for {
+		keys, nextCursor, err := client.Scan(ctx, cursor, "session:*", 100).Result()
+		if err != nil {
+			return 0, err
+		}
+
+		for _, key := range keys {
+			sessionJSON, err := client.Get(ctx, key).Result()
+			if err != nil {
+				continue
+			}
+
+			var sessionData auth.SessionData
+			if json.Unmarshal([]byte(sessionJSON), &sessionData) == nil && sessionData.UserID == userID {
+				count++
+			}
+		}
+
+		cursor = nextCursor
+		if cursor == 0 {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+func BenchmarkSessionLookup(b *testing.B) {
+	// Setup
+	ctx := context.Background()
+	redisClient := setupTestRedis(nil)
+	defer teardownTestRedis(nil, redisClient)
+
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID := uuid.New()
+	for i := 0; i < 5; i++ {
+		if _, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID}); err != nil {
+			b.Fatalf("failed to create session: %v", err)
+		}
+	}
+	// Sessions belonging to other users make up the rest of the keyspace
+	for i := 0; i < 5000; i++ {
+		if _, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New()}); err != nil {
+			b.Fatalf("failed to create session: %v", err)
+		}
+	}
+
+	lookups := map[string]func() (int, error){
+		"scan": func() (int, error) {
+			return scanActiveSessionCount(ctx, redisClient, userID)
+		},
+		"index": func() (int, error) {
+			return sessionService.GetActiveSessionCount(ctx, userID)
+		},
+	}
+
+	for _, name := range []string{"scan", "index"} {
+		lookup := lookups[name]
+		b.Run(name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				count, err := lookup()
+				if err != nil {
+					b.Fatalf("lookup failed: %v", err)
+				}
+				if count != 5 {
+					b.Fatalf("expected 5 sessions, got %d", count)
+				}
+			}
+		})
+	}
+}
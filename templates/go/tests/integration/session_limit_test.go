@@ -0,0 +1,114 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+)
+
+func TestSessionService_LimitsByRole(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, auth.WithSessionLimitPolicy(auth.SessionLimitPolicy{
+		ByRole: map[string]int{"admin": 0, "user": 3},
+	}))
+
+	createSessions := func(roles []string, count int) (created int, err error) {
+		userID := uuid.New()
+		for i := 0; i < count; i++ {
+			if _, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID, Roles: roles}); err != nil {
+				return created, err
+			}
+			created++
+		}
+		return created, nil
+	}
+
+	// Act
+	userCreated, userErr := createSessions([]string{"user"}, 4)
+	adminCreated, adminErr := createSessions([]string{"admin"}, 10)
+
+	// Assert
+	assert.Equal(t, 3, userCreated)
+	assert.ErrorIs(t, userErr, auth.ErrTooManySessions)
+
+	assert.Equal(t, 10, adminCreated)
+	require.NoError(t, adminErr)
+}
+
+func TestSessionService_LimitEnforcement(t *testing.T) {
+	const limit = 3
+
+	tests := []struct {
+		name        string
+		evictOldest bool
+	}{
+		{name: "reject new session", evictOldest: false},
+		{name: "evict oldest session", evictOldest: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			redisClient := setupTestRedis(t)
+			defer teardownTestRedis(t, redisClient)
+
+			ctx := context.Background()
+			sessionService := auth.NewSessionService(redisClient, time.Hour, auth.WithSessionLimitPolicy(auth.SessionLimitPolicy{
+				Default:     limit,
+				EvictOldest: tt.evictOldest,
+			}))
+			userID := uuid.New()
+
+			var sessionIDs []string
+			for i := 0; i < limit; i++ {
+				sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+				require.NoError(t, err)
+				sessionIDs = append(sessionIDs, sessionID)
+				time.Sleep(5 * time.Millisecond) // distinct CreatedAt ordering
+			}
+
+			// Act
+			newest, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+
+			// Assert
+			count, countErr := sessionService.GetActiveSessionCount(ctx, userID)
+			require.NoError(t, countErr)
+			assert.Equal(t, limit, count)
+
+			oldestValid, validErr := sessionService.IsSessionValid(ctx, sessionIDs[0])
+			require.NoError(t, validErr)
+
+			if tt.evictOldest {
+				require.NoError(t, err)
+				assert.False(t, oldestValid, "the oldest session is evicted")
+
+				newestValid, validErr := sessionService.IsSessionValid(ctx, newest)
+				require.NoError(t, validErr)
+				assert.True(t, newestValid)
+
+				for _, sessionID := range sessionIDs[1:] {
+					valid, validErr := sessionService.IsSessionValid(ctx, sessionID)
+					require.NoError(t, validErr)
+					assert.True(t, valid, "newer sessions survive eviction")
+				}
+			} else {
+				assert.ErrorIs(t, err, auth.ErrTooManySessions)
+				assert.Empty(t, newest)
+				assert.True(t, oldestValid, "existing sessions are untouched")
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+)
+
+func TestSessionService_MaxSessionSize(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, auth.WithMaxSessionSize(1024))
+	sessionData := &auth.SessionData{UserID: uuid.New(), Metadata: map[string]interface{}{"theme": "dark"}}
+
+	// Act - a small session is stored
+	sessionID, err := sessionService.CreateSession(ctx, sessionData)
+	require.NoError(t, err)
+
+	// Act - growing it past the limit is rejected
+	sessionData.Metadata["blob"] = strings.Repeat("x", 2048)
+	err = sessionService.UpdateSession(ctx, sessionID, sessionData)
+
+	// Assert - the stored session keeps its previous contents
+	assert.ErrorIs(t, err, auth.ErrSessionTooLarge)
+
+	stored, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Metadata, "blob")
+}
@@ -0,0 +1,190 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/utils"
+)
+
+// newSlidingSessionRouter authenticates every request as userID and applies
+// sliding session expiration
+func newSlidingSessionRouter(sessionService *auth.SessionService, refreshInterval time.Duration, userID uuid.UUID) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Next()
+	})
+	router.Use(middleware.NewSessionMiddleware(sessionService, refreshInterval, utils.NewLogger("error", "test")).SlidingExpiration())
+	router.GET("/resource", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func touchSession(router *gin.Engine, sessionID string) int {
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set(middleware.SessionHeader, sessionID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestSlidingExpiration_ActiveSessionOutlivesTimeout(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	timeout := 2 * time.Second
+	sessionService := auth.NewSessionService(redisClient, timeout)
+	userID := uuid.New()
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+
+	router := newSlidingSessionRouter(sessionService, 0, userID)
+
+	// Act - keep using the session for longer than its timeout
+	for i := 0; i < 4; i++ {
+		time.Sleep(timeout / 3)
+		require.Equal(t, http.StatusOK, touchSession(router, sessionID))
+	}
+
+	// Assert
+	valid, err := sessionService.IsSessionValid(ctx, sessionID)
+	require.NoError(t, err)
+	assert.True(t, valid, "active session should still be alive")
+
+	// Act - go idle
+	time.Sleep(timeout + 500*time.Millisecond)
+
+	// Assert
+	valid, err = sessionService.IsSessionValid(ctx, sessionID)
+	require.NoError(t, err)
+	assert.False(t, valid, "idle session should have expired")
+}
+
+func TestSlidingExpiration_ThrottlesRefreshes(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID := uuid.New()
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+	before, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+
+	router := newSlidingSessionRouter(sessionService, time.Minute, userID)
+
+	// Act
+	require.Equal(t, http.StatusOK, touchSession(router, sessionID))
+
+	// Assert - refreshed less than a minute ago, so activity is not rewritten
+	after, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	assert.True(t, before.LastActivity.Equal(after.LastActivity))
+}
+
+func TestSlidingExpiration_IgnoresOtherUsersSessions(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	timeout := 2 * time.Second
+	sessionService := auth.NewSessionService(redisClient, timeout)
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New()})
+	require.NoError(t, err)
+
+	router := newSlidingSessionRouter(sessionService, 0, uuid.New())
+
+	// Act
+	for i := 0; i < 4; i++ {
+		time.Sleep(timeout / 3)
+		require.Equal(t, http.StatusOK, touchSession(router, sessionID))
+	}
+
+	// Assert
+	valid, err := sessionService.IsSessionValid(ctx, sessionID)
+	require.NoError(t, err)
+	assert.False(t, valid, "another user's session must not be kept alive")
+}
+
+func TestSlidingExpiration_ActiveSessionEndsAtMaxLifetime(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	idleTimeout := 2 * time.Second
+	sessionService := auth.NewSessionService(redisClient, idleTimeout, auth.WithMaxLifetime(3*time.Second))
+	userID := uuid.New()
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+
+	session, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, session.CreatedAt.Add(3*time.Second), session.AbsoluteExpiry, time.Millisecond)
+
+	router := newSlidingSessionRouter(sessionService, 0, userID)
+
+	// Act - keep using the session well past its absolute lifetime
+	for i := 0; i < 6; i++ {
+		time.Sleep(idleTimeout / 3)
+		touchSession(router, sessionID)
+	}
+
+	// Assert
+	valid, err := sessionService.IsSessionValid(ctx, sessionID)
+	require.NoError(t, err)
+	assert.False(t, valid, "activity must not extend a session past its absolute lifetime")
+}
+
+func TestSessionService_GetSession_PastAbsoluteExpiry(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	userID := uuid.New()
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	require.NoError(t, err)
+
+	// Backdate the absolute expiry while the idle TTL is still long
+	session, err := sessionService.GetSession(ctx, sessionID)
+	require.NoError(t, err)
+	session.AbsoluteExpiry = time.Now().Add(-time.Second)
+	require.NoError(t, sessionService.UpdateSession(ctx, sessionID, session))
+
+	// Act
+	_, err = sessionService.GetSession(ctx, sessionID)
+
+	// Assert
+	assert.Error(t, err)
+
+	exists, err := redisClient.Exists(ctx, "session:"+sessionID).Result()
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), exists, "an expired session is deleted on read")
+
+	count, err := sessionService.GetActiveSessionCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
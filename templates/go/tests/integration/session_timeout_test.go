@@ -0,0 +1,69 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+)
+
+func TestSessionService_TimeoutByLoginMethod(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, auth.WithTimeoutsByMethod(map[string]time.Duration{
+		auth.AuthMethodOAuth:     24 * time.Hour,
+		auth.AuthMethodMagicLink: 15 * time.Minute,
+	}))
+
+	ttlFor := func(method string) time.Duration {
+		sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New(), AuthMethod: method})
+		require.NoError(t, err)
+
+		ttl, err := redisClient.TTL(ctx, "session:"+sessionID).Result()
+		require.NoError(t, err)
+		return ttl
+	}
+
+	// Act
+	passwordTTL := ttlFor(auth.AuthMethodPassword)
+	oauthTTL := ttlFor(auth.AuthMethodOAuth)
+	magicLinkTTL := ttlFor(auth.AuthMethodMagicLink)
+
+	// Assert
+	assert.InDelta(t, time.Hour.Seconds(), passwordTTL.Seconds(), 5)
+	assert.InDelta(t, (24 * time.Hour).Seconds(), oauthTTL.Seconds(), 5)
+	assert.InDelta(t, (15 * time.Minute).Seconds(), magicLinkTTL.Seconds(), 5)
+}
+
+func TestSessionService_RefreshKeepsMethodTimeout(t *testing.T) {
+	// Arrange
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(redisClient, time.Hour, auth.WithTimeoutsByMethod(map[string]time.Duration{
+		auth.AuthMethodMagicLink: 15 * time.Minute,
+	}))
+
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: uuid.New(), AuthMethod: auth.AuthMethodMagicLink})
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, sessionService.RefreshSession(ctx, sessionID))
+
+	// Assert
+	ttl, err := redisClient.TTL(ctx, "session:"+sessionID).Result()
+	require.NoError(t, err)
+	assert.InDelta(t, (15 * time.Minute).Seconds(), ttl.Seconds(), 5)
+}
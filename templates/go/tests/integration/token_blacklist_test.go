@@ -0,0 +1,104 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/utils"
+)
+
+func TestLogout_BlacklistsAccessToken(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	authService := newTestAuthService(db, redisClient, jwtService, auth.NewSessionService(redisClient, time.Hour), &config.Config{Environment: "test"})
+
+	user, err := createTestUser(db, "logout@example.com", "logout", "user")
+	require.NoError(t, err)
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(token)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"),
+		middleware.WithTokenBlacklist(auth.NewRedisTokenBlacklist(redisClient, "")))
+	router := gin.New()
+	router.GET("/me", authMiddleware.RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	serve := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, serve())
+
+	// Act
+	require.NoError(t, authService.Logout(ctx, user.ID, claims, ""))
+
+	// Assert - the token is rejected and its entry expires with the token
+	assert.Equal(t, http.StatusUnauthorized, serve())
+
+	ttl, err := redisClient.TTL(ctx, "blacklist:"+claims.ID).Result()
+	require.NoError(t, err)
+	assert.InDelta(t, time.Until(claims.ExpiresAt.Time).Seconds(), ttl.Seconds(), 5)
+}
+
+func TestLogout_WithoutRedisRevokesRefreshToken(t *testing.T) {
+	// Arrange - Redis disabled for sessions and the blacklist
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	authService := newTestAuthService(db, nil, jwtService, auth.NewSessionService(nil, time.Hour), &config.Config{Environment: "test"})
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "logout@example.com", "logout", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	resp, err := authService.Login(ctx, &models.LoginRequest{
+		Login:    "logout@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(resp.AccessToken)
+	require.NoError(t, err)
+
+	// Act
+	err = authService.Logout(ctx, user.ID, claims, resp.RefreshToken)
+
+	// Assert - the access token cannot be revoked, but logout still completes
+	require.NoError(t, err)
+
+	var stored models.RefreshToken
+	require.NoError(t, db.Where("token = ?", models.HashRefreshToken(resp.RefreshToken)).First(&stored).Error)
+	assert.True(t, stored.IsRevoked)
+
+	_, err = authService.RefreshToken(ctx, resp.RefreshToken, "127.0.0.1", "test-agent")
+	assert.Error(t, err)
+}
@@ -0,0 +1,78 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/models"
+	"app/internal/repository/postgres"
+	"app/internal/utils"
+)
+
+func TestRoleChange_InvalidatesExistingTokens(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	ctx := context.Background()
+	userRepo := postgres.NewUserRepository(db)
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+
+	user, err := createTestUser(db, "promoted@example.com", "promoted", "user")
+	require.NoError(t, err)
+	user, err = userRepo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	oldToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"),
+		middleware.WithTokenVersionSource(userRepo))
+	router := gin.New()
+	router.GET("/me", authMiddleware.RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+	require.Equal(t, http.StatusOK, serve(oldToken))
+
+	var adminRole models.Role
+	require.NoError(t, db.Where("name = ?", "admin").First(&adminRole).Error)
+
+	// Act
+	require.NoError(t, userRepo.AssignRole(ctx, user.ID, adminRole.ID, nil, nil))
+
+	// Assert - the old token is rejected, a freshly issued one works
+	assert.Equal(t, http.StatusUnauthorized, serve(oldToken))
+
+	user, err = userRepo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1, user.TokenVersion)
+
+	newToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, serve(newToken))
+
+	// Act - revoking the role bumps the version again
+	require.NoError(t, userRepo.RevokeRole(ctx, user.ID, adminRole.ID, nil))
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, serve(newToken))
+}
@@ -0,0 +1,260 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/services"
+)
+
+// totpTestSecret is the secret the TOTP tests enrol users with
+const totpTestSecret = "JBSWY3DPEHPK3PXP"
+
+type totpTestEnv struct {
+	db             *gorm.DB
+	user           *models.User
+	authService    *services.AuthService
+	jwtService     *auth.JWTService
+	sessionService *auth.SessionService
+}
+
+func setupTOTPTest(t *testing.T, enrolled bool) *totpTestEnv {
+	db := setupTestDB(t)
+	t.Cleanup(func() { teardownTestDB(t, db) })
+	redisClient := setupTestRedis(t)
+	t.Cleanup(func() { teardownTestRedis(t, redisClient) })
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "totp@example.com", "totp", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+	if enrolled {
+		require.NoError(t, db.Model(user).Updates(map[string]interface{}{
+			"totp_secret":  totpTestSecret,
+			"totp_enabled": true,
+		}).Error)
+	}
+
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	sessionService := auth.NewSessionService(redisClient, time.Hour)
+	return &totpTestEnv{
+		db:   db,
+		user: user,
+		authService: newTestAuthService(db, redisClient,
+			jwtService,
+			sessionService,
+			&config.Config{Environment: "test", RefreshTokenRotation: true},
+		),
+		jwtService:     jwtService,
+		sessionService: sessionService,
+	}
+}
+
+// login answers the password step and returns the MFA challenge token
+func (e *totpTestEnv) login(t *testing.T) string {
+	resp, err := e.authService.Login(context.Background(), &models.LoginRequest{
+		Login:    "totp@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	require.True(t, resp.MFARequired)
+	require.NotEmpty(t, resp.ChallengeToken)
+	return resp.ChallengeToken
+}
+
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	code, err := auth.NewTOTPService("").GenerateCode(secret, at)
+	require.NoError(t, err)
+	return code
+}
+
+func TestAuthService_TOTPLogin_ValidCode(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	ctx := context.Background()
+
+	resp, err := env.authService.Login(ctx, &models.LoginRequest{
+		Login:    "totp@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	// Assert - a correct password alone issues no tokens
+	require.True(t, resp.MFARequired)
+	assert.Empty(t, resp.AccessToken)
+	assert.Empty(t, resp.RefreshToken)
+	assert.Equal(t, 300, resp.ExpiresIn)
+
+	// Act
+	verified, err := env.authService.VerifyTOTP(ctx, resp.ChallengeToken, totpCode(t, totpTestSecret, time.Now()))
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, verified.MFARequired)
+	assert.NotEmpty(t, verified.RefreshToken)
+
+	claims, err := env.jwtService.ValidateToken(verified.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, env.user.ID, claims.UserID)
+	assert.Equal(t, auth.AuthMethodTOTP, claims.AuthMethod)
+
+	// Act & Assert - a completed challenge cannot log in again
+	_, err = env.authService.VerifyTOTP(ctx, resp.ChallengeToken, totpCode(t, totpTestSecret, time.Now()))
+	assert.ErrorIs(t, err, services.ErrInvalidMFAChallenge)
+}
+
+func TestAuthService_TOTPLogin_ExpiredCode(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	challenge := env.login(t)
+
+	// Act
+	_, err := env.authService.VerifyTOTP(context.Background(), challenge, totpCode(t, totpTestSecret, time.Now().Add(-5*time.Minute)))
+
+	// Assert - the wrong code counts towards both the challenge and the lockout
+	assert.ErrorIs(t, err, services.ErrInvalidTOTPCode)
+
+	var stored models.MFAChallenge
+	require.NoError(t, env.db.Where("token = ?", models.HashMFAChallengeToken(challenge)).First(&stored).Error)
+	assert.Equal(t, 1, stored.Attempts)
+	assert.Nil(t, stored.UsedAt)
+
+	var user models.User
+	require.NoError(t, env.db.First(&user, "id = ?", env.user.ID).Error)
+	assert.Equal(t, 1, user.FailedLoginCount)
+
+	// Act & Assert - the challenge can still be completed with a valid code
+	_, err = env.authService.VerifyTOTP(context.Background(), challenge, totpCode(t, totpTestSecret, time.Now()))
+	require.NoError(t, err)
+
+	require.NoError(t, env.db.First(&user, "id = ?", env.user.ID).Error)
+	assert.Zero(t, user.FailedLoginCount)
+}
+
+func TestAuthService_TOTPLogin_ReusedCode(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	ctx := context.Background()
+
+	code := totpCode(t, totpTestSecret, time.Now())
+	_, err := env.authService.VerifyTOTP(ctx, env.login(t), code)
+	require.NoError(t, err)
+
+	// Act - an attacker who saw the code replays it within its period
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), code)
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrTOTPCodeReused)
+}
+
+func TestAuthService_TOTPLogin_TooManyWrongCodes(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, true)
+	ctx := context.Background()
+	challenge := env.login(t)
+
+	// Act - keep the account under the lockout threshold while exhausting the challenge
+	for i := 0; i < 5; i++ {
+		_, err := env.authService.VerifyTOTP(ctx, challenge, "000000")
+		require.Error(t, err)
+		require.NoError(t, env.db.Model(env.user).Update("failed_login_count", 0).Error)
+	}
+
+	// Assert - not even the right code completes it now
+	_, err := env.authService.VerifyTOTP(ctx, challenge, totpCode(t, totpTestSecret, time.Now()))
+	assert.ErrorIs(t, err, services.ErrInvalidMFAChallenge)
+}
+
+func TestAuthService_TOTPEnrolmentAndRecoveryCodes(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, false)
+	ctx := context.Background()
+
+	setup, err := env.authService.SetupTOTP(ctx, env.user.ID)
+	require.NoError(t, err)
+	assert.Contains(t, setup.QRCodeURL, "secret="+setup.Secret)
+
+	// Without confirming the secret, logins need no second factor
+	resp, err := env.authService.Login(ctx, &models.LoginRequest{
+		Login:    "totp@example.com",
+		Password: "Str0ng!Passw0rd",
+	}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+	require.False(t, resp.MFARequired)
+
+	// Act
+	_, err = env.authService.EnableTOTP(ctx, env.user.ID, "000000")
+	assert.ErrorIs(t, err, services.ErrInvalidTOTPCode)
+
+	enrolCode := totpCode(t, setup.Secret, time.Now())
+	recovery, err := env.authService.EnableTOTP(ctx, env.user.ID, enrolCode)
+
+	// Assert - codes are stored hashed
+	require.NoError(t, err)
+	require.Len(t, recovery.RecoveryCodes, 10)
+
+	var stored []models.RecoveryCode
+	require.NoError(t, env.db.Where("user_id = ?", env.user.ID).Find(&stored).Error)
+	require.Len(t, stored, 10)
+	for _, code := range stored {
+		assert.NotContains(t, recovery.RecoveryCodes, code.CodeHash)
+	}
+
+	// Act & Assert - the enrolment code cannot be replayed to log in
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), enrolCode)
+	assert.ErrorIs(t, err, services.ErrTOTPCodeReused)
+
+	// Act & Assert - a recovery code completes a login once
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), recovery.RecoveryCodes[0])
+	require.NoError(t, err)
+
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), recovery.RecoveryCodes[0])
+	assert.ErrorIs(t, err, services.ErrInvalidTOTPCode)
+
+	// Act & Assert - regenerating invalidates the old codes
+	regenerated, err := env.authService.RegenerateRecoveryCodes(ctx, env.user.ID)
+	require.NoError(t, err)
+	require.Len(t, regenerated.RecoveryCodes, 10)
+
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), recovery.RecoveryCodes[1])
+	assert.ErrorIs(t, err, services.ErrInvalidTOTPCode)
+
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), regenerated.RecoveryCodes[1])
+	assert.NoError(t, err)
+}
+
+func TestAuthService_TOTPLogin_RecoveryCodeKeptOnUsedChallenge(t *testing.T) {
+	// Arrange
+	env := setupTOTPTest(t, false)
+	ctx := context.Background()
+
+	setup, err := env.authService.SetupTOTP(ctx, env.user.ID)
+	require.NoError(t, err)
+	recovery, err := env.authService.EnableTOTP(ctx, env.user.ID, totpCode(t, setup.Secret, time.Now()))
+	require.NoError(t, err)
+
+	challenge := env.login(t)
+	_, err = env.authService.VerifyTOTP(ctx, challenge, totpCode(t, setup.Secret, time.Now().Add(30*time.Second)))
+	require.NoError(t, err)
+
+	// Act - the completed challenge is presented again with a recovery code
+	_, err = env.authService.VerifyTOTP(ctx, challenge, recovery.RecoveryCodes[0])
+
+	// Assert - the challenge is rejected without spending the code
+	assert.ErrorIs(t, err, services.ErrInvalidMFAChallenge)
+
+	_, err = env.authService.VerifyTOTP(ctx, env.login(t), recovery.RecoveryCodes[0])
+	assert.NoError(t, err)
+}
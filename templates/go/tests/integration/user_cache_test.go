@@ -0,0 +1,212 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/cache"
+	"app/internal/repository/postgres"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestCachingUserRepository_WriteEvictsStaleEntry(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	repo := cache.NewCachingUserRepository(postgres.NewUserRepository(db), redisClient, time.Hour)
+
+	user, err := createTestUser(db, "cached@example.com", "cached", "user")
+	require.NoError(t, err)
+
+	cached, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, cached.IsActive)
+
+	// A write that bypasses the decorator is not seen, proving reads are cached
+	require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("is_active", false).Error)
+	stale, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, stale.IsActive, "expected the cached entry")
+
+	// Act
+	require.NoError(t, repo.DeactivateUser(ctx, user.ID))
+
+	// Assert - lookups by ID and email both see the write
+	byID, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, byID.IsActive)
+
+	byEmail, err := repo.GetByEmail(ctx, "Cached@Example.com")
+	require.NoError(t, err)
+	assert.False(t, byEmail.IsActive)
+}
+
+func TestCachingUserRepository_RolesAreNeverStale(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	repo := cache.NewCachingUserRepository(postgres.NewUserRepository(db), redisClient, time.Hour)
+
+	user, err := createTestUser(db, "roles@example.com", "roles", "user")
+	require.NoError(t, err)
+
+	var moderator models.Role
+	require.NoError(t, db.Where("name = ?", "moderator").First(&moderator).Error)
+
+	cached, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.False(t, cached.HasRole("moderator"))
+
+	// Act & Assert - a grant is seen even though the user is cached
+	expiresAt := time.Now().Add(2 * time.Second)
+	require.NoError(t, db.Create(&models.UserRole{UserID: user.ID, RoleID: moderator.ID, ExpiresAt: &expiresAt}).Error)
+	byID, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.True(t, byID.HasRole("moderator"))
+
+	// Act & Assert - so is a change to the role's permissions
+	require.NoError(t, db.Model(&moderator).Update("permissions", models.Permissions{"reports:read"}).Error)
+	byEmail, err := repo.GetByEmail(ctx, "roles@example.com")
+	require.NoError(t, err)
+	assert.True(t, byEmail.HasPermission("reports:read"))
+
+	// Act & Assert - and the assignment expiring
+	time.Sleep(time.Until(expiresAt) + 100*time.Millisecond)
+	byID, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, byID.HasRole("moderator"))
+}
+
+func TestCachingUserRepository_TransactionEvictsAfterCommit(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	repo := cache.NewCachingUserRepository(postgres.NewUserRepository(db), redisClient, time.Hour)
+
+	user, err := createTestUser(db, "tx@example.com", "tx", "user")
+	require.NoError(t, err)
+
+	tx, err := repo.BeginTransaction(ctx)
+	require.NoError(t, err)
+	require.NoError(t, repo.WithTransaction(tx).DeactivateUser(ctx, user.ID))
+
+	// A read between the write and the commit caches the old row again
+	beforeCommit, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, beforeCommit.IsActive)
+
+	// Act
+	require.NoError(t, tx.Commit().Error)
+
+	// Assert
+	afterCommit, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.False(t, afterCommit.IsActive)
+}
+
+func TestCachingUserRepository_KeepsPasswordHash(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	repo := cache.NewCachingUserRepository(postgres.NewUserRepository(db), redisClient, time.Hour)
+
+	user, err := createTestUser(db, "hash@example.com", "hash", "user")
+	require.NoError(t, err)
+	require.NoError(t, repo.UpdatePassword(ctx, user.ID, "stored-hash"))
+
+	_, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	// Act - served from the cache
+	cached, err := repo.GetByID(ctx, user.ID)
+
+	// Assert - fields hidden from JSON survive the cache
+	require.NoError(t, err)
+	assert.Equal(t, "stored-hash", cached.PasswordHash)
+}
+
+func TestCachingUserRepository_DisabledWithoutTTL(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	inner := postgres.NewUserRepository(db)
+
+	// Act & Assert
+	assert.Equal(t, inner, cache.NewCachingUserRepository(inner, redisClient, 0))
+	assert.Equal(t, inner, cache.NewCachingUserRepository(inner, nil, time.Hour))
+}
+
+func TestCachingUserRepository_PasswordResetEvictsUser(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+	redisClient := setupTestRedis(t)
+	defer teardownTestRedis(t, redisClient)
+
+	ctx := context.Background()
+	repo := cache.NewCachingUserRepository(postgres.NewUserRepository(db), redisClient, time.Hour)
+	authService := services.NewAuthService(
+		repo,
+		auth.NewJWTService("test-secret", "test-issuer", 1),
+		auth.NewPasswordService(4),
+		auth.NewSessionService(redisClient, time.Hour),
+		auth.NewBlacklistService(auth.NewRedisTokenBlacklist(redisClient, "")),
+		redisClient,
+		&config.Config{Environment: "test"},
+		utils.NewLogger("error", "test"),
+		db,
+	)
+
+	hash, err := auth.NewPasswordService(4).HashPassword("Str0ng!Passw0rd")
+	require.NoError(t, err)
+	user, err := createTestUser(db, "reset-cache@example.com", "resetcache", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Model(user).Update("password_hash", hash).Error)
+
+	// The old password hash is cached
+	_, err = repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+
+	reset := &models.PasswordReset{Email: user.Email, UserID: user.ID}
+	require.NoError(t, db.Create(reset).Error)
+
+	// Act
+	require.NoError(t, authService.ResetPassword(ctx, &models.ResetPasswordRequest{Token: reset.Token, NewPassword: "Tz9!mVq#Lw4k"}, "127.0.0.1"))
+
+	// Assert - cached lookups see the new password
+	_, err = authService.Login(ctx, &models.LoginRequest{Login: "reset-cache@example.com", Password: "Tz9!mVq#Lw4k"}, "127.0.0.1", "test-agent")
+	require.NoError(t, err)
+
+	err = authService.ChangePassword(ctx, user.ID, &models.ChangePasswordRequest{CurrentPassword: "Tz9!mVq#Lw4k", NewPassword: "Nw7$pLx!Qe2r"})
+	assert.NoError(t, err)
+}
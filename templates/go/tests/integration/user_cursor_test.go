@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_ListWithCursor(t *testing.T) {
+	for _, order := range []string{"asc", "desc"} {
+		t.Run(order, func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+
+			ctx := context.Background()
+			repo := postgres.NewUserRepository(db)
+
+			// Pairs of users share a creation time, so the ID breaks ties
+			base := time.Now().Add(-time.Hour).UTC().Truncate(time.Microsecond)
+			seeded := make(map[uuid.UUID]bool)
+			for i := 0; i < 20; i++ {
+				user, err := createTestUser(db, fmt.Sprintf("cursor%d@example.com", i), fmt.Sprintf("cursor%d", i), "user")
+				require.NoError(t, err)
+				createdAt := base.Add(time.Duration(i/2) * time.Minute)
+				require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("created_at", createdAt).Error)
+				seeded[user.ID] = true
+			}
+
+			// Act - page through, inserting a user after the first page
+			filters := interfaces.UserFilters{SortOrder: order}
+			seen := make(map[uuid.UUID]int)
+			var pages [][]*models.User
+			cursor := ""
+			for {
+				users, next, err := repo.ListWithCursor(ctx, filters, cursor, 7)
+				require.NoError(t, err)
+				pages = append(pages, users)
+				for _, user := range users {
+					seen[user.ID]++
+				}
+
+				if len(pages) == 1 {
+					_, err := createTestUser(db, "late@example.com", "late", "user")
+					require.NoError(t, err)
+				}
+
+				if next == "" {
+					break
+				}
+				cursor = next
+				require.Less(t, len(pages), 10, "pagination did not terminate")
+			}
+
+			// Assert - every seeded user appears exactly once, in keyset order
+			for id := range seeded {
+				assert.Equal(t, 1, seen[id], "user %s", id)
+			}
+			for id, count := range seen {
+				assert.Equal(t, 1, count, "user %s repeated", id)
+			}
+
+			var all []*models.User
+			for _, page := range pages {
+				all = append(all, page...)
+			}
+			for i := 1; i < len(all); i++ {
+				prev, cur := all[i-1], all[i]
+				before := prev.CreatedAt.Before(cur.CreatedAt) ||
+					(prev.CreatedAt.Equal(cur.CreatedAt) && prev.ID.String() < cur.ID.String())
+				if order == "desc" {
+					assert.False(t, before, "users %d and %d are out of order", i-1, i)
+				} else {
+					assert.True(t, before, "users %d and %d are out of order", i-1, i)
+				}
+			}
+		})
+	}
+}
+
+func TestUserRepository_ListWithCursorInvalidCursor(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	repo := postgres.NewUserRepository(db)
+
+	// Act
+	_, _, err := repo.ListWithCursor(context.Background(), interfaces.UserFilters{}, "not a cursor", 10)
+
+	// Assert
+	assert.ErrorIs(t, err, interfaces.ErrInvalidCursor)
+}
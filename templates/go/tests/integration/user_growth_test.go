@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_GetUserGrowth(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	// 2030-01-01 is a Tuesday
+	createdAt := []time.Time{
+		time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC),
+		time.Date(2030, time.January, 1, 23, 59, 0, 0, time.UTC),
+		time.Date(2030, time.January, 3, 12, 0, 0, 0, time.UTC),
+		time.Date(2030, time.January, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2030, time.February, 8, 12, 0, 0, 0, time.UTC),
+		time.Date(2029, time.December, 31, 23, 0, 0, 0, time.UTC), // before range
+	}
+	for i, ts := range createdAt {
+		user, err := createTestUser(db, fmt.Sprintf("growth%d@example.com", i), fmt.Sprintf("growth%d", i))
+		require.NoError(t, err)
+		require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("created_at", ts).Error)
+	}
+
+	repo := postgres.NewUserRepository(db)
+	ctx := context.Background()
+	from := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("day", func(t *testing.T) {
+		// Act
+		buckets, err := repo.GetUserGrowth(ctx, from, from.AddDate(0, 0, 4), interfaces.GrowthIntervalDay)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []interfaces.GrowthBucket{
+			{Start: from, Count: 2},
+			{Start: from.AddDate(0, 0, 1), Count: 0},
+			{Start: from.AddDate(0, 0, 2), Count: 1},
+			{Start: from.AddDate(0, 0, 3), Count: 0},
+		}, buckets)
+	})
+
+	t.Run("week", func(t *testing.T) {
+		// Act
+		buckets, err := repo.GetUserGrowth(ctx, from, from.AddDate(0, 0, 14), interfaces.GrowthIntervalWeek)
+
+		// Assert - weeks start on Monday 2029-12-31
+		require.NoError(t, err)
+		monday := time.Date(2029, time.December, 31, 0, 0, 0, 0, time.UTC)
+		assert.Equal(t, []interfaces.GrowthBucket{
+			{Start: monday, Count: 3},
+			{Start: monday.AddDate(0, 0, 7), Count: 1},
+			{Start: monday.AddDate(0, 0, 14), Count: 0},
+		}, buckets)
+	})
+
+	t.Run("month", func(t *testing.T) {
+		// Act
+		buckets, err := repo.GetUserGrowth(ctx, from, from.AddDate(0, 2, 0), interfaces.GrowthIntervalMonth)
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, []interfaces.GrowthBucket{
+			{Start: from, Count: 4},
+			{Start: from.AddDate(0, 1, 0), Count: 1},
+		}, buckets)
+	})
+
+	t.Run("invalid interval", func(t *testing.T) {
+		// Act
+		_, err := repo.GetUserGrowth(ctx, from, from.AddDate(0, 0, 1), "year")
+
+		// Assert
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,81 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/repository/postgres"
+)
+
+func TestUserRepository_GetUserStatsUsersByRole(t *testing.T) {
+	// Arrange
+	db := setupTestDB(t)
+	defer teardownTestDB(t, db)
+
+	_, err := createTestUser(db, "admin@example.com", "admin", "admin", "user")
+	require.NoError(t, err)
+	_, err = createTestUser(db, "alice@example.com", "alice", "user")
+	require.NoError(t, err)
+	_, err = createTestUser(db, "bob@example.com", "bob", "user")
+	require.NoError(t, err)
+	_, err = createTestUser(db, "norole@example.com", "norole")
+	require.NoError(t, err)
+
+	deleted, err := createTestUser(db, "deleted@example.com", "deleted", "user")
+	require.NoError(t, err)
+	require.NoError(t, db.Delete(&models.User{}, "id = ?", deleted.ID).Error)
+
+	repo := postgres.NewUserRepository(db)
+
+	// Act
+	stats, err := repo.GetUserStats(context.Background(), nil)
+
+	// Assert - multi-role users count towards each role, deleted users are excluded
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{
+		"admin":     1,
+		"user":      3,
+		"moderator": 0,
+	}, stats.UsersByRole)
+}
+
+func TestUserRepository_GetUserStatsTimezone(t *testing.T) {
+	for _, loc := range []*time.Location{time.FixedZone("UTC+14", 14*60*60), time.FixedZone("UTC-12", -12*60*60)} {
+		t.Run(loc.String(), func(t *testing.T) {
+			// Arrange
+			db := setupTestDB(t)
+			defer teardownTestDB(t, db)
+
+			windows := interfaces.NewStatsWindows(time.Now(), loc)
+			createdAt := []time.Time{
+				windows.Today,                   // first instant of the local day
+				windows.Today.Add(-time.Minute), // just before local midnight
+			}
+			for i, ts := range createdAt {
+				user, err := createTestUser(db, fmt.Sprintf("tz%d@example.com", i), fmt.Sprintf("tz%d", i))
+				require.NoError(t, err)
+				require.NoError(t, db.Model(&models.User{}).Where("id = ?", user.ID).Update("created_at", ts).Error)
+			}
+
+			repo := postgres.NewUserRepository(db)
+
+			// Act
+			stats, err := repo.GetUserStats(context.Background(), loc)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, int64(1), stats.NewUsersToday)
+			assert.Equal(t, loc.String(), stats.Timezone)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// failingUserLookup fails every user lookup with err
+type failingUserLookup struct {
+	interfaces.UserRepository
+	err error
+}
+
+func (r *failingUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return nil, r.err
+}
+
+func TestAuthService_ActivateUser_LookupErrors(t *testing.T) {
+	dbErr := errors.New("connection refused")
+
+	tests := []struct {
+		name     string
+		err      error
+		notFound bool
+	}{
+		{name: "missing user", err: fmt.Errorf("user not found: %w", gorm.ErrRecordNotFound), notFound: true},
+		{name: "database failure", err: fmt.Errorf("failed to get user: %w", dbErr), notFound: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &failingUserLookup{err: tt.err}
+			authService := services.NewAuthService(repo, nil, nil, nil, nil, nil, &config.Config{}, utils.NewLogger("error", "test"), nil)
+
+			// Act
+			err := authService.ActivateUser(context.Background(), uuid.New(), uuid.New())
+
+			// Assert
+			assert.Equal(t, tt.notFound, errors.Is(err, services.ErrUserNotFound))
+			if !tt.notFound {
+				assert.ErrorIs(t, err, dbErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/models"
+)
+
+func TestAuditLog_TruncateDetails(t *testing.T) {
+	tests := []struct {
+		name          string
+		details       map[string]interface{}
+		maxBytes      int
+		wantTruncated bool
+		wantKept      []string
+		wantDropped   []string
+	}{
+		{
+			name:     "within limit",
+			details:  map[string]interface{}{"email": "user@example.com"},
+			maxBytes: 1024,
+			wantKept: []string{"email"},
+		},
+		{
+			name:     "unlimited",
+			details:  map[string]interface{}{"blob": strings.Repeat("x", 4096)},
+			maxBytes: 0,
+			wantKept: []string{"blob"},
+		},
+		{
+			name: "oversized entry dropped",
+			details: map[string]interface{}{
+				"email":  "user@example.com",
+				"blob":   strings.Repeat("x", 4096),
+				"reason": "test",
+			},
+			maxBytes:      256,
+			wantTruncated: true,
+			wantKept:      []string{"email", "reason"},
+			wantDropped:   []string{"blob"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			userID := uuid.New()
+			auditLog := &models.AuditLog{
+				UserID:   &userID,
+				Action:   "user.update",
+				Resource: "user",
+				Details:  tt.details,
+				Success:  true,
+			}
+
+			// Act
+			truncated := auditLog.TruncateDetails(tt.maxBytes)
+
+			// Assert
+			assert.Equal(t, tt.wantTruncated, truncated)
+			for _, key := range tt.wantKept {
+				assert.Contains(t, auditLog.Details, key)
+			}
+			for _, key := range tt.wantDropped {
+				assert.NotContains(t, auditLog.Details, key)
+			}
+			if tt.wantTruncated {
+				assert.Equal(t, true, auditLog.Details["details_truncated"])
+				assert.Greater(t, auditLog.Details["details_bytes"], tt.maxBytes)
+
+				data, err := json.Marshal(auditLog.Details)
+				require.NoError(t, err)
+				assert.LessOrEqual(t, len(data), tt.maxBytes)
+			}
+
+			// The entry's core fields are never touched
+			assert.Equal(t, &userID, auditLog.UserID)
+			assert.Equal(t, "user.update", auditLog.Action)
+			assert.Equal(t, "user", auditLog.Resource)
+			assert.True(t, auditLog.Success)
+		})
+	}
+}
@@ -0,0 +1,388 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/models"
+	"app/internal/utils"
+)
+
+// authState simulates what RequireAuth stores in the context
+type authState struct {
+	authenticated bool
+	userID        uuid.UUID
+	roles         interface{}
+	permissions   interface{}
+}
+
+func serveWithAuthState(state authState, guard gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resource", func(c *gin.Context) {
+		if state.authenticated {
+			c.Set("user_id", state.userID)
+			if state.roles != nil {
+				c.Set("user_roles", state.roles)
+			}
+			if state.permissions != nil {
+				c.Set("user_permissions", state.permissions)
+			}
+		}
+		c.Next()
+	}, guard, func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/resource", nil))
+	return w
+}
+
+func newTestAuthMiddleware() *middleware.AuthMiddleware {
+	return middleware.NewAuthMiddleware(auth.NewJWTService("test-secret", "test-issuer", 1), utils.NewLogger("error", "test"))
+}
+
+func TestRequireRole_StatusSemantics(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          authState
+		expectedStatus int
+	}{
+		{name: "unauthenticated", state: authState{}, expectedStatus: http.StatusUnauthorized},
+		{name: "missing role", state: authState{authenticated: true, roles: []string{"user"}}, expectedStatus: http.StatusForbidden},
+		{name: "no roles claim", state: authState{authenticated: true}, expectedStatus: http.StatusForbidden},
+		{name: "malformed roles", state: authState{authenticated: true, roles: "editor"}, expectedStatus: http.StatusForbidden},
+		{name: "has role", state: authState{authenticated: true, roles: []string{"editor"}}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := serveWithAuthState(tt.state, newTestAuthMiddleware().RequireRole("editor"))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.NotEmpty(t, w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestRequirePermission_StatusSemantics(t *testing.T) {
+	tests := []struct {
+		name           string
+		state          authState
+		expectedStatus int
+	}{
+		{name: "unauthenticated", state: authState{}, expectedStatus: http.StatusUnauthorized},
+		{name: "missing permission", state: authState{authenticated: true, permissions: []string{"user:read"}}, expectedStatus: http.StatusForbidden},
+		{name: "no permissions claim", state: authState{authenticated: true}, expectedStatus: http.StatusForbidden},
+		{name: "has permission", state: authState{authenticated: true, permissions: []string{"user:*"}}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := serveWithAuthState(tt.state, newTestAuthMiddleware().RequirePermission("user:delete"))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireOwnershipOrRole_StatusSemantics(t *testing.T) {
+	ownerID := uuid.New()
+	ownerOf := func(c *gin.Context) (uuid.UUID, error) { return ownerID, nil }
+
+	tests := []struct {
+		name           string
+		state          authState
+		expectedStatus int
+	}{
+		{name: "unauthenticated", state: authState{}, expectedStatus: http.StatusUnauthorized},
+		{name: "not owner", state: authState{authenticated: true, userID: uuid.New(), roles: []string{"user"}}, expectedStatus: http.StatusForbidden},
+		{name: "owner", state: authState{authenticated: true, userID: ownerID}, expectedStatus: http.StatusOK},
+		{name: "allowed role", state: authState{authenticated: true, userID: uuid.New(), roles: []string{"admin"}}, expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			w := serveWithAuthState(tt.state, newTestAuthMiddleware().RequireOwnershipOrRole(ownerOf, "admin"))
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+		})
+	}
+}
+
+func TestRequireAuth_InvalidTokenIsUnauthenticated(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/resource", newTestAuthMiddleware().RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer not-a-jwt")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.Equal(t, `Bearer realm="api"`, w.Header().Get("WWW-Authenticate"))
+}
+
+func TestRequireAuth_TokenErrorCodes(t *testing.T) {
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	user := &models.User{ID: uuid.New()}
+
+	expiredToken, err := auth.NewJWTService("test-secret", "test-issuer", -1).GenerateToken(user)
+	require.NoError(t, err)
+	foreignToken, err := auth.NewJWTService("other-secret", "test-issuer", 1).GenerateToken(user)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		token        string
+		expectedCode string
+	}{
+		{name: "malformed", token: "not-a-jwt", expectedCode: "TOKEN_MALFORMED"},
+		{name: "expired", token: expiredToken, expectedCode: "TOKEN_EXPIRED"},
+		{name: "bad signature", token: foreignToken, expectedCode: "TOKEN_SIGNATURE_INVALID"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.GET("/resource", middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test")).RequireAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusUnauthorized, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedCode, body["code"])
+		})
+	}
+}
+
+func TestRequireAuth_SubjectOnlyTokenSetsUserID(t *testing.T) {
+	// Arrange
+	userID := uuid.New()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}).SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	var current *middleware.CurrentUser
+	router := gin.New()
+	router.GET("/resource", newTestAuthMiddleware().RequireAuth(), func(c *gin.Context) {
+		current, err = middleware.GetCurrentUser(c)
+		c.Status(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, err)
+	assert.Equal(t, userID, current.ID)
+}
+
+// fakeTokenVersions returns a fixed current token version
+type fakeTokenVersions struct {
+	version int
+}
+
+func (f *fakeTokenVersions) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int, error) {
+	return f.version, nil
+}
+
+func TestRequireAuth_RejectsOutdatedTokenVersion(t *testing.T) {
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	token, err := jwtService.GenerateToken(&models.User{ID: uuid.New(), TokenVersion: 2})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		currentVersion int
+		expectedStatus int
+	}{
+		{name: "current version accepted", currentVersion: 2, expectedStatus: http.StatusOK},
+		{name: "bumped version rejected", currentVersion: 3, expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"),
+				middleware.WithTokenVersionSource(&fakeTokenVersions{version: tt.currentVersion}))
+			router := gin.New()
+			router.GET("/resource", authMiddleware.RequireAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "TOKEN_REVOKED", body["code"])
+			}
+		})
+	}
+}
+
+func TestOptionalAuth_IgnoresOutdatedTokenVersion(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	token, err := jwtService.GenerateToken(&models.User{ID: uuid.New()})
+	require.NoError(t, err)
+
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"),
+		middleware.WithTokenVersionSource(&fakeTokenVersions{version: 1}))
+	router := gin.New()
+	router.GET("/resource", authMiddleware.OptionalAuth(), func(c *gin.Context) {
+		_, authenticated := c.Get("user_id")
+		c.JSON(http.StatusOK, gin.H{"authenticated": authenticated})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"authenticated": false}`, w.Body.String())
+}
+
+// fakeTokenBlacklist is an in-memory token blacklist
+type fakeTokenBlacklist struct {
+	revoked map[string]bool
+	err     error
+}
+
+func (f *fakeTokenBlacklist) BlacklistToken(tokenID string, expiresAt time.Time) error {
+	f.revoked[tokenID] = true
+	return nil
+}
+
+func (f *fakeTokenBlacklist) IsTokenBlacklisted(tokenID string) (bool, error) {
+	return f.revoked[tokenID], f.err
+}
+
+func (f *fakeTokenBlacklist) CleanupExpiredTokens() error {
+	return nil
+}
+
+func TestRequireAuth_RejectsBlacklistedTokens(t *testing.T) {
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	user := &models.User{ID: uuid.New()}
+
+	revokedToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	revokedClaims, err := jwtService.ValidateToken(revokedToken)
+	require.NoError(t, err)
+	activeToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name           string
+		token          string
+		lookupErr      error
+		expectedStatus int
+	}{
+		{name: "active token accepted", token: activeToken, expectedStatus: http.StatusOK},
+		{name: "blacklisted token rejected", token: revokedToken, expectedStatus: http.StatusUnauthorized},
+		{name: "lookup failure rejected", token: activeToken, lookupErr: errors.New("redis down"), expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			blacklist := &fakeTokenBlacklist{revoked: map[string]bool{revokedClaims.ID: true}, err: tt.lookupErr}
+			authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"),
+				middleware.WithTokenBlacklist(blacklist))
+			router := gin.New()
+			router.GET("/resource", authMiddleware.RequireAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "TOKEN_REVOKED", body["code"])
+			}
+		})
+	}
+}
+
+func TestRedisTokenBlacklist_EmptyTokenID(t *testing.T) {
+	// Arrange - empty IDs are handled before Redis is touched
+	blacklist := auth.NewRedisTokenBlacklist(nil, "")
+
+	// Act
+	blacklistErr := blacklist.BlacklistToken("", time.Now().Add(time.Hour))
+	blacklisted, lookupErr := blacklist.IsTokenBlacklisted("")
+
+	// Assert
+	assert.ErrorIs(t, blacklistErr, auth.ErrMissingTokenID)
+	assert.NoError(t, lookupErr)
+	assert.False(t, blacklisted)
+	assert.NoError(t, blacklist.CleanupExpiredTokens())
+}
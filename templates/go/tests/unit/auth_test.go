@@ -1,9 +1,16 @@
 package unit
 
 import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -69,7 +76,8 @@ func TestJWTService_ValidateToken(t *testing.T) {
 
 func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
 	// Arrange
-	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", -1) // Expired immediately
+	clock := &fakeClock{now: time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithClock(clock))
 	
 	user := &models.User{
 		ID:       uuid.New(),
@@ -80,8 +88,11 @@ func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
 	token, err := jwtService.GenerateToken(user)
 	require.NoError(t, err)
 
-	// Wait for token to expire
-	time.Sleep(time.Millisecond * 100)
+	_, err = jwtService.ValidateToken(token)
+	require.NoError(t, err)
+
+	// Advance past the one hour expiry
+	clock.Advance(time.Hour + time.Second)
 
 	// Act
 	_, err = jwtService.ValidateToken(token)
@@ -91,6 +102,26 @@ func TestJWTService_ValidateToken_ExpiredToken(t *testing.T) {
 	assert.Contains(t, err.Error(), "expired")
 }
 
+func TestJWTService_ValidateToken_NotYetValidUntilClockAdvances(t *testing.T) {
+	// Arrange
+	issuerClock := &fakeClock{now: time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	validatorClock := &fakeClock{now: issuerClock.now.Add(-time.Minute)}
+	issuer := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithClock(issuerClock))
+	validator := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithClock(validatorClock))
+
+	token, err := issuer.GenerateToken(&models.User{ID: uuid.New()})
+	require.NoError(t, err)
+
+	// Act
+	_, errBefore := validator.ValidateToken(token)
+	validatorClock.Advance(time.Minute)
+	_, errAfter := validator.ValidateToken(token)
+
+	// Assert
+	assert.Error(t, errBefore)
+	assert.NoError(t, errAfter)
+}
+
 func TestJWTService_ValidateToken_InvalidSignature(t *testing.T) {
 	// Arrange
 	jwtService1 := auth.NewJWTService("secret-key-1", "test-issuer", 24)
@@ -112,6 +143,395 @@ func TestJWTService_ValidateToken_InvalidSignature(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestJWTService_ValidateToken_DistinctErrors(t *testing.T) {
+	clock := &fakeClock{now: time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC)}
+	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithClock(clock))
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Username: "testuser"}
+
+	expiredToken, err := auth.NewJWTService("test-secret-key", "test-issuer", 1,
+		auth.WithClock(&fakeClock{now: clock.now.Add(-2 * time.Hour)})).GenerateToken(user)
+	require.NoError(t, err)
+	foreignToken, err := auth.NewJWTService("other-secret-key", "test-issuer", 1, auth.WithClock(clock)).GenerateToken(user)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		token    string
+		expected error
+	}{
+		{name: "malformed", token: "not-a-jwt", expected: auth.ErrTokenMalformed},
+		{name: "expired", token: expiredToken, expected: auth.ErrTokenExpired},
+		{name: "bad signature", token: foreignToken, expected: auth.ErrTokenSignatureInvalid},
+	}
+
+	sentinels := []error{auth.ErrTokenMalformed, auth.ErrTokenExpired, auth.ErrTokenSignatureInvalid}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := jwtService.ValidateToken(tt.token)
+
+			// Assert - each failure matches its own error and no other
+			require.Error(t, err)
+			for _, sentinel := range sentinels {
+				assert.Equal(t, sentinel == tt.expected, errors.Is(err, sentinel), "errors.Is(err, %q)", sentinel)
+			}
+		})
+	}
+}
+
+func TestJWTService_ValidateToken_Subject(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		name     string
+		userID   uuid.UUID
+		subject  string
+		expected uuid.UUID
+		wantErr  bool
+	}{
+		{name: "subject only", subject: userID.String(), expected: userID},
+		{name: "user_id only", userID: userID, expected: userID},
+		{name: "matching subject and user_id", userID: userID, subject: userID.String(), expected: userID},
+		{name: "conflicting subject and user_id", userID: uuid.New(), subject: userID.String(), wantErr: true},
+		{name: "subject is not a user ID", subject: "not-a-uuid", wantErr: true},
+		{name: "neither", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 1)
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+				UserID: tt.userID,
+				RegisteredClaims: jwt.RegisteredClaims{
+					Subject:   tt.subject,
+					ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+				},
+			}).SignedString([]byte("test-secret-key"))
+			require.NoError(t, err)
+
+			// Act
+			claims, err := jwtService.ValidateToken(token)
+
+			// Assert - sub and user_id always agree on accepted tokens
+			if tt.wantErr {
+				assert.ErrorIs(t, err, auth.ErrTokenInvalid)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, claims.UserID)
+			assert.Equal(t, tt.expected.String(), claims.Subject)
+		})
+	}
+}
+
+func TestJWTService_GenerateTokenWithMethod(t *testing.T) {
+	// Arrange
+	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 24)
+
+	user := &models.User{
+		ID:       uuid.New(),
+		Email:    "test@example.com",
+		Username: "testuser",
+	}
+
+	for _, method := range []string{auth.AuthMethodPassword, auth.AuthMethodOAuth, auth.AuthMethodMagicLink, auth.AuthMethodTOTP} {
+		t.Run(method, func(t *testing.T) {
+			// Act
+			token, err := jwtService.GenerateTokenWithMethod(user, method)
+			require.NoError(t, err)
+
+			claims, err := jwtService.ValidateToken(token)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, method, claims.AuthMethod)
+		})
+	}
+}
+
+func TestJWTService_ValidateToken_NotBeforeSkew(t *testing.T) {
+	tests := []struct {
+		name      string
+		notBefore time.Duration
+		wantErr   bool
+	}{
+		{name: "nbf within skew accepted", notBefore: 10 * time.Second, wantErr: false},
+		{name: "nbf beyond skew rejected", notBefore: time.Hour, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 24, auth.WithNotBeforeSkew(30*time.Second))
+
+			now := time.Now()
+			claims := auth.Claims{
+				UserID: uuid.New(),
+				RegisteredClaims: jwt.RegisteredClaims{
+					IssuedAt:  jwt.NewNumericDate(now),
+					NotBefore: jwt.NewNumericDate(now.Add(tt.notBefore)),
+					ExpiresAt: jwt.NewNumericDate(now.Add(2 * time.Hour)),
+				},
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+			require.NoError(t, err)
+
+			// Act
+			_, err = jwtService.ValidateToken(token)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJWTService_ValidateToken_NotBeforeWithoutSkew(t *testing.T) {
+	// Arrange
+	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 24)
+
+	now := time.Now()
+	claims := auth.Claims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(now.Add(10 * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+	require.NoError(t, err)
+
+	// Act
+	_, err = jwtService.ValidateToken(token)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestJWTService_ValidateToken_Leeway(t *testing.T) {
+	tests := []struct {
+		name    string
+		leeway  time.Duration
+		wantErr bool
+	}{
+		{name: "expired within leeway accepted", leeway: 5 * time.Second, wantErr: false},
+		{name: "expired without leeway rejected", leeway: 0, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 24)
+			jwtService.SetLeeway(tt.leeway)
+
+			now := time.Now()
+			claims := auth.Claims{
+				UserID: uuid.New(),
+				RegisteredClaims: jwt.RegisteredClaims{
+					IssuedAt:  jwt.NewNumericDate(now.Add(-time.Hour)),
+					ExpiresAt: jwt.NewNumericDate(now.Add(-2 * time.Second)),
+				},
+			}
+			token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+			require.NoError(t, err)
+
+			// Act
+			_, err = jwtService.ValidateToken(token)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJWTService_ValidateToken_LeewayAppliesToNotBefore(t *testing.T) {
+	// Arrange
+	jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 24)
+	jwtService.SetLeeway(5 * time.Second)
+
+	now := time.Now()
+	claims := auth.Claims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			NotBefore: jwt.NewNumericDate(now.Add(2 * time.Second)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("test-secret-key"))
+	require.NoError(t, err)
+
+	// Act
+	_, err = jwtService.ValidateToken(token)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestJWTService_ValidateToken_Audience(t *testing.T) {
+	// Arrange
+	issuer := auth.NewJWTService("test-secret-key", "test-issuer", 1)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Username: "testuser"}
+
+	clientAToken, err := issuer.GenerateTokenForAudience(user, "", "client-a")
+	require.NoError(t, err)
+	unscopedToken, err := issuer.GenerateToken(user)
+	require.NoError(t, err)
+
+	clientA := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithAudiences("client-a"))
+	clientB := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithAudiences("client-b"))
+	both := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithAudiences("client-b", "client-a"))
+
+	// Act & Assert - a token minted for client A is only accepted where A is an accepted audience
+	claims, err := clientA.ValidateToken(clientAToken)
+	require.NoError(t, err)
+	assert.Equal(t, jwt.ClaimStrings{"client-a"}, claims.Audience)
+
+	_, err = clientB.ValidateToken(clientAToken)
+	assert.ErrorContains(t, err, "audience")
+
+	_, err = both.ValidateToken(clientAToken)
+	assert.NoError(t, err)
+
+	// Act & Assert - unscoped tokens fail audience enforcement but pass without it
+	_, err = clientB.ValidateToken(unscopedToken)
+	assert.ErrorContains(t, err, "audience")
+
+	_, err = issuer.ValidateToken(clientAToken)
+	assert.NoError(t, err)
+}
+
+func TestJWTService_ValidateTokenForAudience(t *testing.T) {
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Username: "testuser"}
+
+	tests := []struct {
+		name     string
+		audience []string
+		expected string
+		wantErr  bool
+	}{
+		{name: "matching audience", audience: []string{"orders-api"}, expected: "orders-api"},
+		{name: "one of several audiences", audience: []string{"billing-api", "orders-api"}, expected: "orders-api"},
+		{name: "mismatched audience", audience: []string{"billing-api"}, expected: "orders-api", wantErr: true},
+		{name: "missing audience", expected: "orders-api", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithTokenAudience(tt.audience...))
+			token, err := jwtService.GenerateToken(user)
+			require.NoError(t, err)
+
+			// Act
+			claims, err := jwtService.ValidateTokenForAudience(token, tt.expected)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "audience")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, jwt.ClaimStrings(tt.audience), claims.Audience)
+		})
+	}
+}
+
+func TestJWTService_ValidateToken_IgnoresTokenAudience(t *testing.T) {
+	// Arrange
+	scoped := auth.NewJWTService("test-secret-key", "test-issuer", 1, auth.WithTokenAudience("orders-api"))
+	unscoped := auth.NewJWTService("test-secret-key", "test-issuer", 1)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Username: "testuser"}
+
+	scopedToken, err := scoped.GenerateToken(user)
+	require.NoError(t, err)
+	unscopedToken, err := unscoped.GenerateToken(user)
+	require.NoError(t, err)
+
+	// Act & Assert - plain validation accepts tokens with and without an audience
+	_, err = unscoped.ValidateToken(scopedToken)
+	assert.NoError(t, err)
+
+	claims, err := scoped.ValidateToken(unscopedToken)
+	require.NoError(t, err)
+	assert.Empty(t, claims.Audience)
+}
+
+func TestJWTService_RS256(t *testing.T) {
+	// Arrange
+	privatePEM, publicPEM := generateRSAKeyPair(t)
+	jwtService, err := auth.NewJWTServiceRSA(privatePEM, publicPEM, "test-issuer", 1)
+	require.NoError(t, err)
+	user := &models.User{ID: uuid.New(), Email: "test@example.com", Username: "testuser"}
+
+	// Act
+	token, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+	claims, err := jwtService.ValidateToken(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, claims.UserID)
+	assert.Equal(t, publicPEM, jwtService.PublicKeyPEM())
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &auth.Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "RS256", parsed.Method.Alg())
+}
+
+func TestJWTService_RejectsAlgorithmMismatch(t *testing.T) {
+	// Arrange
+	privatePEM, publicPEM := generateRSAKeyPair(t)
+	rsaService, err := auth.NewJWTServiceRSA(privatePEM, publicPEM, "test-issuer", 1)
+	require.NoError(t, err)
+	hmacService := auth.NewJWTService("test-secret-key", "test-issuer", 1)
+	user := &models.User{ID: uuid.New()}
+
+	// An attacker signs an HS256 token using the public key as the secret
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		UserID: user.ID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			Issuer:    "test-issuer",
+		},
+	}).SignedString(publicPEM)
+	require.NoError(t, err)
+
+	rsaToken, err := rsaService.GenerateToken(user)
+	require.NoError(t, err)
+
+	// Act & Assert
+	_, err = rsaService.ValidateToken(forged)
+	assert.ErrorContains(t, err, "unexpected signing method")
+
+	_, err = hmacService.ValidateToken(rsaToken)
+	assert.ErrorContains(t, err, "unexpected signing method")
+
+	assert.Nil(t, hmacService.PublicKeyPEM())
+}
+
+func TestNewJWTServiceRSA_InvalidKeys(t *testing.T) {
+	// Arrange
+	privatePEM, publicPEM := generateRSAKeyPair(t)
+	_, otherPublicPEM := generateRSAKeyPair(t)
+
+	// Act & Assert
+	_, err := auth.NewJWTServiceRSA([]byte("not a key"), publicPEM, "test-issuer", 1)
+	assert.ErrorContains(t, err, "private key")
+
+	_, err = auth.NewJWTServiceRSA(privatePEM, []byte("not a key"), "test-issuer", 1)
+	assert.ErrorContains(t, err, "public key")
+
+	_, err = auth.NewJWTServiceRSA(privatePEM, otherPublicPEM, "test-issuer", 1)
+	assert.ErrorContains(t, err, "does not match")
+}
+
 func TestPasswordService_HashPassword(t *testing.T) {
 	// Arrange
 	passwordService := auth.NewPasswordService(12)
@@ -208,6 +628,89 @@ func TestValidatePassword(t *testing.T) {
 	}
 }
 
+func TestPasswordService_PasswordPolicy(t *testing.T) {
+	loose := auth.PasswordPolicy{MinLength: 6}
+	strict := auth.DefaultPasswordPolicy()
+	strict.MinLength = 16
+
+	tests := []struct {
+		name        string
+		password    string
+		passDefault bool
+		passLoose   bool
+		passStrict  bool
+	}{
+		{name: "lowercase only", password: "sunflower", passDefault: false, passLoose: true, passStrict: false},
+		{name: "sequential characters", password: "xyz987", passDefault: false, passLoose: true, passStrict: false},
+		{name: "repeated characters", password: "mooon!", passDefault: false, passLoose: true, passStrict: false},
+		{name: "meets default policy", password: "Tr0ub4dor&3", passDefault: true, passLoose: true, passStrict: false},
+		{name: "meets strict policy", password: "Bright-Meadow-62!", passDefault: true, passLoose: true, passStrict: true},
+		{name: "too short for any policy", password: "Ab1!", passDefault: false, passLoose: false, passStrict: false},
+		{name: "common password", password: "letmein2024", passDefault: false, passLoose: false, passStrict: false},
+	}
+
+	defaultService := auth.NewPasswordService(4)
+	looseService := auth.NewPasswordService(4, auth.WithPasswordPolicy(loose))
+	strictService := auth.NewPasswordService(4, auth.WithPasswordPolicy(strict))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.passDefault, defaultService.IsPasswordValid(tt.password) == nil, "default policy")
+			assert.Equal(t, tt.passLoose, looseService.IsPasswordValid(tt.password) == nil, "loose policy")
+			assert.Equal(t, tt.passStrict, strictService.IsPasswordValid(tt.password) == nil, "strict policy")
+		})
+	}
+}
+
+func TestPasswordPolicy_MaxLength(t *testing.T) {
+	// Arrange
+	policy := auth.PasswordPolicy{MinLength: 1, MaxLength: 10}
+
+	// Act & Assert
+	assert.NoError(t, policy.Validate("k9#Qz!vR2m"))
+	assert.ErrorContains(t, policy.Validate("k9#Qz!vR2mX"), "no more than 10")
+	assert.NoError(t, auth.PasswordPolicy{MinLength: 1}.Validate(strings.Repeat("k9#Qz!", 30)), "0 means no maximum")
+}
+
+func TestPasswordService_MinEntropyBits(t *testing.T) {
+	tests := []struct {
+		name          string
+		password      string
+		passesClasses bool
+		passesEntropy bool
+	}{
+		{name: "long passphrase", password: "correct horse battery staple", passesClasses: false, passesEntropy: true},
+		{name: "short password ticking every class", password: "Secur3!x", passesClasses: true, passesEntropy: false},
+		{name: "repeated chunk", password: "Xy7!Xy7!Xy7!", passesClasses: true, passesEntropy: false},
+		{name: "strong password", password: "Tr0ub4dor&3", passesClasses: true, passesEntropy: true},
+		{name: "common password", password: "Password123!", passesClasses: false, passesEntropy: false},
+	}
+
+	classService := auth.NewPasswordService(4)
+	entropyService := auth.NewPasswordService(4, auth.WithMinEntropyBits(60))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			classErr := classService.IsPasswordValid(tt.password)
+			entropyErr := entropyService.IsPasswordValid(tt.password)
+
+			// Assert
+			assert.Equal(t, tt.passesClasses, classErr == nil, "class rules: %v", classErr)
+			assert.Equal(t, tt.passesEntropy, entropyErr == nil, "entropy: %v", entropyErr)
+		})
+	}
+}
+
+func TestEstimatePasswordEntropy(t *testing.T) {
+	// Random characters are worth far more than predictable ones of the same length
+	assert.Zero(t, auth.EstimatePasswordEntropy(""))
+	assert.Greater(t, auth.EstimatePasswordEntropy("k9#Qz!vR2m"), auth.EstimatePasswordEntropy("abcdefghij"))
+	assert.Greater(t, auth.EstimatePasswordEntropy("k9#Qz!vR2m"), auth.EstimatePasswordEntropy("aaaaaaaaaa"))
+	assert.Greater(t, auth.EstimatePasswordEntropy("k9#Qz!vR2m"), auth.EstimatePasswordEntropy("password12"))
+}
+
 func TestGetPasswordStrength(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -351,4 +854,32 @@ func BenchmarkPasswordService_VerifyPassword(b *testing.B) {
 			b.Fatal(err)
 		}
 	}
-}
\ No newline at end of file
+}
+
+// fakeClock is a manually advanced clock for deterministic token tests
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// generateRSAKeyPair returns a PEM encoded 2048-bit RSA key pair
+func generateRSAKeyPair(t *testing.T) (privatePEM, publicPEM []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	publicDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	require.NoError(t, err)
+
+	privatePEM = pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+	publicPEM = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, publicPEM
+}
@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/api/middleware"
+	"app/internal/utils"
+)
+
+// blockingRouter serves /slow as the user in the X-Test-User header; each
+// request signals started and then waits until release is closed
+func blockingRouter(limiter *middleware.ConcurrencyLimiter, started chan<- struct{}, release <-chan struct{}) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set("user_id", uuid.MustParse(c.GetHeader("X-Test-User")))
+		c.Next()
+	})
+	router.GET("/slow", limiter.Limit(), func(c *gin.Context) {
+		started <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+	return router
+}
+
+func serveAs(router *gin.Engine, userID uuid.UUID) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/slow", nil)
+	req.Header.Set("X-Test-User", userID.String())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestConcurrencyLimiter_RejectsRequestsBeyondLimit(t *testing.T) {
+	// Arrange
+	const limit = 2
+	limiter := middleware.NewConcurrencyLimiter(limit, utils.NewLogger("error", "test"))
+	started := make(chan struct{}, limit+1)
+	release := make(chan struct{})
+	router := blockingRouter(limiter, started, release)
+	userID := uuid.New()
+
+	var wg sync.WaitGroup
+	codes := make([]int, limit)
+	for i := 0; i < limit; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = serveAs(router, userID).Code
+		}(i)
+	}
+	for i := 0; i < limit; i++ {
+		<-started
+	}
+
+	// Act - one more request while the first ones are in flight
+	rejected := serveAs(router, userID)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, rejected.Code)
+	assert.Contains(t, rejected.Body.String(), "CONCURRENCY_LIMIT_EXCEEDED")
+	assert.Equal(t, "1", rejected.Header().Get("Retry-After"))
+
+	close(release)
+	wg.Wait()
+	for _, code := range codes {
+		assert.Equal(t, http.StatusOK, code)
+	}
+
+	// Assert - slots are freed once requests complete
+	assert.Equal(t, http.StatusOK, serveAs(router, userID).Code)
+}
+
+func TestConcurrencyLimiter_LimitsEachUserSeparately(t *testing.T) {
+	// Arrange
+	limiter := middleware.NewConcurrencyLimiter(1, utils.NewLogger("error", "test"))
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+	router := blockingRouter(limiter, started, release)
+
+	done := make(chan int)
+	go func() {
+		done <- serveAs(router, uuid.New()).Code
+	}()
+	<-started
+
+	// Act - a different user is not affected by the first user's request
+	go func() {
+		done <- serveAs(router, uuid.New()).Code
+	}()
+	<-started
+	close(release)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, <-done)
+	assert.Equal(t, http.StatusOK, <-done)
+}
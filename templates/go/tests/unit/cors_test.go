@@ -0,0 +1,185 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func exposedHeaders(t *testing.T, cfg *config.Config) []string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewSecurityMiddleware(cfg, utils.NewLogger("error", "test")).CORS())
+	router.GET("/resource", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var headers []string
+	for _, header := range strings.Split(w.Header().Get("Access-Control-Expose-Headers"), ",") {
+		headers = append(headers, strings.ToLower(strings.TrimSpace(header)))
+	}
+	return headers
+}
+
+func TestCORS_ExposesConfiguredHeaders(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{
+		Environment:        "test",
+		CORSAllowedOrigins: []string{"http://localhost:3000"},
+		CORSAllowedMethods: []string{"GET"},
+		CORSExposedHeaders: []string{"X-Total-Count", "X-Request-ID"},
+	}
+
+	// Act
+	headers := exposedHeaders(t, cfg)
+
+	// Assert
+	assert.ElementsMatch(t, []string{"x-total-count", "x-request-id"}, headers)
+}
+
+func TestCORS_DefaultExposedHeadersIncludeRateLimit(t *testing.T) {
+	// Arrange
+	t.Setenv("CORS_ALLOWED_ORIGINS", "http://localhost:3000")
+	cfg, err := config.Load()
+	require.NoError(t, err)
+
+	// Act
+	headers := exposedHeaders(t, cfg)
+
+	// Assert
+	for _, header := range []string{"x-request-id", "x-ratelimit-limit", "x-ratelimit-remaining", "x-ratelimit-reset", "retry-after"} {
+		assert.Contains(t, headers, header)
+	}
+}
+
+func allowedOrigin(cfg *config.Config, origin string) string {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewSecurityMiddleware(cfg, utils.NewLogger("error", "test")).CORS())
+	router.GET("/resource", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+	req.Header.Set("Origin", origin)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	return w.Header().Get("Access-Control-Allow-Origin")
+}
+
+func TestCORS_DevelopmentAllowsLocalhostPortRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     string
+		origin  string
+		allowed bool
+	}{
+		{name: "dev localhost in range", env: "development", origin: "http://localhost:5173", allowed: true},
+		{name: "dev loopback ip in range", env: "development", origin: "http://127.0.0.1:4200", allowed: true},
+		{name: "dev localhost out of range", env: "development", origin: "http://localhost:80", allowed: false},
+		{name: "dev localhost without port", env: "development", origin: "http://localhost", allowed: false},
+		{name: "dev other host", env: "development", origin: "http://localhost.evil.com:5173", allowed: false},
+		{name: "dev explicit origin", env: "development", origin: "https://app.example.com", allowed: true},
+		{name: "prod localhost", env: "production", origin: "http://localhost:5173", allowed: false},
+		{name: "prod explicit origin", env: "production", origin: "https://app.example.com", allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := &config.Config{
+				Environment:             tt.env,
+				CORSAllowedOrigins:      []string{"https://app.example.com"},
+				CORSAllowedMethods:      []string{"GET"},
+				CORSDevLocalhostPortMin: 3000,
+				CORSDevLocalhostPortMax: 9999,
+			}
+
+			// Act
+			allowOrigin := allowedOrigin(cfg, tt.origin)
+
+			// Assert
+			if tt.allowed {
+				assert.Equal(t, tt.origin, allowOrigin)
+			} else {
+				assert.Empty(t, allowOrigin)
+			}
+		})
+	}
+}
+
+func TestCORS_OriginMatching(t *testing.T) {
+	trusted := func(origin string) bool { return origin == "https://partner.example.net" }
+
+	tests := []struct {
+		name    string
+		origins []string
+		origin  string
+		allowed bool
+	}{
+		{name: "exact origin", origins: []string{"https://app.example.com"}, origin: "https://app.example.com", allowed: true},
+		{name: "wildcard subdomain", origins: []string{"https://*.example.com"}, origin: "https://tenant.example.com", allowed: true},
+		{name: "wildcard nested subdomain", origins: []string{"https://*.example.com"}, origin: "https://a.tenant.example.com", allowed: true},
+		{name: "wildcard with port", origins: []string{"https://*.example.com:8443"}, origin: "https://tenant.example.com:8443", allowed: true},
+		{name: "wildcard excludes bare domain", origins: []string{"https://*.example.com"}, origin: "https://example.com", allowed: false},
+		{name: "wildcard excludes other scheme", origins: []string{"https://*.example.com"}, origin: "http://tenant.example.com", allowed: false},
+		{name: "wildcard excludes other port", origins: []string{"https://*.example.com"}, origin: "https://tenant.example.com:8443", allowed: false},
+		{name: "wildcard excludes lookalike domain", origins: []string{"https://*.example.com"}, origin: "https://evilexample.com", allowed: false},
+		{name: "wildcard excludes suffix attack", origins: []string{"https://*.example.com"}, origin: "https://example.com.evil.com", allowed: false},
+		{name: "unlisted origin", origins: []string{"https://app.example.com"}, origin: "https://evil.com", allowed: false},
+		{name: "star is never honoured", origins: []string{"*"}, origin: "https://evil.com", allowed: false},
+		{name: "predicate allows", origins: []string{"https://app.example.com"}, origin: "https://partner.example.net", allowed: true},
+		{name: "predicate rejects", origins: []string{"https://app.example.com"}, origin: "https://other.example.net", allowed: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			cfg := &config.Config{
+				Environment:        "production",
+				CORSAllowedOrigins: tt.origins,
+				CORSAllowedMethods: []string{"GET"},
+			}
+			router := gin.New()
+			router.Use(middleware.NewSecurityMiddleware(cfg, utils.NewLogger("error", "test"), middleware.WithOriginPredicate(trusted)).CORS())
+			router.GET("/resource", func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/resource", nil)
+			req.Header.Set("Origin", tt.origin)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			allowOrigin := w.Header().Get("Access-Control-Allow-Origin")
+			assert.NotEqual(t, "*", allowOrigin, "credentialed responses must name the origin")
+			if tt.allowed {
+				assert.Equal(t, tt.origin, allowOrigin)
+				assert.Equal(t, "true", w.Header().Get("Access-Control-Allow-Credentials"))
+			} else {
+				assert.Empty(t, allowOrigin)
+			}
+		})
+	}
+}
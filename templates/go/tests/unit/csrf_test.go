@@ -0,0 +1,115 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func setupCSRFRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{Environment: "production", CSRFExemptPaths: []string{"/api/v1/auth"}}
+	securityMiddleware := middleware.NewSecurityMiddleware(cfg, utils.NewLogger("error", "test"))
+
+	router := gin.New()
+	router.Use(securityMiddleware.CSRFProtection())
+	router.GET("/api/v1/csrf-token", securityMiddleware.CSRFToken())
+	router.POST("/api/v1/user/profile", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/v1/auth/login", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return router
+}
+
+// issueCSRFToken fetches a token and returns it with the cookie that carries it
+func issueCSRFToken(t *testing.T, router *gin.Engine) (string, *http.Cookie) {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/csrf-token", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		CSRFToken string `json:"csrf_token"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == middleware.CSRFCookieName {
+			cookie = c
+		}
+	}
+	require.NotNil(t, cookie)
+	return body.CSRFToken, cookie
+}
+
+func TestCSRFToken_IssuesCookieAndBody(t *testing.T) {
+	// Arrange
+	router := setupCSRFRouter()
+
+	// Act
+	token, cookie := issueCSRFToken(t, router)
+	otherToken, _ := issueCSRFToken(t, router)
+
+	// Assert
+	assert.NotEmpty(t, token)
+	assert.Equal(t, token, cookie.Value)
+	assert.True(t, cookie.Secure)
+	assert.True(t, cookie.HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, cookie.SameSite)
+	assert.NotEqual(t, token, otherToken, "every token must be random")
+}
+
+func TestCSRFProtection(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		sendCookie     bool
+		header         string
+		expectedStatus int
+	}{
+		{name: "valid token", path: "/api/v1/user/profile", sendCookie: true, header: "match", expectedStatus: http.StatusOK},
+		{name: "missing header", path: "/api/v1/user/profile", sendCookie: true, header: "", expectedStatus: http.StatusForbidden},
+		{name: "missing cookie", path: "/api/v1/user/profile", sendCookie: false, header: "match", expectedStatus: http.StatusForbidden},
+		{name: "mismatched token", path: "/api/v1/user/profile", sendCookie: true, header: "forged", expectedStatus: http.StatusForbidden},
+		{name: "exempt path", path: "/api/v1/auth/login", sendCookie: false, header: "", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := setupCSRFRouter()
+			token, cookie := issueCSRFToken(t, router)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			if tt.sendCookie {
+				req.AddCookie(cookie)
+			}
+			switch tt.header {
+			case "match":
+				req.Header.Set(middleware.CSRFHeader, token)
+			case "":
+			default:
+				req.Header.Set(middleware.CSRFHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusForbidden {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "CSRF_TOKEN_INVALID", body["code"])
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package unit
+
+import (
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/email"
+	"app/internal/models"
+)
+
+func TestRenderer_DefaultTemplates(t *testing.T) {
+	renderer, err := email.NewDefaultRenderer()
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2030, time.March, 4, 15, 30, 0, 0, time.UTC)
+	data := email.TemplateData{
+		AppName:   "Acme",
+		User:      models.UserResponse{FirstName: "Ada", Email: "ada@example.com"},
+		Link:      "https://example.com/action?token=abc123",
+		ExpiresAt: expiresAt,
+	}
+
+	tests := []struct {
+		name            string
+		expectedSubject string
+	}{
+		{name: email.TemplateVerification, expectedSubject: "Verify your Acme email address"},
+		{name: email.TemplatePasswordReset, expectedSubject: "Reset your Acme password"},
+		{name: email.TemplateAccountLockout, expectedSubject: "Your Acme account has been locked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			msg, err := renderer.Render(tt.name, data)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedSubject, msg.Subject)
+			for _, body := range []string{msg.Text, msg.HTML} {
+				assert.Contains(t, body, "Ada")
+				assert.Contains(t, body, data.Link)
+				assert.Contains(t, body, "March 4, 2030 15:30 UTC")
+			}
+		})
+	}
+}
+
+func TestRenderer_EscapesHTML(t *testing.T) {
+	// Arrange
+	renderer, err := email.NewDefaultRenderer()
+	require.NoError(t, err)
+
+	data := email.TemplateData{
+		User: models.UserResponse{FirstName: "<script>alert(1)</script>"},
+		Link: "https://example.com/verify",
+	}
+
+	// Act
+	msg, err := renderer.Render(email.TemplateVerification, data)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotContains(t, msg.HTML, "<script>")
+	assert.Contains(t, msg.HTML, "&lt;script&gt;")
+}
+
+func TestRenderer_CustomTemplates(t *testing.T) {
+	// Arrange
+	fsys := fstest.MapFS{
+		"welcome.subject.tmpl": {Data: []byte("Welcome {{.User.FirstName}}")},
+		"welcome.txt.tmpl":     {Data: []byte("Start here: {{.Link}}")},
+		"welcome.html.tmpl":    {Data: []byte(`<a href="{{.Link}}">Start</a>`)},
+	}
+	renderer, err := email.NewRenderer(fsys)
+	require.NoError(t, err)
+
+	// Act
+	msg, err := renderer.Render("welcome", email.TemplateData{
+		User: models.UserResponse{FirstName: "Ada"},
+		Link: "https://example.com/start",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome Ada", msg.Subject)
+	assert.Equal(t, "Start here: https://example.com/start", msg.Text)
+	assert.Contains(t, msg.HTML, "https://example.com/start")
+
+	_, err = renderer.Render("missing", email.TemplateData{})
+	assert.Error(t, err)
+}
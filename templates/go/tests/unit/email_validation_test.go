@@ -0,0 +1,101 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/utils"
+)
+
+func TestEmailValidator_Normalize(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		expected string
+	}{
+		{name: "already normalized", address: "jane@example.com", expected: "jane@example.com"},
+		{name: "mixed case", address: "Jane.Doe@Example.COM", expected: "jane.doe@example.com"},
+		{name: "surrounding whitespace", address: "  jane@example.com\t", expected: "jane@example.com"},
+		{name: "plus tag and subdomain", address: "jane+news@mail.example.co.uk", expected: "jane+news@mail.example.co.uk"},
+	}
+
+	validator := utils.NewEmailValidator(false)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			normalized, err := validator.Normalize(context.Background(), tt.address)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestEmailValidator_RejectsMalformed(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+	}{
+		{name: "empty", address: ""},
+		{name: "missing at", address: "jane.example.com"},
+		{name: "display name", address: "Jane <jane@example.com>"},
+		{name: "dotless domain", address: "jane@localhost"},
+		{name: "numeric tld", address: "jane@example.123"},
+		{name: "ip literal", address: "jane@[127.0.0.1]"},
+		{name: "leading dot", address: ".jane@example.com"},
+		{name: "consecutive dots", address: "jane..doe@example.com"},
+		{name: "hyphen edged label", address: "jane@-example.com"},
+		{name: "empty label", address: "jane@example..com"},
+		{name: "quoted local part", address: `"jane doe"@example.com`},
+		{name: "local part too long", address: "abcdefghijabcdefghijabcdefghijabcdefghijabcdefghijabcdefghij12345@example.com"},
+	}
+
+	validator := utils.NewEmailValidator(false)
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := validator.Normalize(context.Background(), tt.address)
+
+			// Assert
+			assert.ErrorIs(t, err, utils.ErrInvalidEmail)
+		})
+	}
+}
+
+func TestEmailValidator_CheckMX(t *testing.T) {
+	// Arrange
+	validator := utils.NewEmailValidator(true)
+	validator.SetMXLookup(func(ctx context.Context, domain string) ([]*net.MX, error) {
+		switch domain {
+		case "example.com":
+			return []*net.MX{{Host: "mx.example.com.", Pref: 10}}, nil
+		case "nomx.example":
+			return nil, nil
+		default:
+			return nil, errors.New("no such host")
+		}
+	})
+
+	// Act & Assert
+	normalized, err := validator.Normalize(context.Background(), "Jane@Example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "jane@example.com", normalized)
+
+	_, err = validator.Normalize(context.Background(), "jane@nomx.example")
+	assert.ErrorIs(t, err, utils.ErrInvalidEmail)
+
+	_, err = validator.Normalize(context.Background(), "jane@unknown.example")
+	assert.ErrorIs(t, err, utils.ErrInvalidEmail)
+
+	// MX checks are skipped unless enabled
+	_, err = utils.NewEmailValidator(false).Normalize(context.Background(), "jane@unknown.example")
+	assert.NoError(t, err)
+}
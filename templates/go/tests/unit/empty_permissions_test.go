@@ -0,0 +1,125 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/models"
+	"app/internal/utils"
+)
+
+func TestAggregatePermissions_NeverNil(t *testing.T) {
+	tests := []struct {
+		name     string
+		roles    []models.Role
+		expected []string
+	}{
+		{name: "roleless user", roles: nil, expected: []string{}},
+		{name: "empty-permission role", roles: []models.Role{{Name: "empty"}}, expected: []string{}},
+		{
+			name:     "overlapping roles",
+			roles:    []models.Role{{Permissions: models.Permissions{"user:read", "role:read"}}, {Permissions: models.Permissions{"user:read"}}},
+			expected: []string{"role:read", "user:read"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			permissions := models.AggregatePermissions(tt.roles)
+
+			// Assert
+			require.NotNil(t, permissions)
+			assert.Equal(t, tt.expected, permissions)
+		})
+	}
+}
+
+func TestAggregatePermissions_StableOrder(t *testing.T) {
+	// Arrange
+	admin := models.Role{Permissions: models.Permissions{"user:write", "audit:read", "user:read"}}
+	editor := models.Role{Permissions: models.Permissions{"role:read", "user:write"}}
+	expected := []string{"audit:read", "role:read", "user:read", "user:write"}
+
+	// Act & Assert - map iteration order must not leak into the result
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, expected, models.AggregatePermissions([]models.Role{admin, editor}))
+		assert.Equal(t, expected, models.AggregatePermissions([]models.Role{editor, admin}))
+	}
+}
+
+func TestPermissions_ScanNullIsEmpty(t *testing.T) {
+	for _, value := range []interface{}{nil, []byte("null")} {
+		// Arrange
+		permissions := models.Permissions{"stale"}
+
+		// Act
+		err := permissions.Scan(value)
+
+		// Assert
+		require.NoError(t, err)
+		assert.NotNil(t, permissions)
+		assert.Empty(t, permissions)
+	}
+}
+
+func TestRole_ToResponseEmptyPermissions(t *testing.T) {
+	// Arrange
+	role := &models.Role{Name: "empty"}
+
+	// Act
+	body, err := json.Marshal(role.ToResponse())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"permissions":[]`)
+}
+
+func TestAuthMiddleware_RolelessUser(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"))
+
+	token, err := jwtService.GenerateToken(&models.User{ID: uuid.New(), Email: "roleless@example.com"})
+	require.NoError(t, err)
+
+	var currentUser *middleware.CurrentUser
+	router := gin.New()
+	router.GET("/me", authMiddleware.RequireAuth(), func(c *gin.Context) {
+		user, err := middleware.GetCurrentUser(c)
+		require.NoError(t, err)
+		currentUser = user
+		c.Status(http.StatusOK)
+	})
+	router.GET("/users", authMiddleware.RequireAuth(), authMiddleware.RequirePermission(models.PermissionUserRead), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// Act & Assert - the user authenticates with empty, non-nil lists
+	require.Equal(t, http.StatusOK, serve("/me").Code)
+	require.NotNil(t, currentUser)
+	assert.NotNil(t, currentUser.Roles)
+	assert.NotNil(t, currentUser.Permissions)
+	assert.Empty(t, currentUser.Permissions)
+
+	// Act & Assert - and is denied rather than crashing a permission check
+	assert.Equal(t, http.StatusForbidden, serve("/users").Code)
+}
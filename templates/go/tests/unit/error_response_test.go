@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func setupErrorPathRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.HandleMethodNotAllowed = true
+
+	logger := utils.NewLogger("error", "test")
+	securityMiddleware := middleware.NewSecurityMiddleware(&config.Config{Environment: "production"}, logger)
+	authMiddleware := middleware.NewAuthMiddleware(auth.NewJWTService("test-secret-key", "test-issuer", 1), logger)
+
+	router.Use(securityMiddleware.RequestID())
+	router.Use(securityMiddleware.Recovery())
+
+	ok := func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"ok": true})
+	}
+	router.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	router.GET("/protected", authMiddleware.RequireAuth(), ok)
+	router.GET("/items/:id", middleware.ValidateUUIDParams("id"), ok)
+	router.GET("/keyed", securityMiddleware.APIKeyAuth([]string{"valid-api-key"}), ok)
+	router.GET("/internal", securityMiddleware.IPWhitelist([]string{"10.0.0.1"}), ok)
+	router.POST("/upload", securityMiddleware.RequestSizeLimit(8), ok)
+	router.POST("/typed", securityMiddleware.ContentTypeValidation(), ok)
+	router.NoRoute(middleware.NotFound())
+	router.NoMethod(middleware.MethodNotAllowed())
+
+	return router
+}
+
+func TestErrorPaths_ReturnJSONEnvelope(t *testing.T) {
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		body        string
+		contentType string
+		wantStatus  int
+		wantCode    string
+	}{
+		{name: "unknown route", method: http.MethodGet, path: "/missing", wantStatus: http.StatusNotFound, wantCode: "ROUTE_NOT_FOUND"},
+		{name: "wrong method", method: http.MethodDelete, path: "/protected", wantStatus: http.StatusMethodNotAllowed, wantCode: "METHOD_NOT_ALLOWED"},
+		{name: "panic", method: http.MethodGet, path: "/panic", wantStatus: http.StatusInternalServerError, wantCode: "INTERNAL_ERROR"},
+		{name: "missing token", method: http.MethodGet, path: "/protected", wantStatus: http.StatusUnauthorized, wantCode: "MISSING_AUTH_HEADER"},
+		{name: "invalid path parameter", method: http.MethodGet, path: "/items/not-a-uuid", wantStatus: http.StatusBadRequest, wantCode: "INVALID_PATH_PARAMETER"},
+		{name: "missing api key", method: http.MethodGet, path: "/keyed", wantStatus: http.StatusUnauthorized, wantCode: "MISSING_API_KEY"},
+		{name: "ip not allowed", method: http.MethodGet, path: "/internal", wantStatus: http.StatusForbidden, wantCode: "IP_NOT_ALLOWED"},
+		{name: "body too large", method: http.MethodPost, path: "/upload", body: `{"data":"too large"}`, contentType: "application/json", wantStatus: http.StatusRequestEntityTooLarge, wantCode: "REQUEST_TOO_LARGE"},
+		{name: "unsupported content type", method: http.MethodPost, path: "/typed", body: "plain", contentType: "text/plain", wantStatus: http.StatusUnsupportedMediaType, wantCode: "INVALID_CONTENT_TYPE"},
+	}
+
+	router := setupErrorPathRouter()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.True(t, strings.HasPrefix(w.Header().Get("Content-Type"), "application/json"),
+				"unexpected Content-Type %q", w.Header().Get("Content-Type"))
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.wantCode, body["code"])
+			assert.NotEmpty(t, body["error"])
+			assert.NotEmpty(t, body["request_id"])
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func setupFeatureRouter(cfg *config.Config, permissions []string, observed *bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	featureMiddleware := middleware.NewFeatureMiddleware(cfg, utils.NewLogger("error", "test"))
+	router.GET("/feature", func(c *gin.Context) {
+		if permissions != nil {
+			c.Set("user_permissions", permissions)
+		}
+		c.Next()
+	}, featureMiddleware.FeatureOverrides(), func(c *gin.Context) {
+		*observed = cfg.FeatureFlags.IsEnabled(c.Request.Context(), "beta")
+		c.Status(http.StatusOK)
+	})
+
+	return router
+}
+
+func TestFeatureFlags_IsEnabled(t *testing.T) {
+	flags := config.NewFeatureFlags(map[string]bool{"Audit_Logging": true, "beta": false})
+
+	assert.True(t, flags.IsEnabled(context.Background(), "audit_logging"))
+	assert.False(t, flags.IsEnabled(context.Background(), "beta"))
+	assert.False(t, flags.IsEnabled(context.Background(), "unknown"))
+}
+
+func TestFeatureOverrides(t *testing.T) {
+	tests := []struct {
+		name        string
+		token       string
+		permissions []string
+		expected    bool
+	}{
+		{name: "valid override token", token: "override-secret", expected: true},
+		{name: "invalid override token", token: "wrong-secret", expected: false},
+		{name: "no token or permission", expected: false},
+		{name: "system update permission", permissions: []string{"system:update"}, expected: true},
+		{name: "insufficient permission", permissions: []string{"user:read"}, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := &config.Config{
+				FeatureFlags:         config.NewFeatureFlags(map[string]bool{"beta": false}),
+				FeatureOverrideToken: "override-secret",
+			}
+			var observed bool
+			router := setupFeatureRouter(cfg, tt.permissions, &observed)
+
+			req := httptest.NewRequest("GET", "/feature", nil)
+			req.Header.Set(middleware.FeatureOverrideHeader, "beta=true")
+			if tt.token != "" {
+				req.Header.Set(middleware.FeatureOverrideTokenHeader, tt.token)
+			}
+
+			// Act
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			// Assert
+			assert.Equal(t, tt.expected, observed)
+			assert.False(t, cfg.FeatureFlags.IsEnabled(context.Background(), "beta"), "override must not leak outside the request")
+		})
+	}
+}
+
+func TestFeatureOverrides_ScopedToRequest(t *testing.T) {
+	// Arrange
+	cfg := &config.Config{
+		FeatureFlags:         config.NewFeatureFlags(map[string]bool{"beta": false}),
+		FeatureOverrideToken: "override-secret",
+	}
+	var observed bool
+	router := setupFeatureRouter(cfg, nil, &observed)
+
+	overridden := httptest.NewRequest("GET", "/feature", nil)
+	overridden.Header.Set(middleware.FeatureOverrideHeader, "beta=true")
+	overridden.Header.Set(middleware.FeatureOverrideTokenHeader, "override-secret")
+
+	// Act & Assert
+	router.ServeHTTP(httptest.NewRecorder(), overridden)
+	assert.True(t, observed)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/feature", nil))
+	assert.False(t, observed)
+}
+
+func TestRequireFeature(t *testing.T) {
+	tests := []struct {
+		name           string
+		enabled        bool
+		override       string
+		expectedStatus int
+	}{
+		{name: "enabled feature is accessible", enabled: true, expectedStatus: http.StatusOK},
+		{name: "disabled feature is blocked", enabled: false, expectedStatus: http.StatusForbidden},
+		{name: "override enables feature for the request", enabled: false, override: "beta=true", expectedStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			cfg := &config.Config{
+				FeatureFlags:         config.NewFeatureFlags(map[string]bool{"beta": tt.enabled}),
+				FeatureOverrideToken: "override-secret",
+			}
+			featureMiddleware := middleware.NewFeatureMiddleware(cfg, utils.NewLogger("error", "test"))
+			handlerCalled := false
+
+			router := gin.New()
+			router.Use(featureMiddleware.FeatureOverrides())
+			router.GET("/beta", featureMiddleware.RequireFeature("beta"), func(c *gin.Context) {
+				handlerCalled = true
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/beta", nil)
+			if tt.override != "" {
+				req.Header.Set(middleware.FeatureOverrideHeader, tt.override)
+				req.Header.Set(middleware.FeatureOverrideTokenHeader, "override-secret")
+			}
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedStatus == http.StatusOK, handlerCalled)
+			if tt.expectedStatus == http.StatusForbidden {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "FEATURE_DISABLED", body["code"])
+				assert.Equal(t, "beta", body["feature"])
+			}
+		})
+	}
+}
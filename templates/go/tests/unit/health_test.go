@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/utils"
+)
+
+func setupHealthRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	// No database simulates it being down; no Redis client means Redis is disabled
+	healthHandler := handlers.NewHealthHandler(nil, nil, utils.NewLogger("error", "test"))
+	router.GET("/health/", healthHandler.Health)
+	router.GET("/health/readiness", healthHandler.Readiness)
+	router.GET("/health/liveness", healthHandler.Liveness)
+
+	return router
+}
+
+func TestHealth_LivenessIgnoresDependencies(t *testing.T) {
+	// Arrange
+	router := setupHealthRouter()
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/liveness", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "alive", response["status"])
+	assert.NotContains(t, response, "services")
+}
+
+func TestHealth_ReadinessFailsWhenDatabaseDown(t *testing.T) {
+	// Arrange
+	router := setupHealthRouter()
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/readiness", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response handlers.HealthStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "not_ready", response.Status)
+	assert.Equal(t, "unhealthy", response.Services["database"].Status)
+	assert.NotEmpty(t, response.Services["database"].Error)
+	assert.Equal(t, "disabled", response.Services["redis"].Status)
+}
+
+func TestHealth_HealthReportsUnhealthyWhenDatabaseDown(t *testing.T) {
+	// Arrange
+	router := setupHealthRouter()
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response handlers.HealthStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "unhealthy", response.Status)
+	assert.Equal(t, "unhealthy", response.Services["database"].Status)
+}
+
+// newUnresponsiveRedis returns a Redis client whose server accepts
+// connections but never replies
+func newUnresponsiveRedis(t *testing.T) *redis.Client {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go io.Copy(io.Discard, conn)
+		}
+	}()
+
+	client := redis.NewClient(&redis.Options{
+		Addr:        listener.Addr().String(),
+		MaxRetries:  -1,
+		ReadTimeout: time.Minute,
+	})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestHealth_SlowDependencyTimesOut(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+	}{
+		{name: "short timeout", timeout: 100 * time.Millisecond},
+		{name: "longer timeout", timeout: 400 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			healthHandler := handlers.NewHealthHandler(nil, newUnresponsiveRedis(t), utils.NewLogger("error", "test"),
+				handlers.WithHealthCheckTimeout(tt.timeout),
+			)
+			router := gin.New()
+			router.GET("/health/", healthHandler.Health)
+
+			// Act
+			start := time.Now()
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/", nil))
+			elapsed := time.Since(start)
+
+			// Assert
+			assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+			assert.GreaterOrEqual(t, elapsed, tt.timeout)
+			assert.Less(t, elapsed, tt.timeout+500*time.Millisecond, "the check must give up at the configured timeout")
+
+			var response handlers.HealthStatus
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, "unhealthy", response.Services["redis"].Status)
+			assert.NotEmpty(t, response.Services["redis"].Error)
+		})
+	}
+}
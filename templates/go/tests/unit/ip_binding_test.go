@@ -0,0 +1,134 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/models"
+	"app/internal/utils"
+)
+
+func TestJWTService_CheckIPBinding(t *testing.T) {
+	tests := []struct {
+		name       string
+		ipv4Prefix int
+		ipv6Prefix int
+		issuedTo   string
+		usedFrom   string
+		expectErr  bool
+	}{
+		{name: "same IPv4 address", ipv4Prefix: 32, ipv6Prefix: 128, issuedTo: "203.0.113.7", usedFrom: "203.0.113.7", expectErr: false},
+		{name: "other IPv4 address", ipv4Prefix: 32, ipv6Prefix: 128, issuedTo: "203.0.113.7", usedFrom: "203.0.113.8", expectErr: true},
+		{name: "same IPv4 subnet within tolerance", ipv4Prefix: 24, ipv6Prefix: 64, issuedTo: "203.0.113.7", usedFrom: "203.0.113.200", expectErr: false},
+		{name: "other IPv4 subnet", ipv4Prefix: 24, ipv6Prefix: 64, issuedTo: "203.0.113.7", usedFrom: "198.51.100.7", expectErr: true},
+		{name: "same IPv6 prefix within tolerance", ipv4Prefix: 24, ipv6Prefix: 64, issuedTo: "2001:db8:1:2::1", usedFrom: "2001:db8:1:2::ffff", expectErr: false},
+		{name: "other IPv6 prefix", ipv4Prefix: 24, ipv6Prefix: 64, issuedTo: "2001:db8:1:2::1", usedFrom: "2001:db8:1:3::1", expectErr: true},
+		{name: "address family change", ipv4Prefix: 0, ipv6Prefix: 0, issuedTo: "203.0.113.7", usedFrom: "2001:db8::1", expectErr: true},
+		{name: "unparseable client address", ipv4Prefix: 24, ipv6Prefix: 64, issuedTo: "203.0.113.7", usedFrom: "unknown", expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			jwtService := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithIPBinding(tt.ipv4Prefix, tt.ipv6Prefix))
+			token, err := jwtService.GenerateTokenForClient(&models.User{ID: uuid.New()}, "", "", tt.issuedTo)
+			require.NoError(t, err)
+			claims, err := jwtService.ValidateToken(token)
+			require.NoError(t, err)
+			require.Equal(t, tt.issuedTo, claims.IP)
+
+			// Act
+			err = jwtService.CheckIPBinding(claims, tt.usedFrom)
+
+			// Assert
+			if tt.expectErr {
+				assert.ErrorIs(t, err, auth.ErrTokenIPMismatch)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestJWTService_IPBindingDisabled(t *testing.T) {
+	// Arrange
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1)
+	token, err := jwtService.GenerateTokenForClient(&models.User{ID: uuid.New()}, "", "", "203.0.113.7")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := jwtService.ValidateToken(token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, claims.IP, "the client IP is only recorded when binding is enabled")
+	assert.NoError(t, jwtService.CheckIPBinding(claims, "198.51.100.7"))
+}
+
+func TestJWTService_IPBindingRejectsUnboundTokens(t *testing.T) {
+	// Arrange - a token issued without a client IP, e.g. before binding was enabled
+	unbound := auth.NewJWTService("test-secret", "test-issuer", 1)
+	token, err := unbound.GenerateToken(&models.User{ID: uuid.New()})
+	require.NoError(t, err)
+
+	jwtService := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithIPBinding(24, 64))
+	claims, err := jwtService.ValidateToken(token)
+	require.NoError(t, err)
+	require.Empty(t, claims.IP)
+
+	// Act
+	err = jwtService.CheckIPBinding(claims, "203.0.113.7")
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrTokenIPMismatch)
+}
+
+func TestAuthMiddleware_IPBinding(t *testing.T) {
+	tests := []struct {
+		name           string
+		remoteAddr     string
+		expectedStatus int
+	}{
+		{name: "same IP passes", remoteAddr: "203.0.113.7:5000", expectedStatus: http.StatusOK},
+		{name: "different network is rejected", remoteAddr: "198.51.100.7:5000", expectedStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			jwtService := auth.NewJWTService("test-secret", "test-issuer", 1, auth.WithIPBinding(32, 128))
+			authMiddleware := middleware.NewAuthMiddleware(jwtService, utils.NewLogger("error", "test"))
+
+			token, err := jwtService.GenerateTokenForClient(&models.User{ID: uuid.New()}, "", "", "203.0.113.7")
+			require.NoError(t, err)
+
+			router := gin.New()
+			router.GET("/protected", authMiddleware.RequireAuth(), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("Authorization", "Bearer "+token)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusUnauthorized {
+				assert.Contains(t, w.Body.String(), "TOKEN_IP_MISMATCH")
+			}
+		})
+	}
+}
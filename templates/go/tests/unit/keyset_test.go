@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/auth"
+	"app/internal/models"
+)
+
+func TestKeySet_SignsWithNewestKey(t *testing.T) {
+	// Arrange
+	oldPEM, _ := generateRSAKeyPair(t)
+	newPEM, _ := generateRSAKeyPair(t)
+	keySet := auth.NewKeySet()
+	require.NoError(t, keySet.Add("2024-01", oldPEM))
+	jwtService := auth.NewJWTServiceWithKeySet(keySet, "test-issuer", 1)
+	user := &models.User{ID: uuid.New()}
+
+	oldToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	// Act
+	require.NoError(t, keySet.Add("2024-02", newPEM))
+	newToken, err := jwtService.GenerateToken(user)
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, "2024-01", tokenKeyID(t, oldToken))
+	assert.Equal(t, "2024-02", tokenKeyID(t, newToken))
+
+	// Tokens signed before the rotation still validate
+	_, err = jwtService.ValidateToken(oldToken)
+	assert.NoError(t, err)
+	_, err = jwtService.ValidateToken(newToken)
+	assert.NoError(t, err)
+}
+
+func TestKeySet_RejectsUnknownKeyID(t *testing.T) {
+	// Arrange
+	oldPEM, _ := generateRSAKeyPair(t)
+	newPEM, _ := generateRSAKeyPair(t)
+	keySet := auth.NewKeySet()
+	require.NoError(t, keySet.Add("old", oldPEM))
+	jwtService := auth.NewJWTServiceWithKeySet(keySet, "test-issuer", 1)
+
+	token, err := jwtService.GenerateToken(&models.User{ID: uuid.New()})
+	require.NoError(t, err)
+	require.NoError(t, keySet.Add("new", newPEM))
+
+	// Act
+	keySet.Remove("old")
+	_, err = jwtService.ValidateToken(token)
+
+	// Assert
+	assert.ErrorIs(t, err, auth.ErrUnknownKeyID)
+}
+
+func TestKeySet_MissingKeyIDUsesNewestKey(t *testing.T) {
+	// Arrange
+	oldPEM, _ := generateRSAKeyPair(t)
+	newPEM, _ := generateRSAKeyPair(t)
+	keySet := auth.NewKeySet()
+	require.NoError(t, keySet.Add("old", oldPEM))
+	require.NoError(t, keySet.Add("new", newPEM))
+	jwtService := auth.NewJWTServiceWithKeySet(keySet, "test-issuer", 1)
+
+	claims := auth.Claims{
+		UserID: uuid.New(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	signWithoutKeyID := func(privatePEM []byte) string {
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(privatePEM)
+		require.NoError(t, err)
+		token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+		require.NoError(t, err)
+		return token
+	}
+
+	// Act
+	_, newestErr := jwtService.ValidateToken(signWithoutKeyID(newPEM))
+	_, olderErr := jwtService.ValidateToken(signWithoutKeyID(oldPEM))
+
+	// Assert
+	assert.NoError(t, newestErr)
+	assert.Error(t, olderErr)
+}
+
+func TestKeySet_RejectsDuplicateKeyID(t *testing.T) {
+	// Arrange
+	privatePEM, _ := generateRSAKeyPair(t)
+	keySet := auth.NewKeySet()
+	require.NoError(t, keySet.Add("primary", privatePEM))
+
+	// Act
+	err := keySet.Add("primary", privatePEM)
+
+	// Assert
+	assert.ErrorContains(t, err, "already exists")
+}
+
+func TestJWKSHandler_ServesPublicKeys(t *testing.T) {
+	// Arrange
+	oldPEM, _ := generateRSAKeyPair(t)
+	newPEM, _ := generateRSAKeyPair(t)
+	keySet := auth.NewKeySet()
+	require.NoError(t, keySet.Add("old", oldPEM))
+	require.NoError(t, keySet.Add("new", newPEM))
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/.well-known/jwks.json", handlers.JWKSHandler(keySet))
+
+	// Act
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/.well-known/jwks.json", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var jwks auth.JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+	assert.Equal(t, "old", jwks.Keys[1].Kid)
+
+	privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(newPEM)
+	require.NoError(t, err)
+
+	key := jwks.Keys[0]
+	assert.Equal(t, "RSA", key.Kty)
+	assert.Equal(t, "RS256", key.Alg)
+	assert.Equal(t, "sig", key.Use)
+
+	n, err := base64.RawURLEncoding.DecodeString(key.N)
+	require.NoError(t, err)
+	e, err := base64.RawURLEncoding.DecodeString(key.E)
+	require.NoError(t, err)
+	assert.Equal(t, 0, privateKey.N.Cmp(new(big.Int).SetBytes(n)))
+	assert.Equal(t, int64(privateKey.E), new(big.Int).SetBytes(e).Int64())
+}
+
+// tokenKeyID returns the kid header of a token without verifying it
+func tokenKeyID(t *testing.T, tokenString string) string {
+	t.Helper()
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &auth.Claims{})
+	require.NoError(t, err)
+	kid, _ := token.Header["kid"].(string)
+	return kid
+}
@@ -0,0 +1,46 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeLoginLookup counts user lookups made by login
+type fakeLoginLookup struct {
+	interfaces.UserRepository
+	lookups int
+}
+
+func (r *fakeLoginLookup) GetByEmailOrUsername(ctx context.Context, login string) (*models.User, error) {
+	r.lookups++
+	return nil, services.ErrUserNotFound
+}
+
+func TestAuthService_Login_RejectsLongIdentifier(t *testing.T) {
+	// Arrange
+	repo := &fakeLoginLookup{}
+	cfg := &config.Config{LoginIdentifierMaxLength: 254}
+	authService := services.NewAuthService(repo, nil, nil, nil, nil, nil, cfg, utils.NewLogger("error", "test"), nil)
+
+	req := &models.LoginRequest{
+		Login:    strings.Repeat("a", 255),
+		Password: "password123",
+	}
+
+	// Act
+	response, err := authService.Login(context.Background(), req, "127.0.0.1", "test-agent")
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrLoginIdentifierTooLong)
+	assert.Nil(t, response)
+	assert.Zero(t, repo.lookups, "an oversized identifier must not reach the database")
+}
@@ -0,0 +1,121 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func TestSessionService_NilRedis(t *testing.T) {
+	// Arrange
+	ctx := context.Background()
+	sessionService := auth.NewSessionService(nil, time.Hour, auth.WithSessionLimitPolicy(auth.SessionLimitPolicy{Default: 1}))
+	userID := uuid.New()
+
+	// Act & Assert - writes and reads report the store is disabled
+	sessionID, err := sessionService.CreateSession(ctx, &auth.SessionData{UserID: userID})
+	assert.ErrorIs(t, err, auth.ErrSessionsDisabled)
+	assert.Empty(t, sessionID)
+
+	_, err = sessionService.GetSession(ctx, "any")
+	assert.ErrorIs(t, err, auth.ErrSessionsDisabled)
+	assert.ErrorIs(t, sessionService.UpdateSession(ctx, "any", &auth.SessionData{}), auth.ErrSessionsDisabled)
+	assert.ErrorIs(t, sessionService.RefreshSession(ctx, "any"), auth.ErrSessionsDisabled)
+
+	// Lookups find nothing and deletes have nothing to do
+	valid, err := sessionService.IsSessionValid(ctx, "any")
+	require.NoError(t, err)
+	assert.False(t, valid)
+
+	count, err := sessionService.GetActiveSessionCount(ctx, userID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	sessions, err := sessionService.GetUserSessions(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	assert.NoError(t, sessionService.DeleteSession(ctx, "any"))
+	assert.NoError(t, sessionService.DeleteUserSessions(ctx, userID))
+}
+
+func TestRateLimiter_NilRedis(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	rateLimiter := middleware.NewRateLimiter(nil, &config.Config{}, utils.NewLogger("error", "test"))
+
+	tests := []struct {
+		name  string
+		limit gin.HandlerFunc
+	}{
+		{
+			name: "fixed window",
+			limit: rateLimiter.RateLimit(middleware.RateLimitConfig{
+				Requests: 1,
+				Window:   time.Minute,
+				KeyFunc:  middleware.IPKeyFunc("nil"),
+			}),
+		},
+		{
+			name: "sliding window",
+			limit: rateLimiter.RateLimit(middleware.RateLimitConfig{
+				Requests: 1,
+				Window:   time.Minute,
+				KeyFunc:  middleware.IPKeyFunc("nil"),
+				Sliding:  true,
+			}),
+		},
+		{name: "progressive", limit: rateLimiter.ProgressiveRateLimit()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(tt.limit)
+			router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			// Act & Assert - well past every limit, nothing is rejected
+			for i := 0; i < 20; i++ {
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+				require.Equal(t, http.StatusOK, w.Code)
+			}
+		})
+	}
+
+	allowed, remaining, _, err := rateLimiter.SlidingWindowRateLimit("key", 5, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	assert.Equal(t, 5, remaining)
+
+	remaining, _, err = rateLimiter.GetRateLimitStatus("key", 5, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 5, remaining)
+}
+
+func TestRedisTokenBlacklist_NilRedis(t *testing.T) {
+	// Arrange
+	blacklist := auth.NewRedisTokenBlacklist(nil, "")
+
+	// Act & Assert
+	blacklisted, err := blacklist.IsTokenBlacklisted("token-id")
+	require.NoError(t, err, "lookups must not fail, or every token would be rejected")
+	assert.False(t, blacklisted)
+
+	err = blacklist.BlacklistToken("token-id", time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, auth.ErrBlacklistUnavailable)
+
+	assert.NoError(t, blacklist.BlacklistToken("token-id", time.Now().Add(-time.Hour)), "expired tokens need no revocation")
+}
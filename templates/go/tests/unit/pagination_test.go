@@ -0,0 +1,236 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeRoleRepository records the filter and paging arguments passed to List
+type fakeRoleRepository struct {
+	interfaces.RoleRepository
+	filters interfaces.RoleFilters
+	offset  int
+	limit   int
+	total   int64
+}
+
+func (r *fakeRoleRepository) List(ctx context.Context, filters interfaces.RoleFilters, offset, limit int) ([]*models.Role, int64, error) {
+	r.filters, r.offset, r.limit = filters, offset, limit
+	return []*models.Role{}, r.total, nil
+}
+
+func (r *fakeRoleRepository) CountUsers(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	return map[uuid.UUID]int64{}, nil
+}
+
+func setupRoleRouter(repo *fakeRoleRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{PaginationDefaultSize: 25, PaginationMaxSize: 50}
+	logger := utils.NewLogger("error", "test")
+	roleHandler := handlers.NewRoleHandler(services.NewRoleService(repo, &fakeAuditLogRepository{}, logger), cfg, logger)
+	router.GET("/roles", roleHandler.ListRoles)
+
+	return router
+}
+
+func TestListRoles_Pagination(t *testing.T) {
+	tests := []struct {
+		name             string
+		query            string
+		expectedStatus   int
+		expectedPageSize int
+		expectedOffset   int
+	}{
+		{name: "default size applied", query: "", expectedStatus: http.StatusOK, expectedPageSize: 25, expectedOffset: 0},
+		{name: "size at max", query: "?page_size=50&page=2", expectedStatus: http.StatusOK, expectedPageSize: 50, expectedOffset: 50},
+		{name: "over max clamped", query: "?page_size=500", expectedStatus: http.StatusOK, expectedPageSize: 50, expectedOffset: 0},
+		{name: "invalid size rejected", query: "?page_size=abc", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &fakeRoleRepository{}
+			router := setupRoleRouter(repo)
+
+			req := httptest.NewRequest(http.MethodGet, "/roles"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var body models.Paginated[models.RoleResponse]
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedPageSize, body.PageSize)
+			assert.Equal(t, tt.expectedPageSize, repo.limit)
+			assert.Equal(t, tt.expectedOffset, repo.offset)
+		})
+	}
+}
+
+func TestListRoles_IsActiveFilter(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedStatus int
+		expected       *bool
+	}{
+		{name: "absent", query: "", expectedStatus: http.StatusOK, expected: nil},
+		{name: "yes", query: "?is_active=yes", expectedStatus: http.StatusOK, expected: boolPtr(true)},
+		{name: "zero", query: "?is_active=0", expectedStatus: http.StatusOK, expected: boolPtr(false)},
+		{name: "invalid", query: "?is_active=maybe", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := &fakeRoleRepository{}
+			router := setupRoleRouter(repo)
+
+			req := httptest.NewRequest(http.MethodGet, "/roles"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusOK {
+				assert.Equal(t, tt.expected, repo.filters.IsActive)
+			}
+		})
+	}
+}
+
+func boolPtr(value bool) *bool {
+	return &value
+}
+
+func TestPaginated_SetLinks(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     int
+		expected models.Links
+	}{
+		{
+			name: "first page",
+			page: 1,
+			expected: models.Links{
+				Self:  "/roles?name=adm&page=1&page_size=10",
+				Next:  "/roles?name=adm&page=2&page_size=10",
+				First: "/roles?name=adm&page=1&page_size=10",
+				Last:  "/roles?name=adm&page=3&page_size=10",
+			},
+		},
+		{
+			name: "middle page",
+			page: 2,
+			expected: models.Links{
+				Self:  "/roles?name=adm&page=2&page_size=10",
+				Next:  "/roles?name=adm&page=3&page_size=10",
+				Prev:  "/roles?name=adm&page=1&page_size=10",
+				First: "/roles?name=adm&page=1&page_size=10",
+				Last:  "/roles?name=adm&page=3&page_size=10",
+			},
+		},
+		{
+			name: "last page",
+			page: 3,
+			expected: models.Links{
+				Self:  "/roles?name=adm&page=3&page_size=10",
+				Prev:  "/roles?name=adm&page=2&page_size=10",
+				First: "/roles?name=adm&page=1&page_size=10",
+				Last:  "/roles?name=adm&page=3&page_size=10",
+			},
+		},
+		{
+			name: "past the last page",
+			page: 7,
+			expected: models.Links{
+				Self:  "/roles?name=adm&page=7&page_size=10",
+				Prev:  "/roles?name=adm&page=3&page_size=10",
+				First: "/roles?name=adm&page=1&page_size=10",
+				Last:  "/roles?name=adm&page=3&page_size=10",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			requestURL, err := url.Parse("/roles?name=adm&page=99")
+			require.NoError(t, err)
+			page := models.NewPaginated([]models.RoleResponse{}, 25, tt.page, 10)
+
+			// Act
+			page.SetLinks(requestURL)
+
+			// Assert
+			require.NotNil(t, page.Links)
+			assert.Equal(t, tt.expected, *page.Links)
+		})
+	}
+}
+
+func TestPaginated_SetLinksEmptyResult(t *testing.T) {
+	// Arrange
+	requestURL, err := url.Parse("/roles")
+	require.NoError(t, err)
+	page := models.NewPaginated([]models.RoleResponse{}, 0, 1, 10)
+
+	// Act
+	page.SetLinks(requestURL)
+
+	// Assert - a single empty page links to itself
+	assert.Equal(t, models.Links{
+		Self:  "/roles?page=1&page_size=10",
+		First: "/roles?page=1&page_size=10",
+		Last:  "/roles?page=1&page_size=10",
+	}, *page.Links)
+}
+
+func TestListRoles_IncludesLinks(t *testing.T) {
+	// Arrange
+	repo := &fakeRoleRepository{total: 60}
+	router := setupRoleRouter(repo)
+
+	req := httptest.NewRequest(http.MethodGet, "/roles?page=2&is_active=true", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var body models.Paginated[models.RoleResponse]
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	require.NotNil(t, body.Links)
+	assert.Equal(t, "/roles?is_active=true&page=3&page_size=25", body.Links.Next)
+	assert.Equal(t, "/roles?is_active=true&page=1&page_size=25", body.Links.Prev)
+	assert.Equal(t, "/roles?is_active=true&page=3&page_size=25", body.Links.Last)
+}
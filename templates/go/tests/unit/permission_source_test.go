@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestResolvePermissionSource(t *testing.T) {
+	user := &models.User{
+		Roles: []models.Role{
+			{Name: "superuser", Permissions: models.Permissions{"*"}},
+			{Name: "user_manager", Permissions: models.Permissions{"user:*"}},
+			{Name: "editor", Permissions: models.Permissions{"post:write", "user:read"}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		permission   string
+		expectedRole string
+		expectedType string
+		expectedFrom string
+	}{
+		{name: "direct grant wins", permission: "user:read", expectedRole: "editor", expectedType: models.PermissionSourceDirect, expectedFrom: "user:read"},
+		{name: "inherited from prefix", permission: "user:delete", expectedRole: "user_manager", expectedType: models.PermissionSourceInherited, expectedFrom: "user:*"},
+		{name: "wildcard fallback", permission: "system:update", expectedRole: "superuser", expectedType: models.PermissionSourceWildcard, expectedFrom: "*"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			source := models.ResolvePermissionSource(user, tt.permission)
+
+			// Assert
+			assert.True(t, source.Granted)
+			assert.Equal(t, tt.permission, source.Permission)
+			assert.Equal(t, tt.expectedRole, source.Role)
+			assert.Equal(t, tt.expectedType, source.Type)
+			assert.Equal(t, tt.expectedFrom, source.Grant)
+		})
+	}
+}
+
+func TestResolvePermissionSource_NotGranted(t *testing.T) {
+	// Arrange
+	user := &models.User{
+		Roles: []models.Role{{Name: "editor", Permissions: models.Permissions{"post:write"}}},
+	}
+
+	// Act
+	source := models.ResolvePermissionSource(user, "user:delete")
+
+	// Assert
+	assert.False(t, source.Granted)
+	assert.Empty(t, source.Role)
+	assert.Empty(t, source.Type)
+}
+
+// fakeUserLookup serves GetByID from an in-memory map
+type fakeUserLookup struct {
+	interfaces.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserLookup) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
+func TestGetPermissionSource_Endpoint(t *testing.T) {
+	userID := uuid.New()
+	repo := &fakeUserLookup{users: map[uuid.UUID]*models.User{
+		userID: {ID: userID, Roles: []models.Role{{Name: "editor", Permissions: models.Permissions{"post:*"}}}},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, logger), logger)
+	router := gin.New()
+	router.GET("/users/:id/permission-source", userHandler.GetPermissionSource)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "resolved", path: "/users/" + userID.String() + "/permission-source?permission=post:publish", expectedStatus: http.StatusOK},
+		{name: "missing permission", path: "/users/" + userID.String() + "/permission-source", expectedStatus: http.StatusBadRequest},
+		{name: "unknown user", path: "/users/" + uuid.New().String() + "/permission-source?permission=post:publish", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var source models.PermissionSource
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &source))
+			assert.True(t, source.Granted)
+			assert.Equal(t, "editor", source.Role)
+			assert.Equal(t, models.PermissionSourceInherited, source.Type)
+		})
+	}
+}
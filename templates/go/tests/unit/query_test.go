@@ -0,0 +1,44 @@
+package unit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/utils"
+)
+
+func TestParseQueryBool(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected bool
+		wantErr  bool
+	}{
+		{value: "true", expected: true},
+		{value: "TRUE", expected: true},
+		{value: "1", expected: true},
+		{value: "yes", expected: true},
+		{value: "false", expected: false},
+		{value: "False", expected: false},
+		{value: "0", expected: false},
+		{value: "no", expected: false},
+		{value: "maybe", wantErr: true},
+		{value: "2", wantErr: true},
+		{value: "t", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			// Act
+			result, err := utils.ParseQueryBool(tt.value)
+
+			// Assert
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/utils"
+)
+
+func TestReadAuditor_AuditReads(t *testing.T) {
+	actorID := uuid.New()
+	targetID := uuid.New()
+
+	tests := []struct {
+		name          string
+		method        string
+		path          string
+		expectAudited bool
+	}{
+		{name: "flagged read is audited", method: http.MethodGet, path: "/admin/users/" + targetID.String(), expectAudited: true},
+		{name: "unflagged read is not audited", method: http.MethodGet, path: "/admin/roles", expectAudited: false},
+		{name: "failed flagged read is not audited", method: http.MethodGet, path: "/admin/users/missing", expectAudited: false},
+		{name: "non-GET on flagged route is not audited as a read", method: http.MethodDelete, path: "/admin/users/" + targetID.String(), expectAudited: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			auditRepo := &fakeAuditLogRepository{}
+			auditor := middleware.NewReadAuditor(auditRepo, []string{"/admin/users/:id"}, utils.NewLogger("error", "test"))
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				c.Set("user_id", actorID)
+				c.Next()
+			})
+			router.Use(auditor.AuditReads())
+			router.GET("/admin/users/:id", func(c *gin.Context) {
+				if _, err := uuid.Parse(c.Param("id")); err != nil {
+					c.Status(http.StatusNotFound)
+					return
+				}
+				c.Status(http.StatusOK)
+			})
+			router.DELETE("/admin/users/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+			router.GET("/admin/roles", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			// Act
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			if !tt.expectAudited {
+				assert.Empty(t, auditRepo.logs)
+				return
+			}
+
+			require.Len(t, auditRepo.logs, 1)
+			entry := auditRepo.logs[0]
+			assert.Equal(t, "read", entry.Action)
+			assert.Equal(t, "/admin/users/:id", entry.Resource)
+			require.NotNil(t, entry.UserID)
+			assert.Equal(t, actorID, *entry.UserID)
+			require.NotNil(t, entry.ResourceID)
+			assert.Equal(t, targetID, *entry.ResourceID)
+			assert.True(t, entry.Success)
+		})
+	}
+}
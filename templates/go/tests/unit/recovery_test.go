@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+	"app/internal/config"
+	"app/internal/utils"
+)
+
+func setupRecoveryRouter(environment string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{Environment: environment}
+	securityMiddleware := middleware.NewSecurityMiddleware(cfg, utils.NewLogger("error", "test"))
+
+	router.Use(func(c *gin.Context) {
+		c.Set("request_id", "req-123")
+		c.Next()
+	})
+	router.Use(securityMiddleware.Recovery())
+	router.GET("/panic", func(c *gin.Context) {
+		panic("database password is hunter2")
+	})
+
+	return router
+}
+
+func TestRecovery_ProductionHidesDetails(t *testing.T) {
+	// Arrange
+	router := setupRecoveryRouter("production")
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "INTERNAL_ERROR", body["code"])
+	assert.Equal(t, "req-123", body["request_id"])
+	assert.NotContains(t, body, "details")
+	assert.NotContains(t, body, "stack")
+	assert.NotContains(t, w.Body.String(), "hunter2")
+}
+
+func TestRecovery_DevelopmentShowsDetails(t *testing.T) {
+	// Arrange
+	router := setupRecoveryRouter("development")
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "req-123", body["request_id"])
+	assert.Equal(t, "database password is hunter2", body["details"])
+	assert.NotEmpty(t, body["stack"])
+}
+
+func TestRecovery_CoversLaterMiddleware(t *testing.T) {
+	// Arrange - the global chain order used by routes.Setup
+	gin.SetMode(gin.TestMode)
+	securityMiddleware := middleware.NewSecurityMiddleware(&config.Config{Environment: "production"}, utils.NewLogger("error", "test"))
+
+	router := gin.New()
+	router.Use(securityMiddleware.RequestID())
+	router.Use(securityMiddleware.Recovery())
+	router.Use(func(c *gin.Context) {
+		panic("middleware failure")
+	})
+	router.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-456")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "req-456", w.Header().Get(middleware.RequestIDHeader))
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "INTERNAL_ERROR", body["code"])
+	assert.Equal(t, "req-456", body["request_id"])
+}
@@ -0,0 +1,151 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/api/middleware"
+	"app/internal/auth"
+	"app/internal/config"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func setupRequestIDRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	cfg := &config.Config{PaginationDefaultSize: 20, PaginationMaxSize: 100}
+	logger := utils.NewLogger("error", "test")
+	securityMiddleware := middleware.NewSecurityMiddleware(cfg, logger)
+	authMiddleware := middleware.NewAuthMiddleware(auth.NewJWTService("test-secret", "test-issuer", 1), logger)
+	roleHandler := handlers.NewRoleHandler(services.NewRoleService(&fakeRoleRepository{}, &fakeAuditLogRepository{}, logger), cfg, logger)
+
+	router.Use(securityMiddleware.RequestID())
+	router.GET("/protected", authMiddleware.RequireAuth(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/roles", roleHandler.ListRoles)
+
+	return router
+}
+
+func TestRequestID_GeneratedAndEchoed(t *testing.T) {
+	// Arrange
+	router := setupRequestIDRouter()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, requestID, body["request_id"])
+}
+
+func TestRequestID_InErrorResponses(t *testing.T) {
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "auth error", path: "/protected", expectedStatus: http.StatusUnauthorized, expectedCode: "MISSING_AUTH_HEADER"},
+		{name: "validation error", path: "/roles?page=zero", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_PAGINATION"},
+		{name: "query param error", path: "/roles?is_active=maybe", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_QUERY_PARAM"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := setupRequestIDRouter()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set(middleware.RequestIDHeader, "client-req-42")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, "client-req-42", w.Header().Get(middleware.RequestIDHeader))
+
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedCode, body["code"])
+			assert.Equal(t, "client-req-42", body["request_id"])
+		})
+	}
+}
+
+func TestRequestID_RejectsUnsafeIncomingID(t *testing.T) {
+	// Arrange
+	router := setupRequestIDRouter()
+	req := httptest.NewRequest(http.MethodGet, "/protected", nil)
+	req.Header.Set(middleware.RequestIDHeader, "bad id\r\nwith newline")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	requestID := w.Header().Get(middleware.RequestIDHeader)
+	assert.NotEmpty(t, requestID)
+	assert.NotEqual(t, "bad id\r\nwith newline", requestID)
+}
+
+func TestRequestLogger_IncludesRequestID(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	var buf bytes.Buffer
+	logger := &utils.Logger{Logger: slog.New(slog.NewJSONHandler(&buf, nil))}
+	securityMiddleware := middleware.NewSecurityMiddleware(&config.Config{}, logger)
+
+	var handlerRequestID string
+	router := gin.New()
+	router.Use(securityMiddleware.RequestID())
+	router.Use(middleware.RequestLogger(logger))
+	router.GET("/", func(c *gin.Context) {
+		handlerRequestID = middleware.RequestIDFromContext(c)
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(middleware.RequestIDHeader, "client-req-7")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, "client-req-7", handlerRequestID)
+
+	var entry map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "HTTP request", entry["msg"])
+	assert.Equal(t, "client-req-7", entry["request_id"])
+	assert.Equal(t, float64(http.StatusOK), entry["status_code"])
+}
+
+func TestRequestIDFromContext_WithoutMiddleware(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	// Act & Assert
+	assert.Empty(t, middleware.RequestIDFromContext(c))
+}
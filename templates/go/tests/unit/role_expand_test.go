@@ -0,0 +1,122 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeRoleLister serves a fixed list of roles for List and CountUsers
+type fakeRoleLister struct {
+	interfaces.RoleRepository
+	roles []*models.Role
+}
+
+func (r *fakeRoleLister) List(ctx context.Context, filters interfaces.RoleFilters, offset, limit int) ([]*models.Role, int64, error) {
+	return r.roles, int64(len(r.roles)), nil
+}
+
+func (r *fakeRoleLister) CountUsers(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID]int64, error) {
+	return map[uuid.UUID]int64{}, nil
+}
+
+func TestExpandPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		expected    []string
+	}{
+		{
+			name:        "concrete permissions are kept",
+			permissions: []string{models.PermissionUserUpdate, models.PermissionUserRead},
+			expected:    []string{"user:read", "user:update"},
+		},
+		{
+			name:        "group wildcard expands to its known permissions",
+			permissions: []string{models.PermissionUserAll},
+			expected:    []string{"user:create", "user:delete", "user:read", "user:update"},
+		},
+		{
+			name:        "overlapping grants are deduplicated",
+			permissions: []string{models.PermissionSystemAll, models.PermissionSystemRead},
+			expected:    []string{"system:read", "system:update"},
+		},
+		{
+			name:        "global wildcard expands to every known permission",
+			permissions: []string{models.PermissionAll},
+			expected: []string{
+				"role:assign", "role:create", "role:delete", "role:read", "role:revoke", "role:update",
+				"system:read", "system:update",
+				"user:create", "user:delete", "user:read", "user:update",
+			},
+		},
+		{
+			name:        "unknown group wildcard expands to nothing",
+			permissions: []string{"content:*"},
+			expected:    []string{},
+		},
+		{
+			name:        "unknown concrete permissions are kept",
+			permissions: []string{"content:moderate"},
+			expected:    []string{"content:moderate"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, models.ExpandPermissions(tt.permissions))
+		})
+	}
+}
+
+func TestRoleHandler_ListRoles_Expand(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	roleRepo := &fakeRoleLister{roles: []*models.Role{
+		{ID: uuid.New(), Name: "editor", Permissions: models.Permissions{models.PermissionRoleAll, models.PermissionUserRead}},
+	}}
+	logger := utils.NewLogger("error", "test")
+	roleService := services.NewRoleService(roleRepo, &fakeAuditLogRepository{}, logger)
+	roleHandler := handlers.NewRoleHandler(roleService, &config.Config{PaginationDefaultSize: 20, PaginationMaxSize: 100}, logger)
+
+	router := gin.New()
+	router.GET("/roles", roleHandler.ListRoles)
+
+	list := func(query string) models.RoleResponse {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/roles"+query, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp models.Paginated[models.RoleResponse]
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Items, 1)
+		return resp.Items[0]
+	}
+
+	// Act
+	plain := list("")
+	expanded := list("?expand=true")
+
+	// Assert
+	assert.Equal(t, []string{"role:*", "user:read"}, plain.Permissions)
+	assert.Nil(t, plain.ExpandedPermissions)
+
+	assert.Equal(t, []string{"role:*", "user:read"}, expanded.Permissions, "wildcards stay alongside the expansion")
+	assert.Equal(t, []string{
+		"role:assign", "role:create", "role:delete", "role:read", "role:revoke", "role:update", "user:read",
+	}, expanded.ExpandedPermissions)
+}
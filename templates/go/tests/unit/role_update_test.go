@@ -0,0 +1,180 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/config"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeRoleStore keeps roles in memory for GetByID and Update
+type fakeRoleStore struct {
+	interfaces.RoleRepository
+	roles map[uuid.UUID]*models.Role
+}
+
+func (r *fakeRoleStore) GetByID(ctx context.Context, id uuid.UUID) (*models.Role, error) {
+	role, ok := r.roles[id]
+	if !ok {
+		return nil, fmt.Errorf("role not found")
+	}
+	copied := *role
+	return &copied, nil
+}
+
+func (r *fakeRoleStore) Update(ctx context.Context, role *models.Role) error {
+	r.roles[role.ID] = role
+	return nil
+}
+
+// fakeAuditLogRepository records created audit log entries
+type fakeAuditLogRepository struct {
+	logs []*models.AuditLog
+}
+
+func (r *fakeAuditLogRepository) Create(ctx context.Context, auditLog *models.AuditLog) error {
+	r.logs = append(r.logs, auditLog)
+	return nil
+}
+
+func TestDiffPermissions(t *testing.T) {
+	tests := []struct {
+		name            string
+		before          []string
+		after           []string
+		expectedAdded   []string
+		expectedRemoved []string
+	}{
+		{name: "unchanged", before: []string{"user:read"}, after: []string{"user:read"}, expectedAdded: []string{}, expectedRemoved: []string{}},
+		{name: "added only", before: []string{"user:read"}, after: []string{"user:write", "user:read"}, expectedAdded: []string{"user:write"}, expectedRemoved: []string{}},
+		{name: "removed only", before: []string{"user:read", "user:write"}, after: []string{"user:read"}, expectedAdded: []string{}, expectedRemoved: []string{"user:write"}},
+		{name: "added and removed sorted", before: []string{"b", "a"}, after: []string{"d", "c"}, expectedAdded: []string{"c", "d"}, expectedRemoved: []string{"a", "b"}},
+		{name: "from empty", before: nil, after: []string{"user:read", "user:read"}, expectedAdded: []string{"user:read"}, expectedRemoved: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			diff := models.DiffPermissions(tt.before, tt.after)
+
+			// Assert
+			assert.Equal(t, tt.expectedAdded, diff.Added)
+			assert.Equal(t, tt.expectedRemoved, diff.Removed)
+		})
+	}
+}
+
+func TestRoleService_UpdateRole_ReportsPermissionDiff(t *testing.T) {
+	// Arrange
+	roleID := uuid.New()
+	adminID := uuid.New()
+	roleRepo := &fakeRoleStore{roles: map[uuid.UUID]*models.Role{
+		roleID: {ID: roleID, Name: "editor", Permissions: models.Permissions{"post:read", "post:write"}},
+	}}
+	auditRepo := &fakeAuditLogRepository{}
+	roleService := services.NewRoleService(roleRepo, auditRepo, utils.NewLogger("error", "test"))
+
+	// Act
+	result, err := roleService.UpdateRole(context.Background(), roleID, &models.RoleUpdateRequest{
+		Permissions: []string{"post:read", "post:publish"},
+	}, adminID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"post:publish"}, result.PermissionChanges.Added)
+	assert.Equal(t, []string{"post:write"}, result.PermissionChanges.Removed)
+	assert.ElementsMatch(t, []string{"post:read", "post:publish"}, result.Role.Permissions)
+
+	require.Len(t, auditRepo.logs, 1)
+	auditLog := auditRepo.logs[0]
+	assert.Equal(t, "role.update", auditLog.Action)
+	assert.Equal(t, adminID, *auditLog.UserID)
+	assert.Equal(t, roleID, *auditLog.ResourceID)
+	assert.Equal(t, []string{"post:publish"}, auditLog.Details["permissions_added"])
+	assert.Equal(t, []string{"post:write"}, auditLog.Details["permissions_removed"])
+}
+
+func TestRoleService_UpdateRole_WithoutPermissionsLeavesThemUnchanged(t *testing.T) {
+	// Arrange
+	roleID := uuid.New()
+	roleRepo := &fakeRoleStore{roles: map[uuid.UUID]*models.Role{
+		roleID: {ID: roleID, Name: "editor", Permissions: models.Permissions{"post:read"}},
+	}}
+	roleService := services.NewRoleService(roleRepo, &fakeAuditLogRepository{}, utils.NewLogger("error", "test"))
+	description := "Edits posts"
+
+	// Act
+	result, err := roleService.UpdateRole(context.Background(), roleID, &models.RoleUpdateRequest{
+		Description: &description,
+	}, uuid.New())
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.PermissionChanges.IsEmpty())
+	assert.Equal(t, []string{"post:read"}, result.Role.Permissions)
+	assert.Equal(t, description, result.Role.Description)
+}
+
+func TestRoleService_UpdateRole_NotFound(t *testing.T) {
+	// Arrange
+	roleService := services.NewRoleService(&fakeRoleStore{roles: map[uuid.UUID]*models.Role{}}, &fakeAuditLogRepository{}, utils.NewLogger("error", "test"))
+
+	// Act
+	_, err := roleService.UpdateRole(context.Background(), uuid.New(), &models.RoleUpdateRequest{}, uuid.New())
+
+	// Assert
+	assert.ErrorIs(t, err, services.ErrRoleNotFound)
+}
+
+func TestUpdateRole_ResponseIncludesPermissionChanges(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	roleID := uuid.New()
+	roleRepo := &fakeRoleStore{roles: map[uuid.UUID]*models.Role{
+		roleID: {ID: roleID, Name: "editor", Permissions: models.Permissions{"post:read"}},
+	}}
+	logger := utils.NewLogger("error", "test")
+	roleHandler := handlers.NewRoleHandler(services.NewRoleService(roleRepo, &fakeAuditLogRepository{}, logger), &config.Config{}, logger)
+
+	router := gin.New()
+	router.PUT("/roles/:id", func(c *gin.Context) {
+		c.Set("user_id", uuid.New())
+		c.Set("user_email", "admin@example.com")
+		c.Set("user_username", "admin")
+		c.Set("user_roles", []string{"admin"})
+		c.Set("user_permissions", []string{})
+		c.Next()
+	}, roleHandler.UpdateRole)
+
+	body, err := json.Marshal(map[string]interface{}{"permissions": []string{"post:write"}})
+	require.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPut, "/roles/"+roleID.String(), bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var resp models.RoleUpdateResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, []string{"post:write"}, resp.PermissionChanges.Added)
+	assert.Equal(t, []string{"post:read"}, resp.PermissionChanges.Removed)
+}
@@ -0,0 +1,75 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/models"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+func TestSecurityInfo_Endpoints(t *testing.T) {
+	userID := uuid.New()
+	changedAt := time.Date(2026, time.March, 14, 9, 30, 0, 0, time.UTC)
+	repo := &fakeUserLookup{users: map[uuid.UUID]*models.User{
+		userID: {ID: userID, PasswordChangedAt: changedAt, FailedLoginCount: 2},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, logger), logger)
+	router := gin.New()
+	router.GET("/user/security", func(c *gin.Context) {
+		c.Set("user_id", userID)
+		c.Set("user_email", "self@example.com")
+		c.Set("user_username", "self")
+		c.Set("user_roles", []string{"user"})
+		c.Set("user_permissions", []string{})
+		c.Next()
+	}, userHandler.GetOwnSecurityInfo)
+	router.GET("/admin/users/:id/security", userHandler.GetSecurityInfo)
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{name: "own security info", path: "/user/security", expectedStatus: http.StatusOK},
+		{name: "admin view", path: "/admin/users/" + userID.String() + "/security", expectedStatus: http.StatusOK},
+		{name: "unknown user", path: "/admin/users/" + uuid.New().String() + "/security", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			require.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var info models.SecurityInfo
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+			assert.Equal(t, userID, info.UserID)
+			assert.True(t, changedAt.Equal(info.PasswordChangedAt))
+			assert.Equal(t, 2, info.FailedLoginCount)
+			assert.Nil(t, info.LockedUntil)
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/config"
+)
+
+func TestConfig_SessionPlaintextMigrationDeadline(t *testing.T) {
+	deadline := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+
+	tests := []struct {
+		name    string
+		until   string
+		want    time.Time
+		wantErr bool
+	}{
+		{name: "disabled", until: ""},
+		{name: "within limit", until: deadline.Format(time.RFC3339), want: deadline},
+		{name: "already ended", until: "2020-01-01T00:00:00Z", want: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "too far ahead", until: time.Now().Add(60 * 24 * time.Hour).Format(time.RFC3339), wantErr: true},
+		{name: "not a time", until: "next week", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{SessionPlaintextMigrationUntil: tt.until}
+
+			got, err := cfg.SessionPlaintextMigrationDeadline()
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %v, want %v", got, tt.want)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/auth"
+)
+
+func TestSessionLimitPolicy_LimitFor(t *testing.T) {
+	policy := auth.SessionLimitPolicy{
+		Default: 5,
+		ByRole: map[string]int{
+			"admin":     0,
+			"user":      3,
+			"moderator": 10,
+		},
+	}
+
+	tests := []struct {
+		name     string
+		roles    []string
+		expected int
+	}{
+		{name: "admin is unlimited", roles: []string{"admin"}, expected: 0},
+		{name: "user is capped", roles: []string{"user"}, expected: 3},
+		{name: "most permissive role wins", roles: []string{"user", "moderator"}, expected: 10},
+		{name: "unlimited role wins", roles: []string{"user", "admin"}, expected: 0},
+		{name: "unconfigured role uses default", roles: []string{"guest"}, expected: 5},
+		{name: "no roles uses default", roles: nil, expected: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, policy.LimitFor(tt.roles))
+		})
+	}
+}
+
+func TestSessionService_TimeoutFor(t *testing.T) {
+	sessionService := auth.NewSessionService(nil, time.Hour, auth.WithTimeoutsByMethod(map[string]time.Duration{
+		auth.AuthMethodOAuth:     24 * time.Hour,
+		auth.AuthMethodMagicLink: 15 * time.Minute,
+	}))
+
+	tests := []struct {
+		name     string
+		method   string
+		expected time.Duration
+	}{
+		{name: "oauth override", method: auth.AuthMethodOAuth, expected: 24 * time.Hour},
+		{name: "magic link override", method: auth.AuthMethodMagicLink, expected: 15 * time.Minute},
+		{name: "password uses default", method: auth.AuthMethodPassword, expected: time.Hour},
+		{name: "unknown method uses default", method: "", expected: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sessionService.TimeoutFor(tt.method))
+		})
+	}
+}
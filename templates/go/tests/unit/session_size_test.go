@@ -0,0 +1,32 @@
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/auth"
+)
+
+func TestSessionService_RejectsOversizedSession(t *testing.T) {
+	// Arrange - the size check runs before Redis is touched
+	sessionService := auth.NewSessionService(nil, time.Hour, auth.WithMaxSessionSize(1024))
+	sessionData := &auth.SessionData{
+		UserID:   uuid.New(),
+		Metadata: map[string]interface{}{"blob": strings.Repeat("x", 2048)},
+	}
+
+	// Act
+	_, createErr := sessionService.CreateSession(context.Background(), sessionData)
+	updateErr := sessionService.UpdateSession(context.Background(), "session-id", sessionData)
+
+	// Assert
+	for _, err := range []error{createErr, updateErr} {
+		assert.ErrorIs(t, err, auth.ErrSessionTooLarge)
+		assert.Contains(t, err.Error(), "exceeds limit of 1024 bytes")
+	}
+}
@@ -0,0 +1,62 @@
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"app/internal/repository/interfaces"
+)
+
+func TestNewStatsWindows(t *testing.T) {
+	tokyo := time.FixedZone("UTC+9", 9*60*60)
+	newYork := time.FixedZone("UTC-5", -5*60*60)
+
+	tests := []struct {
+		name          string
+		now           time.Time
+		loc           *time.Location
+		expectedToday time.Time
+		expectedWeek  time.Time
+		expectedMonth time.Time
+	}{
+		{
+			// 2030-01-01 is a Tuesday
+			name:          "nil location uses UTC",
+			now:           time.Date(2030, time.January, 1, 12, 0, 0, 0, time.UTC),
+			loc:           nil,
+			expectedToday: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+			expectedWeek:  time.Date(2029, time.December, 30, 0, 0, 0, 0, time.UTC),
+			expectedMonth: time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:          "ahead of UTC the local day has already rolled over",
+			now:           time.Date(2030, time.January, 31, 20, 0, 0, 0, time.UTC),
+			loc:           tokyo,
+			expectedToday: time.Date(2030, time.February, 1, 0, 0, 0, 0, tokyo),
+			expectedWeek:  time.Date(2030, time.January, 27, 0, 0, 0, 0, tokyo),
+			expectedMonth: time.Date(2030, time.February, 1, 0, 0, 0, 0, tokyo),
+		},
+		{
+			name:          "behind UTC the local day has not rolled over yet",
+			now:           time.Date(2030, time.February, 3, 2, 0, 0, 0, time.UTC),
+			loc:           newYork,
+			expectedToday: time.Date(2030, time.February, 2, 0, 0, 0, 0, newYork),
+			expectedWeek:  time.Date(2030, time.January, 27, 0, 0, 0, 0, newYork),
+			expectedMonth: time.Date(2030, time.February, 1, 0, 0, 0, 0, newYork),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			windows := interfaces.NewStatsWindows(tt.now, tt.loc)
+
+			// Assert
+			assert.True(t, tt.expectedToday.Equal(windows.Today), "today: got %s", windows.Today)
+			assert.True(t, tt.expectedWeek.Equal(windows.Week), "week: got %s", windows.Week)
+			assert.True(t, tt.expectedMonth.Equal(windows.Month), "month: got %s", windows.Month)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package unit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeUserRepository serves ListAfter from an in-memory slice ordered by ID
+// and records the size of every chunk it returns
+type fakeUserRepository struct {
+	interfaces.UserRepository
+	users  []*models.User
+	chunks []int
+}
+
+func newFakeUserRepository(count int) *fakeUserRepository {
+	users := make([]*models.User, count)
+	for i := range users {
+		users[i] = &models.User{
+			ID:       uuid.New(),
+			Email:    fmt.Sprintf("user%d@example.com", i),
+			Username: fmt.Sprintf("user%d", i),
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].ID.String() < users[j].ID.String()
+	})
+	return &fakeUserRepository{users: users}
+}
+
+func (r *fakeUserRepository) ListAfter(ctx context.Context, filters interfaces.UserFilters, afterID uuid.UUID, limit int) ([]*models.User, error) {
+	var chunk []*models.User
+	for _, user := range r.users {
+		if afterID != uuid.Nil && user.ID.String() <= afterID.String() {
+			continue
+		}
+		if len(chunk) == limit {
+			break
+		}
+		chunk = append(chunk, user)
+	}
+	r.chunks = append(r.chunks, len(chunk))
+	return chunk, nil
+}
+
+func TestUserService_ExportUsers_ReadsInChunks(t *testing.T) {
+	// Arrange
+	repo := newFakeUserRepository(5)
+	userService := services.NewUserService(repo, utils.NewLogger("error", "test"))
+
+	var exported []string
+
+	// Act
+	err := userService.ExportUsers(context.Background(), interfaces.UserFilters{}, 2, func(user models.UserResponse) error {
+		exported = append(exported, user.Email)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, exported, 5)
+	assert.Equal(t, []int{2, 2, 1}, repo.chunks)
+	for _, size := range repo.chunks {
+		assert.LessOrEqual(t, size, 2)
+	}
+}
+
+func TestUserHandler_ExportUsers_StreamsNDJSON(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	repo := newFakeUserRepository(3)
+	logger := utils.NewLogger("error", "test")
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, logger), logger)
+
+	router := gin.New()
+	router.GET("/users/export", userHandler.ExportUsers)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/export", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	var ids []uuid.UUID
+	scanner := bufio.NewScanner(bytes.NewReader(w.Body.Bytes()))
+	for scanner.Scan() {
+		var user models.UserResponse
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &user))
+		ids = append(ids, user.ID)
+	}
+	require.Len(t, ids, 3)
+	for i, user := range repo.users {
+		assert.Equal(t, user.ID, ids[i])
+	}
+}
+
+func TestUserHandler_ExportUsers_InvalidFilter(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	userHandler := handlers.NewUserHandler(services.NewUserService(newFakeUserRepository(1), logger), logger)
+
+	router := gin.New()
+	router.GET("/users/export", userHandler.ExportUsers)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/export?is_active=perhaps", nil)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
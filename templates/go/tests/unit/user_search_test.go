@@ -0,0 +1,139 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/handlers"
+	"app/internal/models"
+	"app/internal/repository/interfaces"
+	"app/internal/services"
+	"app/internal/utils"
+)
+
+// fakeUserSearch returns users containing the query in any searched field
+type fakeUserSearch struct {
+	interfaces.UserRepository
+	users []*models.User
+}
+
+func (r *fakeUserSearch) SearchUsers(ctx context.Context, query string, filters interfaces.UserFilters) ([]*models.User, error) {
+	var matches []*models.User
+	for _, user := range r.users {
+		if models.MatchUser(user, query) != nil {
+			matches = append(matches, user)
+		}
+	}
+	return matches, nil
+}
+
+func TestMatchUser(t *testing.T) {
+	user := &models.User{
+		Email:     "alice@corp.example",
+		Username:  "wonderland",
+		FirstName: "Alice",
+		LastName:  "Liddell",
+	}
+
+	tests := []struct {
+		name            string
+		query           string
+		expectedField   string
+		expectedSnippet string
+	}{
+		{name: "email match", query: "corp", expectedField: "email", expectedSnippet: "alice@<em>corp</em>.example"},
+		{name: "username match", query: "WONDER", expectedField: "username", expectedSnippet: "<em>wonder</em>land"},
+		{name: "email takes precedence over first name", query: "alice", expectedField: "email", expectedSnippet: "<em>alice</em>@corp.example"},
+		{name: "last name match", query: "dell", expectedField: "last_name", expectedSnippet: "Lid<em>dell</em>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			match := models.MatchUser(user, tt.query)
+
+			// Assert
+			require.NotNil(t, match)
+			assert.Equal(t, tt.expectedField, match.Field)
+			assert.Equal(t, tt.expectedSnippet, match.Snippet)
+		})
+	}
+
+	assert.Nil(t, models.MatchUser(user, "bob"))
+}
+
+func TestMatchUser_EscapesSnippet(t *testing.T) {
+	// Arrange
+	user := &models.User{Username: "<b>mallory</b>"}
+
+	// Act
+	match := models.MatchUser(user, "mallory")
+
+	// Assert
+	require.NotNil(t, match)
+	assert.Equal(t, "&lt;b&gt;<em>mallory</em>&lt;/b&gt;", match.Snippet)
+}
+
+func TestSearchUsers_Highlighting(t *testing.T) {
+	repo := &fakeUserSearch{users: []*models.User{
+		{ID: uuid.New(), Email: "sam@example.com", Username: "samwise"},
+		{ID: uuid.New(), Email: "frodo@shire.example", Username: "ringbearer"},
+	}}
+
+	gin.SetMode(gin.TestMode)
+	logger := utils.NewLogger("error", "test")
+	userHandler := handlers.NewUserHandler(services.NewUserService(repo, logger), logger)
+	router := gin.New()
+	router.GET("/users/search", userHandler.SearchUsers)
+
+	search := func(path string) []map[string]interface{} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var body struct {
+			Users []map[string]interface{} `json:"users"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		return body.Users
+	}
+
+	t.Run("email match reported", func(t *testing.T) {
+		// Act
+		users := search("/users/search?q=shire&highlight=true")
+
+		// Assert
+		require.Len(t, users, 1)
+		match := users[0]["match"].(map[string]interface{})
+		assert.Equal(t, "email", match["field"])
+		assert.Equal(t, "frodo@<em>shire</em>.example", match["snippet"])
+	})
+
+	t.Run("username match reported", func(t *testing.T) {
+		// Act
+		users := search("/users/search?q=wise&highlight=true")
+
+		// Assert
+		require.Len(t, users, 1)
+		match := users[0]["match"].(map[string]interface{})
+		assert.Equal(t, "username", match["field"])
+	})
+
+	t.Run("no metadata without highlight", func(t *testing.T) {
+		// Act
+		users := search("/users/search?q=wise")
+
+		// Assert
+		require.Len(t, users, 1)
+		assert.NotContains(t, users[0], "match")
+		assert.Equal(t, "samwise", users[0]["username"])
+	})
+}
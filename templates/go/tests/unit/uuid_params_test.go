@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"app/internal/api/middleware"
+)
+
+func TestValidateUUIDParams(t *testing.T) {
+	validID := uuid.New()
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+		expectHandler  bool
+	}{
+		{name: "valid UUID", path: "/users/" + validID.String(), expectedStatus: http.StatusOK, expectHandler: true},
+		{name: "malformed UUID", path: "/users/not-a-uuid", expectedStatus: http.StatusBadRequest},
+		{name: "numeric ID", path: "/users/42", expectedStatus: http.StatusBadRequest},
+		{name: "undashed UUID", path: "/users/" + hexWithoutDashes(validID), expectedStatus: http.StatusBadRequest},
+		{name: "route without the param", path: "/users/export", expectedStatus: http.StatusOK, expectHandler: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			handlerCalled := false
+			var parsed uuid.UUID
+
+			users := router.Group("/users", middleware.ValidateUUIDParams("id"))
+			users.GET("/export", func(c *gin.Context) {
+				handlerCalled = true
+				c.Status(http.StatusOK)
+			})
+			users.GET("/:id", func(c *gin.Context) {
+				handlerCalled = true
+				parsed, _ = middleware.UUIDParam(c, "id")
+				c.Status(http.StatusOK)
+			})
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectHandler, handlerCalled)
+			if tt.expectedStatus == http.StatusBadRequest {
+				var body map[string]interface{}
+				require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+				assert.Equal(t, "INVALID_PATH_PARAMETER", body["code"])
+			}
+			if tt.name == "valid UUID" {
+				assert.Equal(t, validID, parsed)
+			}
+		})
+	}
+}
+
+func hexWithoutDashes(id uuid.UUID) string {
+	s := id.String()
+	return s[0:8] + s[9:13] + s[14:18] + s[19:23] + s[24:]
+}